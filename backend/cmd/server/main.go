@@ -3,18 +3,27 @@ package main
 import (
 	"context"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"subscribe_tracker/backend/internal/config"
 	"subscribe_tracker/backend/internal/db"
 	httpapi "subscribe_tracker/backend/internal/http"
+	"subscribe_tracker/backend/internal/notify"
+	"subscribe_tracker/backend/internal/rates"
+	"subscribe_tracker/backend/internal/repository/memory"
 	"subscribe_tracker/backend/internal/repository/postgres"
 	"subscribe_tracker/backend/internal/security"
+	"subscribe_tracker/backend/internal/statement"
+	"subscribe_tracker/backend/internal/telegram"
 	"subscribe_tracker/backend/internal/usecase"
+	"subscribe_tracker/backend/internal/worker"
 )
 
 func main() {
@@ -36,22 +45,144 @@ func main() {
 		log.Fatalf("migrations: %v", err)
 	}
 
-	tokenManager := security.NewJWTManager([]byte(cfg.JWTSecret), 7*24*time.Hour)
+	tokenManager := security.NewJWTManager([]byte(cfg.JWTSecret), cfg.AccessTokenTTL)
 
 	userRepo := postgres.NewUserRepository(pool)
+	sessionRepo := postgres.NewSessionRepository(pool)
+	userTokenRepo := postgres.NewUserTokenRepository(pool)
+	twoFactorRepo := postgres.NewTwoFactorRepository(pool)
 	subRepo := postgres.NewSubscriptionRepository(pool)
+	chargeRepo := postgres.NewChargeRepository(pool)
+	categoryRepo := postgres.NewCategoryRepository(pool)
+	tagRepo := postgres.NewTagRepository(pool)
+	paymentMethodRepo := postgres.NewPaymentMethodRepository(pool)
+	reminderRepo := postgres.NewReminderRepository(pool)
+	webhookRepo := postgres.NewWebhookRepository(pool)
+	telegramRepo := postgres.NewTelegramRepository(pool)
+	calendarRepo := postgres.NewCalendarRepository(pool)
+	rateRepo := postgres.NewRateRepository(pool)
+	personalTokenRepo := postgres.NewPersonalTokenRepository(pool)
+
+	var loginAttempts usecase.LoginAttemptStore
+	switch cfg.LoginAttemptStore {
+	case "postgres":
+		loginAttempts = postgres.NewLoginAttemptRepository(pool)
+	case "memory":
+		loginAttempts = memory.NewLoginAttemptRepository()
+	default:
+		log.Fatalf("unknown LOGIN_ATTEMPT_STORE %q", cfg.LoginAttemptStore)
+	}
 
-	authUC := usecase.NewAuthUsecase(userRepo, tokenManager)
-	subUC := usecase.NewSubscriptionUsecase(subRepo)
+	var rateProviders []usecase.RateProvider
+	if cfg.RatesFile != "" {
+		rateProviders = append(rateProviders, rates.NewFileProvider(cfg.RatesFile))
+	}
+	if cfg.RatesURL != "" {
+		rateProviders = append(rateProviders, rates.NewHTTPProvider(cfg.RatesURL, &http.Client{Timeout: 10 * time.Second}))
+	}
 
-	handler := httpapi.NewHandler(authUC, subUC, tokenManager)
+	var notifiers notify.Multi
+	var accountSender usecase.AccountSender = notify.LogNotifier{}
+	if cfg.SMTPHost != "" {
+		smtpNotifier, err := notify.NewSMTPNotifier(notify.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+			StartTLS: cfg.SMTPStartTLS,
+		})
+		if err != nil {
+			log.Fatalf("smtp: %v", err)
+		}
+		notifiers = append(notifiers, smtpNotifier)
+		accountSender = smtpNotifier
+	}
 
+	verificationUC := usecase.NewVerificationUsecase(userRepo, userTokenRepo, accountSender, cfg.AppURL)
+	loginGuard := usecase.NewLoginGuard(loginAttempts, usecase.LoginLimits{
+		AccountFailures: cfg.LoginMaxAccountFailures,
+		IPFailures:      cfg.LoginMaxIPFailures,
+		Window:          cfg.LoginFailureWindow,
+		Lockout:         cfg.LoginLockout,
+		FreeFailures:    cfg.LoginFreeFailures,
+		Delay:           cfg.LoginDelay,
+		MaxDelay:        cfg.LoginMaxDelay,
+	})
+	twoFactorUC := usecase.NewTwoFactorUsecase(userRepo, twoFactorRepo, userTokenRepo, loginGuard)
+	webhookUC := usecase.NewWebhookUsecase(webhookRepo, usecase.NewWebhookClient(10*time.Second))
+	authUC := usecase.NewAuthUsecase(userRepo, sessionRepo, tokenManager, cfg.RefreshTokenTTL, verificationUC, twoFactorUC, loginGuard)
+	telegramUC := usecase.NewTelegramUsecase(telegramRepo, userRepo)
+	var telegramClient telegram.Client
+	if cfg.TelegramBotToken != "" {
+		telegramClient = telegram.NewClient(cfg.TelegramAPIURL, cfg.TelegramBotToken, &http.Client{Timeout: 60 * time.Second})
+		notifiers = append(notifiers, telegram.NewNotifier(telegramClient, telegramUC))
+	}
+	var notifier usecase.Notifier = notifiers
+	if len(notifiers) == 0 {
+		notifier = notify.LogNotifier{}
+	}
+	// Subscription changes reschedule reminders and are sent to webhooks.
+	reminderUC := usecase.NewReminderUsecase(reminderRepo, subRepo, userRepo, notifier, webhookUC)
+	subscriptionEvents := usecase.Publishers{webhookUC, reminderUC}
+	subUC := usecase.NewSubscriptionUsecase(subRepo, subscriptionEvents, userRepo, cfg.RequireVerifiedEmail)
+	passwordUC := usecase.NewPasswordUsecase(userRepo, userTokenRepo, sessionRepo, accountSender, cfg.AppURL)
+	rateUC := usecase.NewRateUsecase(rateRepo, rateProviders...)
+	analyticsUC := usecase.NewAnalyticsUsecase(subRepo, userRepo, rateRepo)
+	chargeUC := usecase.NewChargeUsecase(chargeRepo, subRepo)
+	categoryUC := usecase.NewCategoryUsecase(categoryRepo)
+	tagUC := usecase.NewTagUsecase(tagRepo)
+	paymentMethodUC := usecase.NewPaymentMethodUsecase(paymentMethodRepo, subRepo, subscriptionEvents)
+	alertUC := usecase.NewAlertUsecase(paymentMethodRepo, subRepo)
+	calendarUC := usecase.NewCalendarUsecase(calendarRepo, userRepo, subRepo)
+	csvUC := usecase.NewCSVUsecase(subUC, categoryRepo, tagRepo)
+	statementUC := usecase.NewStatementUsecase(statement.Parser{}, subUC)
+	personalTokenUC := usecase.NewPersonalTokenUsecase(personalTokenRepo)
+
+	var bot *telegram.Bot
+	if cfg.TelegramBotToken != "" {
+		b := telegram.NewBot(telegramClient, telegramUC, subUC, analyticsUC)
+		bot = &b
+	}
+
+	handler := httpapi.NewHandler(authUC, passwordUC, verificationUC, twoFactorUC, subUC, analyticsUC, chargeUC, categoryUC, tagUC, paymentMethodUC, alertUC, reminderUC, webhookUC, telegramUC, calendarUC, csvUC, statementUC, rateUC, personalTokenUC, tokenManager)
+
+	routes := handler.Routes()
+	if cfg.TrustProxy {
+		routes = withForwardedFor(routes)
+	}
 	server := &http.Server{
 		Addr:              ":" + cfg.Port,
-		Handler:           withCORS(cfg.CorsOrigins, handler.Routes()),
+		Handler:           withCORS(cfg.CorsOrigins, routes),
 		ReadHeaderTimeout: 5 * time.Second,
 	}
 
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	workers.Add(2)
+	go func() {
+		defer workers.Done()
+		worker.NewReminderWorker(reminderUC, cfg.ReminderInterval, cfg.ReminderSweepInterval).Run(workerCtx)
+	}()
+	go func() {
+		defer workers.Done()
+		worker.NewWebhookWorker(webhookUC, cfg.WebhookInterval).Run(workerCtx)
+	}()
+	if len(rateProviders) > 0 {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			worker.NewRateWorker(rateUC, cfg.RatesInterval).Run(workerCtx)
+		}()
+	}
+	if bot != nil {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			bot.Run(workerCtx)
+		}()
+	}
+
 	go func() {
 		log.Printf("API listening on %s", server.Addr)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -68,6 +199,8 @@ func main() {
 	if err := server.Shutdown(shutdownCtx); err != nil {
 		log.Printf("shutdown: %v", err)
 	}
+	stopWorkers()
+	workers.Wait()
 }
 
 func withCORS(allowed []string, next http.Handler) http.Handler {
@@ -99,3 +232,18 @@ func withCORS(allowed []string, next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// withForwardedFor makes the address the proxy in front of the API saw the
+// remote address of the request. Only the last X-Forwarded-For entry is
+// used: that one is added by the proxy, earlier ones come from the client.
+func withForwardedFor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if values := r.Header.Values("X-Forwarded-For"); len(values) > 0 {
+			entries := strings.Split(values[len(values)-1], ",")
+			if ip := net.ParseIP(strings.TrimSpace(entries[len(entries)-1])); ip != nil {
+				r.RemoteAddr = net.JoinHostPort(ip.String(), "0")
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
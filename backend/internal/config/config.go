@@ -1,8 +1,11 @@
 package config
 
 import (
+	"log"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type Config struct {
@@ -11,6 +14,64 @@ type Config struct {
 	JWTSecret     string
 	CorsOrigins   []string
 	MigrationsDir string
+	// AppURL is the address of the frontend, used in links sent by email.
+	AppURL string
+	// TrustProxy takes the client address from X-Forwarded-For. Only set it
+	// when the API is reachable through a reverse proxy alone.
+	TrustProxy bool
+
+	// AccessTokenTTL is how long a JWT access token is accepted;
+	// RefreshTokenTTL is how long a session lasts without being refreshed.
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	// RequireVerifiedEmail blocks creating subscriptions until the user
+	// verifies their email.
+	RequireVerifiedEmail bool
+
+	// LoginAttemptStore is where failed logins are counted: "postgres",
+	// shared by all replicas, or "memory" for a single instance.
+	LoginAttemptStore string
+	// LoginMaxAccountFailures and LoginMaxIPFailures failed logins within
+	// LoginFailureWindow lock the email or the client address out for
+	// LoginLockout; zero turns the limit off.
+	LoginMaxAccountFailures int
+	LoginMaxIPFailures      int
+	LoginFailureWindow      time.Duration
+	LoginLockout            time.Duration
+	// After LoginFreeFailures failures in a row each attempt has to wait
+	// LoginDelay, doubling up to LoginMaxDelay.
+	LoginFreeFailures int
+	LoginDelay        time.Duration
+	LoginMaxDelay     time.Duration
+
+	// ReminderInterval is how often the reminder worker looks for due
+	// reminders.
+	ReminderInterval time.Duration
+	// ReminderSweepInterval is how often reminders of all subscriptions are
+	// rescheduled, which moves them on to the next charge.
+	ReminderSweepInterval time.Duration
+	// WebhookInterval is how often due webhook deliveries are sent.
+	WebhookInterval time.Duration
+
+	// SMTPHost enables email notifications. SMTPFrom may include a display
+	// name, e.g. "Subscribe Tracker <noreply@example.com>".
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+	SMTPStartTLS bool
+
+	// TelegramBotToken enables Telegram notifications and the bot. The bot
+	// long-polls for updates, so only one instance may have it set.
+	TelegramBotToken string
+	TelegramAPIURL   string
+
+	// RatesFile and RatesURL point at JSON documents of exchange rates,
+	// refreshed every RatesInterval. Either may be left empty.
+	RatesFile     string
+	RatesURL      string
+	RatesInterval time.Duration
 }
 
 func Load() Config {
@@ -20,6 +81,40 @@ func Load() Config {
 		JWTSecret:     getEnv("JWT_SECRET", ""),
 		CorsOrigins:   splitCSV(getEnv("CORS_ORIGINS", "")),
 		MigrationsDir: getEnv("MIGRATIONS_DIR", "./migrations"),
+		AppURL:        getEnv("APP_URL", "http://localhost:5173"),
+		TrustProxy:    getBool("TRUST_PROXY", false),
+
+		AccessTokenTTL:  getDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTokenTTL: getDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+
+		RequireVerifiedEmail: getBool("REQUIRE_VERIFIED_EMAIL", false),
+
+		LoginAttemptStore:       getEnv("LOGIN_ATTEMPT_STORE", "postgres"),
+		LoginMaxAccountFailures: getInt("LOGIN_MAX_ACCOUNT_FAILURES", 10),
+		LoginMaxIPFailures:      getInt("LOGIN_MAX_IP_FAILURES", 50),
+		LoginFailureWindow:      getDuration("LOGIN_FAILURE_WINDOW", 15*time.Minute),
+		LoginLockout:            getDuration("LOGIN_LOCKOUT", 15*time.Minute),
+		LoginFreeFailures:       getInt("LOGIN_FREE_FAILURES", 3),
+		LoginDelay:              getDuration("LOGIN_DELAY", time.Second),
+		LoginMaxDelay:           getDuration("LOGIN_MAX_DELAY", 30*time.Second),
+
+		ReminderInterval:      getDuration("REMINDER_INTERVAL", time.Minute),
+		ReminderSweepInterval: getDuration("REMINDER_SWEEP_INTERVAL", time.Hour),
+		WebhookInterval:       getDuration("WEBHOOK_INTERVAL", 10*time.Second),
+
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     getEnv("SMTP_PORT", "587"),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:     getEnv("SMTP_FROM", ""),
+		SMTPStartTLS: getBool("SMTP_STARTTLS", true),
+
+		TelegramBotToken: getEnv("TELEGRAM_BOT_TOKEN", ""),
+		TelegramAPIURL:   getEnv("TELEGRAM_API_URL", "https://api.telegram.org"),
+
+		RatesFile:     getEnv("RATES_FILE", ""),
+		RatesURL:      getEnv("RATES_URL", ""),
+		RatesInterval: getDuration("RATES_INTERVAL", 12*time.Hour),
 	}
 }
 
@@ -30,6 +125,45 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+func getDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed <= 0 {
+		log.Printf("config: invalid %s %q, using %s", key, value, fallback)
+		return fallback
+	}
+	return parsed
+}
+
+func getInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		log.Printf("config: invalid %s %q, using %d", key, value, fallback)
+		return fallback
+	}
+	return parsed
+}
+
+func getBool(key string, fallback bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("config: invalid %s %q, using %t", key, value, fallback)
+		return fallback
+	}
+	return parsed
+}
+
 func splitCSV(value string) []string {
 	if value == "" {
 		return nil
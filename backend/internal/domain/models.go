@@ -7,14 +7,328 @@ type User struct {
 	Name         string
 	Email        string
 	PasswordHash string
+
+	// ReminderLeadDays is how many days before a charge reminders are sent
+	// unless the subscription overrides it.
+	ReminderLeadDays int
+	// Locale is the language of notifications, "ru" or "en".
+	Locale string
+	// BaseCurrency is the currency totals in other currencies are converted
+	// into.
+	BaseCurrency string
+	// EmailVerifiedAt is nil until the user confirms their email.
+	EmailVerifiedAt *time.Time
+}
+
+type BillingUnit string
+
+const (
+	BillingDay   BillingUnit = "day"
+	BillingWeek  BillingUnit = "week"
+	BillingMonth BillingUnit = "month"
+	BillingYear  BillingUnit = "year"
+)
+
+// BillingCycle describes a recurrence of Interval units, e.g. every 3 months
+// or every 28 days.
+type BillingCycle struct {
+	Unit     BillingUnit
+	Interval int
 }
 
+type SubscriptionStatus string
+
+const (
+	StatusActive    SubscriptionStatus = "active"
+	StatusPaused    SubscriptionStatus = "paused"
+	StatusCancelled SubscriptionStatus = "cancelled"
+)
+
 type Subscription struct {
 	ID          string
 	UserID      string
 	ServiceName string
-	BankName    string
-	CardLast4   string
-	Billing     string
-	ChargeDate  time.Time
+
+	// BankName and CardLast4 are read from the payment method.
+	PaymentMethodID string
+	BankName        string
+	CardLast4       string
+
+	Billing    BillingCycle
+	ChargeDate time.Time
+	Price      int64 // minor units of Currency
+	Currency   string
+
+	// TrialEndDate is the first paid charge of a subscription that started as
+	// a free trial; from then on PostTrialPrice is charged instead of Price.
+	TrialEndDate   *time.Time
+	PostTrialPrice int64
+
+	// Status takes effect on StatusEffectiveDate: a paused or cancelled
+	// subscription is not charged from that date on, a resumed one is charged
+	// again from that date.
+	Status              SubscriptionStatus
+	StatusEffectiveDate time.Time
+
+	Category *Category
+	Tags     []Tag
+
+	// ReminderLeadDays overrides the lead time of the user when set.
+	ReminderLeadDays *int
+
+	// Prices is the price history ordered by EffectiveFrom. Price and
+	// Currency above hold the most recently entered price.
+	Prices []PriceChange
+
+	// NextChargeDate, NextChargeAmount and NextChargeCurrency are derived
+	// from the billing schedule and price history and are not stored.
+	NextChargeDate     time.Time
+	NextChargeAmount   int64
+	NextChargeCurrency string
+}
+
+type Category struct {
+	ID     string
+	UserID string
+	Name   string
+}
+
+type Tag struct {
+	ID     string
+	UserID string
+	Name   string
+}
+
+// PaymentMethod is a card subscriptions are charged to. ExpMonth and ExpYear
+// are zero when the expiry is unknown.
+type PaymentMethod struct {
+	ID        string
+	UserID    string
+	BankName  string
+	CardLast4 string
+	Brand     string
+	ExpMonth  int
+	ExpYear   int
+	Nickname  string
+}
+
+// PriceChange is a price that applies to charges on or after EffectiveFrom
+// until the next change.
+type PriceChange struct {
+	ID             string
+	SubscriptionID string
+	Price          int64
+	Currency       string
+	EffectiveFrom  time.Time
+}
+
+// StatusChange is a status a subscription moved to on EffectiveDate.
+type StatusChange struct {
+	Status        SubscriptionStatus
+	EffectiveDate time.Time
+}
+
+type ChargeStatus string
+
+const (
+	ChargeExpected ChargeStatus = "expected"
+	ChargePaid     ChargeStatus = "paid"
+	ChargeFailed   ChargeStatus = "failed"
+	ChargeRefunded ChargeStatus = "refunded"
+)
+
+// Charge is a single entry of a subscription's payment ledger. Expected
+// charges are generated from the billing schedule and await confirmation.
+type Charge struct {
+	ID             string
+	UserID         string
+	SubscriptionID string
+	ChargeDate     time.Time
+	Amount         int64
+	Currency       string
+	Status         ChargeStatus
+	Note           string
+}
+
+type ReminderStatus string
+
+const (
+	ReminderPending ReminderStatus = "pending"
+	ReminderSent    ReminderStatus = "sent"
+	ReminderFailed  ReminderStatus = "failed"
+)
+
+// Reminder is a queued notification about the charge of a subscription on
+// ChargeDate. It becomes due on RemindAt.
+type Reminder struct {
+	ID             string
+	UserID         string
+	SubscriptionID string
+	ServiceName    string
+	ChargeDate     time.Time
+	RemindAt       time.Time
+	Amount         int64
+	Currency       string
+	Status         ReminderStatus
+	Attempts       int
+	LastError      string
+	SentAt         *time.Time
+}
+
+// Webhook events.
+const (
+	EventSubscriptionCreated = "subscription.created"
+	EventSubscriptionUpdated = "subscription.updated"
+	EventSubscriptionDeleted = "subscription.deleted"
+	EventChargeUpcoming      = "charge.upcoming"
+)
+
+// Webhook is a user endpoint that receives the listed events as JSON signed
+// with Secret.
+type Webhook struct {
+	ID     string
+	UserID string
+	URL    string
+	Secret string
+	Events []string
+	Active bool
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliveryDelivered DeliveryStatus = "delivered"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+// WebhookDelivery is one event sent to a webhook, with the outcome of its
+// latest attempt. URL and Secret are copied from the webhook when the
+// delivery is claimed for sending.
+type WebhookDelivery struct {
+	ID             string
+	WebhookID      string
+	Event          string
+	Payload        []byte
+	Status         DeliveryStatus
+	Attempts       int
+	ResponseStatus int
+	LastError      string
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+
+	URL    string
+	Secret string
+}
+
+// Statement is a bank or card account statement read from an uploaded file.
+// Fields the format does not carry are left empty.
+type Statement struct {
+	BankName     string
+	AccountID    string
+	CardLast4    string
+	Currency     string
+	Transactions []StatementTransaction
+}
+
+type StatementTransaction struct {
+	Date time.Time
+	// Amount is the decimal amount with a dot separator, negative for
+	// debits.
+	Amount      string
+	Currency    string
+	Description string
+}
+
+// Rate is the price of one unit of Base in Quote on Date.
+type Rate struct {
+	Base   string
+	Quote  string
+	Rate   float64
+	Date   time.Time
+	Source string
+}
+
+// Session is one refresh token. Refreshing replaces it with a new token of
+// the same family; the family is what users see as a signed-in device, and
+// StartedAt is when it signed in.
+type Session struct {
+	ID        string
+	FamilyID  string
+	UserID    string
+	TokenHash string
+	UserAgent string
+	IPAddress string
+	StartedAt time.Time
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	RotatedAt *time.Time
+	RevokedAt *time.Time
+}
+
+// TwoFactor is the TOTP second factor of a user. It is enabled once
+// ConfirmedAt is set.
+type TwoFactor struct {
+	UserID      string
+	Secret      string
+	ConfirmedAt *time.Time
+	// LastStep is the time step of the last accepted code.
+	LastStep          int64
+	RecoveryCodesLeft int
+}
+
+// LoginScope is what failed logins are counted against.
+type LoginScope string
+
+const (
+	// LoginScopeAccount counts by the email that was tried.
+	LoginScopeAccount LoginScope = "account"
+	// LoginScopeIP counts by the address of the client.
+	LoginScopeIP LoginScope = "ip"
+)
+
+// LoginAttempts are the recent failed logins of one account or client
+// address.
+type LoginAttempts struct {
+	Scope        LoginScope
+	Subject      string
+	Failures     int
+	LastFailedAt time.Time
+	LockedUntil  *time.Time
+}
+
+// LoginLockout records that an account or client address was locked out.
+// UserID is set when an account with the email exists.
+type LoginLockout struct {
+	ID          string
+	Scope       LoginScope
+	Subject     string
+	UserID      *string
+	Failures    int
+	IPAddress   string
+	UserAgent   string
+	LockedUntil time.Time
+	CreatedAt   time.Time
+}
+
+// Scopes of personal access tokens.
+const (
+	ScopeReadSubscriptions  = "read:subscriptions"
+	ScopeWriteSubscriptions = "write:subscriptions"
+	ScopeReadAnalytics      = "read:analytics"
+)
+
+// PersonalToken lets scripts call the API on behalf of a user, limited to
+// its scopes. Only the hash of the token is stored; Prefix is its first few
+// characters, so the user can tell tokens apart.
+type PersonalToken struct {
+	ID         string
+	UserID     string
+	Name       string
+	TokenHash  string
+	Prefix     string
+	Scopes     []string
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	CreatedAt  time.Time
 }
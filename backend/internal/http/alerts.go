@@ -0,0 +1,69 @@
+package httpapi
+
+import (
+	"net/http"
+	"strconv"
+
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+const defaultCardExpiryWindowDays = 30
+
+type expiringCardResult struct {
+	PaymentMethod paymentMethodResult    `json:"payment_method"`
+	ExpiresOn     string                 `json:"expires_on"`
+	Expired       bool                   `json:"expired"`
+	Subscriptions []subscriptionResult   `json:"subscriptions"`
+	Monthly       []currencyAmountResult `json:"monthly"`
+}
+
+func (h Handler) handleListExpiringCards(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	days := defaultCardExpiryWindowDays
+	if value := r.URL.Query().Get("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid days"})
+			return
+		}
+		days = parsed
+	}
+
+	cards, err := h.Alerts.ExpiringCards(r.Context(), userID, days)
+	if err != nil {
+		writePaymentMethodError(w, err)
+		return
+	}
+
+	results := make([]expiringCardResult, 0, len(cards))
+	for _, card := range cards {
+		results = append(results, toExpiringCardResult(card))
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+func toExpiringCardResult(card usecase.ExpiringCard) expiringCardResult {
+	result := expiringCardResult{
+		PaymentMethod: toPaymentMethodResult(card.PaymentMethod),
+		ExpiresOn:     card.ExpiresOn.Format("2006-01-02"),
+		Expired:       card.Expired,
+		Subscriptions: make([]subscriptionResult, 0, len(card.Subscriptions)),
+		Monthly:       make([]currencyAmountResult, 0, len(card.Monthly)),
+	}
+	for _, sub := range card.Subscriptions {
+		result.Subscriptions = append(result.Subscriptions, toSubscriptionResult(sub))
+	}
+	for _, total := range card.Monthly {
+		result.Monthly = append(result.Monthly, currencyAmountResult{
+			Currency: total.Currency,
+			Amount:   usecase.FormatAmount(total.Amount, total.Currency),
+			Count:    total.Count,
+		})
+	}
+	return result
+}
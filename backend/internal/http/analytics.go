@@ -0,0 +1,268 @@
+package httpapi
+
+import (
+	"net/http"
+
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+type spendItemResult struct {
+	SubscriptionID string `json:"subscription_id"`
+	ServiceName    string `json:"service_name"`
+	Currency       string `json:"currency"`
+	Monthly        string `json:"monthly"`
+	Yearly         string `json:"yearly"`
+}
+
+type spendTotalResult struct {
+	BankName  string `json:"bank_name,omitempty"`
+	CardLast4 string `json:"card_last4,omitempty"`
+	Category  string `json:"category,omitempty"`
+	Tag       string `json:"tag,omitempty"`
+	Currency  string `json:"currency"`
+	Monthly   string `json:"monthly"`
+	Yearly    string `json:"yearly"`
+	Count     int    `json:"count"`
+
+	// BaseMonthly and BaseYearly are left out when there is no rate for
+	// Currency.
+	BaseMonthly string `json:"base_monthly,omitempty"`
+	BaseYearly  string `json:"base_yearly,omitempty"`
+}
+
+type currencyAmountResult struct {
+	Currency   string `json:"currency"`
+	Amount     string `json:"amount"`
+	Count      int    `json:"count"`
+	BaseAmount string `json:"base_amount,omitempty"`
+}
+
+type projectedChargeResult struct {
+	Date           string `json:"date"`
+	SubscriptionID string `json:"subscription_id"`
+	ServiceName    string `json:"service_name"`
+	Amount         string `json:"amount"`
+	Currency       string `json:"currency"`
+}
+
+type forecastMonthResult struct {
+	Month     string                  `json:"month"`
+	Totals    []currencyAmountResult  `json:"totals"`
+	Charges   []projectedChargeResult `json:"charges"`
+	BaseTotal string                  `json:"base_total"`
+}
+
+type appliedRateResult struct {
+	Currency string  `json:"currency"`
+	Rate     float64 `json:"rate"`
+	Date     string  `json:"date"`
+}
+
+type rateResult struct {
+	Base   string  `json:"base"`
+	Quote  string  `json:"quote"`
+	Rate   float64 `json:"rate"`
+	Date   string  `json:"date"`
+	Source string  `json:"source"`
+}
+
+type analyticsSettingsPayload struct {
+	BaseCurrency string `json:"base_currency"`
+}
+
+type analyticsSettingsResult struct {
+	BaseCurrency string `json:"base_currency"`
+}
+
+type spendResponse struct {
+	From       string                `json:"from"`
+	Items      []spendItemResult     `json:"items"`
+	ByCurrency []spendTotalResult    `json:"by_currency"`
+	ByBank     []spendTotalResult    `json:"by_bank"`
+	ByCard     []spendTotalResult    `json:"by_card"`
+	ByCategory []spendTotalResult    `json:"by_category"`
+	ByTag      []spendTotalResult    `json:"by_tag"`
+	Forecast   []forecastMonthResult `json:"forecast"`
+
+	BaseCurrency string              `json:"base_currency"`
+	Total        spendTotalResult    `json:"total"`
+	Rates        []appliedRateResult `json:"rates"`
+	RateDate     *string             `json:"rate_date"`
+	Unconverted  []string            `json:"unconverted"`
+}
+
+func (h Handler) handleSpendAnalytics(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	report, err := h.Analytics.SpendIn(r.Context(), userID, r.URL.Query().Get("base"))
+	if err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toSpendResponse(report))
+}
+
+func toSpendResponse(report usecase.SpendReport) spendResponse {
+	response := spendResponse{
+		From:       report.From.Format("2006-01-02"),
+		Items:      make([]spendItemResult, 0, len(report.Items)),
+		ByCurrency: toSpendTotalResults(report.ByCurrency, report.Base),
+		ByBank:     toSpendTotalResults(report.ByBank, report.Base),
+		ByCard:     toSpendTotalResults(report.ByCard, report.Base),
+		ByCategory: toSpendTotalResults(report.ByCategory, report.Base),
+		ByTag:      toSpendTotalResults(report.ByTag, report.Base),
+		Forecast:   make([]forecastMonthResult, 0, len(report.Forecast)),
+
+		BaseCurrency: report.Base,
+		Total:        toSpendTotalResult(report.Total),
+		Rates:        make([]appliedRateResult, 0, len(report.Rates)),
+		Unconverted:  make([]string, 0, len(report.Unconverted)),
+	}
+	if !report.RateDate.IsZero() {
+		date := report.RateDate.Format("2006-01-02")
+		response.RateDate = &date
+	}
+	for _, rate := range report.Rates {
+		response.Rates = append(response.Rates, appliedRateResult{
+			Currency: rate.Currency,
+			Rate:     rate.Rate,
+			Date:     rate.Date.Format("2006-01-02"),
+		})
+	}
+	response.Unconverted = append(response.Unconverted, report.Unconverted...)
+
+	for _, item := range report.Items {
+		currency := item.Currency
+		response.Items = append(response.Items, spendItemResult{
+			SubscriptionID: item.Subscription.ID,
+			ServiceName:    item.Subscription.ServiceName,
+			Currency:       currency,
+			Monthly:        usecase.FormatAmount(item.Monthly, currency),
+			Yearly:         usecase.FormatAmount(item.Yearly, currency),
+		})
+	}
+
+	for _, month := range report.Forecast {
+		result := forecastMonthResult{
+			Month:     month.Month.Format("2006-01"),
+			Totals:    make([]currencyAmountResult, 0, len(month.Totals)),
+			Charges:   make([]projectedChargeResult, 0, len(month.Charges)),
+			BaseTotal: usecase.FormatAmount(month.BaseTotal, report.Base),
+		}
+		for _, total := range month.Totals {
+			item := currencyAmountResult{
+				Currency: total.Currency,
+				Amount:   usecase.FormatAmount(total.Amount, total.Currency),
+				Count:    total.Count,
+			}
+			if total.Converted {
+				item.BaseAmount = usecase.FormatAmount(total.BaseAmount, report.Base)
+			}
+			result.Totals = append(result.Totals, item)
+		}
+		for _, charge := range month.Charges {
+			currency := charge.Currency
+			result.Charges = append(result.Charges, projectedChargeResult{
+				Date:           charge.Date.Format("2006-01-02"),
+				SubscriptionID: charge.Subscription.ID,
+				ServiceName:    charge.Subscription.ServiceName,
+				Amount:         usecase.FormatAmount(charge.Amount, currency),
+				Currency:       currency,
+			})
+		}
+		response.Forecast = append(response.Forecast, result)
+	}
+	return response
+}
+
+func toSpendTotalResults(totals []usecase.SpendTotal, base string) []spendTotalResult {
+	results := make([]spendTotalResult, 0, len(totals))
+	for _, total := range totals {
+		result := toSpendTotalResult(total)
+		if total.Converted {
+			result.BaseMonthly = usecase.FormatAmount(total.BaseMonthly, base)
+			result.BaseYearly = usecase.FormatAmount(total.BaseYearly, base)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func toSpendTotalResult(total usecase.SpendTotal) spendTotalResult {
+	return spendTotalResult{
+		BankName:  total.BankName,
+		CardLast4: total.CardLast4,
+		Category:  total.Category,
+		Tag:       total.Tag,
+		Currency:  total.Currency,
+		Monthly:   usecase.FormatAmount(total.Monthly, total.Currency),
+		Yearly:    usecase.FormatAmount(total.Yearly, total.Currency),
+		Count:     total.Count,
+	}
+}
+
+func (h Handler) handleGetAnalyticsSettings(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	settings, err := h.Analytics.Settings(r.Context(), userID)
+	if err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, analyticsSettingsResult{BaseCurrency: settings.BaseCurrency})
+}
+
+func (h Handler) handleUpdateAnalyticsSettings(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	var payload analyticsSettingsPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return
+	}
+
+	settings, err := h.Analytics.UpdateSettings(r.Context(), userID, usecase.AnalyticsSettings{BaseCurrency: payload.BaseCurrency})
+	if err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, analyticsSettingsResult{BaseCurrency: settings.BaseCurrency})
+}
+
+func (h Handler) handleListRates(w http.ResponseWriter, r *http.Request) {
+	if _, ok := userIDFromContext(r.Context()); !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	rates, err := h.Rates.List(r.Context())
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "request failed"})
+		return
+	}
+
+	results := make([]rateResult, 0, len(rates))
+	for _, rate := range rates {
+		results = append(results, rateResult{
+			Base:   rate.Base,
+			Quote:  rate.Quote,
+			Rate:   rate.Rate,
+			Date:   rate.Date.Format("2006-01-02"),
+			Source: rate.Source,
+		})
+	}
+	writeJSON(w, http.StatusOK, results)
+}
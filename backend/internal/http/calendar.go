@@ -0,0 +1,168 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+// calendarFeedResult includes the token and URL only when the feed is
+// created.
+type calendarFeedResult struct {
+	Active    bool   `json:"active"`
+	Token     string `json:"token,omitempty"`
+	URL       string `json:"url,omitempty"`
+	CreatedAt string `json:"created_at,omitempty"`
+}
+
+func (h Handler) handleGetCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	createdAt, err := h.Calendar.Feed(r.Context(), userID)
+	if errors.Is(err, usecase.ErrNotFound) {
+		writeJSON(w, http.StatusOK, calendarFeedResult{})
+		return
+	}
+	if err != nil {
+		writeCalendarError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, calendarFeedResult{
+		Active:    true,
+		CreatedAt: createdAt.UTC().Format("2006-01-02T15:04:05Z"),
+	})
+}
+
+func (h Handler) handleCreateCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	token, createdAt, err := h.Calendar.CreateFeed(r.Context(), userID)
+	if err != nil {
+		writeCalendarError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, calendarFeedResult{
+		Active:    true,
+		Token:     token,
+		URL:       "/api/calendar/" + token + ".ics",
+		CreatedAt: createdAt.UTC().Format("2006-01-02T15:04:05Z"),
+	})
+}
+
+func (h Handler) handleDeleteCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.Calendar.RevokeFeed(r.Context(), userID); err != nil {
+		writeCalendarError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, calendarFeedResult{})
+}
+
+// handleCalendar serves the feed itself. Calendar apps cannot send an
+// Authorization header, so the secret token in the path authenticates the
+// request.
+func (h Handler) handleCalendar(w http.ResponseWriter, r *http.Request) {
+	_, events, err := h.Calendar.Events(r.Context(), chi.URLParam(r, "token"))
+	if err != nil {
+		writeCalendarError(w, err)
+		return
+	}
+
+	var cal icsWriter
+	now := time.Now().UTC().Format("20060102T150405Z")
+	cal.line("BEGIN:VCALENDAR")
+	cal.line("VERSION:2.0")
+	cal.line("PRODID:-//Subscribe Tracker//Charges//EN")
+	cal.line("CALSCALE:GREGORIAN")
+	cal.line("METHOD:PUBLISH")
+	cal.line("X-WR-CALNAME:Subscribe Tracker")
+	cal.line("REFRESH-INTERVAL;VALUE=DURATION:PT12H")
+	cal.line("X-PUBLISHED-TTL:PT12H")
+	for _, event := range events {
+		sub := event.Subscription
+		summary := sub.ServiceName + ": " + usecase.FormatAmount(event.Amount, event.Currency) + " " + event.Currency
+
+		cal.line("BEGIN:VEVENT")
+		cal.line("UID:" + event.UID + "@subscribe-tracker")
+		cal.line("DTSTAMP:" + now)
+		cal.line("DTSTART;VALUE=DATE:" + event.Start.Format("20060102"))
+		cal.line("DURATION:P1D")
+		cal.line("RRULE:" + event.Rule)
+		cal.line("SUMMARY:" + icsText(summary))
+		if sub.CardLast4 != "" {
+			cal.line("DESCRIPTION:" + icsText(sub.BankName+" *"+sub.CardLast4))
+		}
+		cal.line("TRANSP:TRANSPARENT")
+		cal.line("BEGIN:VALARM")
+		cal.line("ACTION:DISPLAY")
+		cal.line("DESCRIPTION:" + icsText(summary))
+		cal.line("TRIGGER:-P" + strconv.Itoa(event.LeadDays) + "D")
+		cal.line("END:VALARM")
+		cal.line("END:VEVENT")
+	}
+	cal.line("END:VCALENDAR")
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(cal.String()))
+}
+
+// icsWriter collects content lines, folding them at 75 octets without
+// splitting UTF-8 sequences.
+type icsWriter struct {
+	strings.Builder
+}
+
+func (w *icsWriter) line(value string) {
+	// Continuation lines start with a space, which counts towards the limit.
+	limit := 75
+	for len(value) > limit {
+		cut := limit
+		for cut > 0 && value[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.WriteString(value[:cut])
+		w.WriteString("\r\n ")
+		value = value[cut:]
+		limit = 74
+	}
+	w.WriteString(value)
+	w.WriteString("\r\n")
+}
+
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func icsText(value string) string {
+	return icsEscaper.Replace(value)
+}
+
+func writeCalendarError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrUnauthorized):
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	case errors.Is(err, usecase.ErrNotFound):
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "calendar feed not found"})
+	default:
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "request failed"})
+	}
+}
@@ -0,0 +1,156 @@
+package httpapi
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"subscribe_tracker/backend/internal/domain"
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+type chargePayload struct {
+	ChargeDate string `json:"charge_date"`
+	Amount     string `json:"amount"`
+	Currency   string `json:"currency"`
+	Status     string `json:"status"`
+	Note       string `json:"note"`
+}
+
+type chargeResult struct {
+	ID             string `json:"id"`
+	SubscriptionID string `json:"subscription_id"`
+	ChargeDate     string `json:"charge_date"`
+	Amount         string `json:"amount"`
+	AmountMinor    int64  `json:"amount_minor"`
+	Currency       string `json:"currency"`
+	Status         string `json:"status"`
+	Note           string `json:"note"`
+}
+
+type generateChargesPayload struct {
+	Until string `json:"until"`
+}
+
+func (h Handler) handleListCharges(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	items, err := h.Charges.List(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toChargeResults(items))
+}
+
+func (h Handler) handleRecordCharge(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	var payload chargePayload
+	if err := decodeJSON(r, &payload); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return
+	}
+
+	item, err := h.Charges.Record(r.Context(), userID, chi.URLParam(r, "id"), toChargeInput(payload))
+	if err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toChargeResult(item))
+}
+
+func (h Handler) handleCorrectCharge(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	var payload chargePayload
+	if err := decodeJSON(r, &payload); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return
+	}
+
+	item, err := h.Charges.Correct(r.Context(), userID, chi.URLParam(r, "id"), chi.URLParam(r, "chargeID"), toChargeInput(payload))
+	if err != nil {
+		writeChargeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toChargeResult(item))
+}
+
+func (h Handler) handleGenerateCharges(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	var payload generateChargesPayload
+	if err := decodeJSON(r, &payload); err != nil && !errors.Is(err, io.EOF) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return
+	}
+
+	items, err := h.Charges.GenerateExpected(r.Context(), userID, chi.URLParam(r, "id"), payload.Until)
+	if err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toChargeResults(items))
+}
+
+func toChargeInput(payload chargePayload) usecase.ChargeInput {
+	return usecase.ChargeInput{
+		ChargeDate: payload.ChargeDate,
+		Amount:     payload.Amount,
+		Currency:   payload.Currency,
+		Status:     payload.Status,
+		Note:       payload.Note,
+	}
+}
+
+func toChargeResults(items []domain.Charge) []chargeResult {
+	results := make([]chargeResult, 0, len(items))
+	for _, item := range items {
+		results = append(results, toChargeResult(item))
+	}
+	return results
+}
+
+func toChargeResult(item domain.Charge) chargeResult {
+	return chargeResult{
+		ID:             item.ID,
+		SubscriptionID: item.SubscriptionID,
+		ChargeDate:     item.ChargeDate.Format("2006-01-02"),
+		Amount:         usecase.FormatAmount(item.Amount, item.Currency),
+		AmountMinor:    item.Amount,
+		Currency:       item.Currency,
+		Status:         string(item.Status),
+		Note:           item.Note,
+	}
+}
+
+func writeChargeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, usecase.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "charge not found"})
+		return
+	}
+	writeSubscriptionError(w, err)
+}
@@ -0,0 +1,109 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+// maxImportSize bounds the multipart body of an import.
+const maxImportSize = 5 << 20
+
+type importRowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+type importResult struct {
+	DryRun  bool                 `json:"dry_run"`
+	Rows    int                  `json:"rows"`
+	Created []subscriptionResult `json:"created"`
+	Errors  []importRowError     `json:"errors"`
+}
+
+func (h Handler) handleExportSubscriptions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	var body bytes.Buffer
+	if err := h.CSV.Export(r.Context(), userID, &body); err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="subscriptions.csv"`)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body.Bytes())
+}
+
+// handleImportSubscriptions reads the CSV from the "file" part. The optional
+// "mapping" part is a JSON object of field names to CSV headers and
+// "dry_run" only validates the file.
+func (h Handler) handleImportSubscriptions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	if err := r.ParseMultipartForm(maxImportSize); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return
+	}
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return
+	}
+	defer file.Close()
+
+	var options usecase.ImportOptions
+	if value := r.FormValue("mapping"); value != "" {
+		if err := json.Unmarshal([]byte(value), &options.Mapping); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+			return
+		}
+	}
+	if value := r.FormValue("dry_run"); value != "" {
+		if options.DryRun, err = strconv.ParseBool(value); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+			return
+		}
+	}
+
+	result, err := h.CSV.Import(r.Context(), userID, file, options)
+	if err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+
+	response := importResult{
+		DryRun:  result.DryRun,
+		Rows:    result.Rows,
+		Created: make([]subscriptionResult, 0, len(result.Created)),
+		Errors:  make([]importRowError, 0, len(result.Errors)),
+	}
+	for _, item := range result.Created {
+		response.Created = append(response.Created, toSubscriptionResult(item))
+	}
+	for _, rowErr := range result.Errors {
+		response.Errors = append(response.Errors, importRowError{Row: rowErr.Row, Field: rowErr.Field, Message: rowErr.Message})
+	}
+
+	status := http.StatusOK
+	switch {
+	case len(response.Errors) > 0:
+		status = http.StatusUnprocessableEntity
+	case !result.DryRun:
+		status = http.StatusCreated
+	}
+	writeJSON(w, status, response)
+}
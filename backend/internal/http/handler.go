@@ -4,7 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"io"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/go-chi/chi/v5"
@@ -14,22 +17,83 @@ import (
 )
 
 type Handler struct {
-	Auth          usecase.AuthUsecase
-	Subscriptions usecase.SubscriptionUsecase
-	Tokens        usecase.TokenManager
+	Auth           usecase.AuthUsecase
+	Passwords      usecase.PasswordUsecase
+	Verification   usecase.VerificationUsecase
+	TwoFactor      usecase.TwoFactorUsecase
+	Subscriptions  usecase.SubscriptionUsecase
+	Analytics      usecase.AnalyticsUsecase
+	Charges        usecase.ChargeUsecase
+	Categories     usecase.CategoryUsecase
+	Tags           usecase.TagUsecase
+	PaymentMethods usecase.PaymentMethodUsecase
+	Alerts         usecase.AlertUsecase
+	Reminders      usecase.ReminderUsecase
+	Webhooks       usecase.WebhookUsecase
+	Telegram       usecase.TelegramUsecase
+	Calendar       usecase.CalendarUsecase
+	CSV            usecase.CSVUsecase
+	Statements     usecase.StatementUsecase
+	Rates          usecase.RateUsecase
+	PersonalTokens usecase.PersonalTokenUsecase
+	Tokens         usecase.TokenManager
 }
 
-func NewHandler(auth usecase.AuthUsecase, subscriptions usecase.SubscriptionUsecase, tokens usecase.TokenManager) Handler {
+func NewHandler(
+	auth usecase.AuthUsecase,
+	passwords usecase.PasswordUsecase,
+	verification usecase.VerificationUsecase,
+	twoFactor usecase.TwoFactorUsecase,
+	subscriptions usecase.SubscriptionUsecase,
+	analytics usecase.AnalyticsUsecase,
+	charges usecase.ChargeUsecase,
+	categories usecase.CategoryUsecase,
+	tags usecase.TagUsecase,
+	paymentMethods usecase.PaymentMethodUsecase,
+	alerts usecase.AlertUsecase,
+	reminders usecase.ReminderUsecase,
+	webhooks usecase.WebhookUsecase,
+	telegram usecase.TelegramUsecase,
+	calendar usecase.CalendarUsecase,
+	csv usecase.CSVUsecase,
+	statements usecase.StatementUsecase,
+	rates usecase.RateUsecase,
+	personalTokens usecase.PersonalTokenUsecase,
+	tokens usecase.TokenManager,
+) Handler {
 	return Handler{
-		Auth:          auth,
-		Subscriptions: subscriptions,
-		Tokens:        tokens,
+		Auth:           auth,
+		Passwords:      passwords,
+		Verification:   verification,
+		TwoFactor:      twoFactor,
+		Subscriptions:  subscriptions,
+		Analytics:      analytics,
+		Charges:        charges,
+		Categories:     categories,
+		Tags:           tags,
+		PaymentMethods: paymentMethods,
+		Alerts:         alerts,
+		Reminders:      reminders,
+		Webhooks:       webhooks,
+		Telegram:       telegram,
+		Calendar:       calendar,
+		CSV:            csv,
+		Statements:     statements,
+		Rates:          rates,
+		PersonalTokens: personalTokens,
+		Tokens:         tokens,
 	}
 }
 
+const defaultTrialWindowDays = 7
+
 type contextKey string
 
-const userIDKey contextKey = "user_id"
+const (
+	userIDKey    contextKey = "user_id"
+	sessionIDKey contextKey = "session_id"
+	scopesKey    contextKey = "scopes"
+)
 
 func (h Handler) Routes() http.Handler {
 	r := chi.NewRouter()
@@ -39,14 +103,102 @@ func (h Handler) Routes() http.Handler {
 		r.Route("/auth", func(r chi.Router) {
 			r.Post("/register", h.handleRegister)
 			r.Post("/login", h.handleLogin)
+			r.Post("/login/mfa", h.handleLoginMFA)
+			r.Post("/refresh", h.handleRefresh)
+			r.Post("/logout", h.handleLogout)
+			r.Post("/password/forgot", h.handleForgotPassword)
+			r.Post("/password/reset", h.handleResetPassword)
+			r.Post("/verify-email", h.handleVerifyEmail)
 		})
+		r.Get("/calendar/{token}.ics", h.handleCalendar)
 
 		r.Group(func(r chi.Router) {
 			r.Use(h.authMiddleware)
-			r.Get("/subscriptions", h.handleListSubscriptions)
-			r.Post("/subscriptions", h.handleCreateSubscription)
-			r.Put("/subscriptions/{id}", h.handleUpdateSubscription)
-			r.Delete("/subscriptions/{id}", h.handleDeleteSubscription)
+
+			// Routes personal access tokens may call, given the scope.
+			readSubscriptions := r.With(h.requireScope(domain.ScopeReadSubscriptions))
+			readSubscriptions.Get("/subscriptions", h.handleListSubscriptions)
+			readSubscriptions.Get("/subscriptions/trials", h.handleListEndingTrials)
+			readSubscriptions.Get("/subscriptions/export.csv", h.handleExportSubscriptions)
+			readSubscriptions.Get("/subscriptions/{id}/prices", h.handleListPrices)
+			readSubscriptions.Get("/subscriptions/{id}/charges", h.handleListCharges)
+			readSubscriptions.Get("/categories", h.handleListCategories)
+			readSubscriptions.Get("/tags", h.handleListTags)
+			readSubscriptions.Get("/payment-methods", h.handleListPaymentMethods)
+
+			writeSubscriptions := r.With(h.requireScope(domain.ScopeWriteSubscriptions))
+			writeSubscriptions.Post("/subscriptions/import", h.handleImportSubscriptions)
+			writeSubscriptions.Post("/statements/import", h.handleImportStatement)
+			writeSubscriptions.Post("/statements/confirm", h.handleConfirmStatement)
+			writeSubscriptions.Post("/subscriptions", h.handleCreateSubscription)
+			writeSubscriptions.Put("/subscriptions/{id}", h.handleUpdateSubscription)
+			writeSubscriptions.Delete("/subscriptions/{id}", h.handleDeleteSubscription)
+			writeSubscriptions.Post("/subscriptions/{id}/pause", h.handleSubscriptionStatus(h.Subscriptions.Pause))
+			writeSubscriptions.Post("/subscriptions/{id}/resume", h.handleSubscriptionStatus(h.Subscriptions.Resume))
+			writeSubscriptions.Post("/subscriptions/{id}/cancel", h.handleSubscriptionStatus(h.Subscriptions.Cancel))
+			writeSubscriptions.Post("/subscriptions/{id}/charges", h.handleRecordCharge)
+			writeSubscriptions.Post("/subscriptions/{id}/charges/generate", h.handleGenerateCharges)
+			writeSubscriptions.Put("/subscriptions/{id}/charges/{chargeID}", h.handleCorrectCharge)
+			writeSubscriptions.Put("/subscriptions/{id}/reminder", h.handleUpdateSubscriptionReminder)
+
+			readAnalytics := r.With(h.requireScope(domain.ScopeReadAnalytics))
+			readAnalytics.Get("/analytics/spend", h.handleSpendAnalytics)
+			readAnalytics.Get("/analytics/settings", h.handleGetAnalyticsSettings)
+			readAnalytics.Get("/rates", h.handleListRates)
+
+			// The rest needs a signed-in session.
+			r.Group(func(r chi.Router) {
+				r.Use(h.requireSession)
+				r.Get("/auth/sessions", h.handleListSessions)
+				r.Delete("/auth/sessions/{id}", h.handleRevokeSession)
+				r.Post("/auth/verify-email/resend", h.handleResendVerification)
+				r.Get("/auth/2fa", h.handleGetTwoFactor)
+				r.Post("/auth/2fa/enroll", h.handleEnrollTwoFactor)
+				r.Post("/auth/2fa/confirm", h.handleConfirmTwoFactor)
+				r.Post("/auth/2fa/disable", h.handleDisableTwoFactor)
+				r.Post("/auth/2fa/recovery-codes", h.handleRegenerateRecoveryCodes)
+
+				r.Get("/tokens", h.handleListPersonalTokens)
+				r.Post("/tokens", h.handleCreatePersonalToken)
+				r.Get("/tokens/{id}", h.handleGetPersonalToken)
+				r.Put("/tokens/{id}", h.handleUpdatePersonalToken)
+				r.Delete("/tokens/{id}", h.handleDeletePersonalToken)
+
+				r.Put("/analytics/settings", h.handleUpdateAnalyticsSettings)
+
+				r.Post("/categories", h.handleCreateCategory)
+				r.Put("/categories/{id}", h.handleUpdateCategory)
+				r.Delete("/categories/{id}", h.handleDeleteCategory)
+
+				r.Post("/tags", h.handleCreateTag)
+				r.Put("/tags/{id}", h.handleUpdateTag)
+				r.Delete("/tags/{id}", h.handleDeleteTag)
+
+				r.Post("/payment-methods", h.handleCreatePaymentMethod)
+				r.Put("/payment-methods/{id}", h.handleUpdatePaymentMethod)
+				r.Delete("/payment-methods/{id}", h.handleDeletePaymentMethod)
+				r.Post("/payment-methods/{id}/reassign", h.handleReassignPaymentMethod)
+				r.Get("/alerts/expiring-cards", h.handleListExpiringCards)
+
+				r.Get("/reminders", h.handleListReminders)
+				r.Get("/reminders/settings", h.handleGetReminderSettings)
+				r.Put("/reminders/settings", h.handleUpdateReminderSettings)
+
+				r.Get("/webhooks", h.handleListWebhooks)
+				r.Post("/webhooks", h.handleCreateWebhook)
+				r.Put("/webhooks/{id}", h.handleUpdateWebhook)
+				r.Delete("/webhooks/{id}", h.handleDeleteWebhook)
+				r.Get("/webhooks/{id}/deliveries", h.handleListDeliveries)
+				r.Post("/webhooks/{id}/deliveries/{deliveryID}/redeliver", h.handleRedeliver)
+
+				r.Post("/telegram/link-code", h.handleCreateTelegramCode)
+				r.Get("/telegram/link", h.handleGetTelegramLink)
+				r.Delete("/telegram/link", h.handleDeleteTelegramLink)
+
+				r.Get("/calendar/feed", h.handleGetCalendarFeed)
+				r.Post("/calendar/feed", h.handleCreateCalendarFeed)
+				r.Delete("/calendar/feed", h.handleDeleteCalendarFeed)
+			})
 		})
 	})
 
@@ -64,6 +216,9 @@ func recoverer(next http.Handler) http.Handler {
 	})
 }
 
+// authMiddleware accepts a JWT of a signed-in session or a personal access
+// token. Routes open to personal access tokens say which scope they need
+// with requireScope; the others are closed to them by requireSession.
 func (h Handler) authMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		tokenValue := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer"))
@@ -72,22 +227,72 @@ func (h Handler) authMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		userID, err := h.Tokens.Parse(tokenValue)
+		var access usecase.AccessToken
+		var err error
+		if strings.HasPrefix(tokenValue, usecase.PersonalTokenPrefix) {
+			access, err = h.PersonalTokens.Authenticate(r.Context(), tokenValue)
+			if err != nil && !errors.Is(err, usecase.ErrUnauthorized) {
+				writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "request failed"})
+				return
+			}
+		} else {
+			access, err = h.Tokens.Parse(tokenValue)
+		}
 		if err != nil {
 			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
 			return
 		}
 
-		ctx := context.WithValue(r.Context(), userIDKey, userID)
+		ctx := context.WithValue(r.Context(), userIDKey, access.UserID)
+		ctx = context.WithValue(ctx, sessionIDKey, access.SessionID)
+		ctx = context.WithValue(ctx, scopesKey, access.Scopes)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// requireScope lets personal access tokens through only when they have
+// scope. Session tokens always pass.
+func (h Handler) requireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !accessFromContext(r.Context()).HasScope(scope) {
+				writeJSON(w, http.StatusForbidden, map[string]string{"error": "token lacks scope " + scope})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// requireSession turns personal access tokens away.
+func (h Handler) requireSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if accessFromContext(r.Context()).Scopes != nil {
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": "not allowed for personal access tokens"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 func userIDFromContext(ctx context.Context) (string, bool) {
 	value, ok := ctx.Value(userIDKey).(string)
 	return value, ok
 }
 
+func accessFromContext(ctx context.Context) usecase.AccessToken {
+	userID, _ := userIDFromContext(ctx)
+	scopes, _ := ctx.Value(scopesKey).([]string)
+	return usecase.AccessToken{UserID: userID, SessionID: sessionIDFromContext(ctx), Scopes: scopes}
+}
+
+// sessionIDFromContext returns the session the access token was issued for;
+// it is empty for tokens issued before sessions existed.
+func sessionIDFromContext(ctx context.Context) string {
+	value, _ := ctx.Value(sessionIDKey).(string)
+	return value
+}
+
 type authRequest struct {
 	Name     string `json:"name"`
 	Email    string `json:"email"`
@@ -95,14 +300,16 @@ type authRequest struct {
 }
 
 type authResponse struct {
-	Token string     `json:"token"`
-	User  userResult `json:"user"`
+	Token        string     `json:"token"`
+	RefreshToken string     `json:"refresh_token"`
+	User         userResult `json:"user"`
 }
 
 type userResult struct {
-	ID    string `json:"id"`
-	Name  string `json:"name"`
-	Email string `json:"email"`
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
 }
 
 func (h Handler) handleRegister(w http.ResponseWriter, r *http.Request) {
@@ -112,7 +319,7 @@ func (h Handler) handleRegister(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, err := h.Auth.Register(r.Context(), req.Name, req.Email, req.Password)
+	result, err := h.Auth.Register(r.Context(), req.Name, req.Email, req.Password, clientInfo(r))
 	if err != nil {
 		writeAuthError(w, err)
 		return
@@ -128,50 +335,120 @@ func (h Handler) handleLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, err := h.Auth.Login(r.Context(), req.Email, req.Password)
+	result, err := h.Auth.Login(r.Context(), req.Email, req.Password, clientInfo(r))
 	if err != nil {
 		writeAuthError(w, err)
 		return
 	}
+	if result.MFATicket != "" {
+		writeJSON(w, http.StatusOK, mfaRequiredResponse{MFARequired: true, MFATicket: result.MFATicket})
+		return
+	}
 
 	writeJSON(w, http.StatusOK, toAuthResponse(result))
 }
 
 func toAuthResponse(result usecase.AuthResult) authResponse {
 	return authResponse{
-		Token: result.Token,
-		User:  userResult{ID: result.User.ID, Name: result.User.Name, Email: result.User.Email},
+		Token:        result.Token,
+		RefreshToken: result.RefreshToken,
+		User:         toUserResult(result.User),
+	}
+}
+
+func toUserResult(user domain.User) userResult {
+	return userResult{
+		ID:            user.ID,
+		Name:          user.Name,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerifiedAt != nil,
 	}
 }
 
 func writeAuthError(w http.ResponseWriter, err error) {
 	switch {
 	case errors.Is(err, usecase.ErrInvalidInput):
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid input"})
+		payload := map[string]string{"error": "invalid input"}
+		var fieldErr usecase.FieldError
+		if errors.As(err, &fieldErr) {
+			payload["field"] = fieldErr.Field
+		}
+		writeJSON(w, http.StatusBadRequest, payload)
 	case errors.Is(err, usecase.ErrEmailExists):
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "email already in use"})
 	case errors.Is(err, usecase.ErrUnauthorized):
 		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid credentials"})
+	case errors.Is(err, usecase.ErrTooManyAttempts):
+		writeTooManyAttempts(w, err)
 	default:
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "request failed"})
 	}
 }
 
+// writeTooManyAttempts answers 429, with Retry-After when the error says
+// how long to wait.
+func writeTooManyAttempts(w http.ResponseWriter, err error) {
+	payload := map[string]any{"error": "too many attempts"}
+	var retryErr usecase.RetryError
+	if errors.As(err, &retryErr) {
+		seconds := int(math.Ceil(retryErr.RetryAfter.Seconds()))
+		w.Header().Set("Retry-After", strconv.Itoa(seconds))
+		payload["retry_after"] = seconds
+	}
+	writeJSON(w, http.StatusTooManyRequests, payload)
+}
+
+type billingPayload struct {
+	Unit     string `json:"unit"`
+	Interval int    `json:"interval"`
+}
+
 type subscriptionPayload struct {
-	ServiceName string `json:"service_name"`
-	BankName    string `json:"bank_name"`
-	CardLast4   string `json:"card_last4"`
-	Billing     string `json:"billing_cycle"`
-	ChargeDate  string `json:"charge_date"`
+	ServiceName     string          `json:"service_name"`
+	PaymentMethodID string          `json:"payment_method_id"`
+	BankName        string          `json:"bank_name"`
+	CardLast4       string          `json:"card_last4"`
+	Billing         string          `json:"billing_cycle"`
+	Recurrence      *billingPayload `json:"billing"`
+	ChargeDate      string          `json:"charge_date"`
+	Price           string          `json:"price"`
+	Currency        string          `json:"currency"`
+
+	// TrialEndDate left out keeps the trial on update; "" removes it.
+	TrialEndDate   *string `json:"trial_end_date"`
+	PostTrialPrice string  `json:"post_trial_price"`
+
+	PriceEffectiveFrom string `json:"price_effective_from"`
+
+	CategoryID *string  `json:"category_id"`
+	TagIDs     []string `json:"tag_ids"`
 }
 
 type subscriptionResult struct {
-	ID          string `json:"id"`
-	ServiceName string `json:"service_name"`
-	BankName    string `json:"bank_name"`
-	CardLast4   string `json:"card_last4"`
-	Billing     string `json:"billing_cycle"`
-	ChargeDate  string `json:"charge_date"`
+	ID              string         `json:"id"`
+	ServiceName     string         `json:"service_name"`
+	PaymentMethodID string         `json:"payment_method_id"`
+	BankName        string         `json:"bank_name"`
+	CardLast4       string         `json:"card_last4"`
+	Billing         string         `json:"billing_cycle"`
+	Recurrence      billingPayload `json:"billing"`
+	ChargeDate      string         `json:"charge_date"`
+	Price           string         `json:"price"`
+	PriceMinor      int64          `json:"price_minor"`
+	Currency        string         `json:"currency"`
+	NextCharge      string         `json:"next_charge_date,omitempty"`
+	NextAmount      string         `json:"next_charge_amount,omitempty"`
+	NextCurrency    string         `json:"next_charge_currency,omitempty"`
+	Status          string         `json:"status"`
+	StatusDate      string         `json:"status_effective_date"`
+
+	TrialEndDate   string `json:"trial_end_date,omitempty"`
+	PostTrialPrice string `json:"post_trial_price,omitempty"`
+
+	Category *labelResult  `json:"category"`
+	Tags     []labelResult `json:"tags"`
+
+	ReminderLeadDays *int `json:"reminder_lead_days"`
 }
 
 func (h Handler) handleListSubscriptions(w http.ResponseWriter, r *http.Request) {
@@ -181,7 +458,44 @@ func (h Handler) handleListSubscriptions(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	items, err := h.Subscriptions.List(r.Context(), userID)
+	query := r.URL.Query()
+	filter := usecase.SubscriptionFilter{
+		Status:   domain.SubscriptionStatus(query.Get("status")),
+		Category: query.Get("category"),
+		Tag:      query.Get("tag"),
+	}
+	items, err := h.Subscriptions.List(r.Context(), userID, filter)
+	if err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+
+	results := make([]subscriptionResult, 0, len(items))
+	for _, item := range items {
+		results = append(results, toSubscriptionResult(item))
+	}
+
+	writeJSON(w, http.StatusOK, results)
+}
+
+func (h Handler) handleListEndingTrials(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	days := defaultTrialWindowDays
+	if value := r.URL.Query().Get("within_days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid within_days"})
+			return
+		}
+		days = parsed
+	}
+
+	items, err := h.Subscriptions.TrialsEndingWithin(r.Context(), userID, days)
 	if err != nil {
 		writeSubscriptionError(w, err)
 		return
@@ -256,40 +570,159 @@ func (h Handler) handleDeleteSubscription(w http.ResponseWriter, r *http.Request
 	writeJSON(w, http.StatusOK, map[string]string{"id": id})
 }
 
+type priceResult struct {
+	Price         string `json:"price"`
+	PriceMinor    int64  `json:"price_minor"`
+	Currency      string `json:"currency"`
+	EffectiveFrom string `json:"effective_from"`
+}
+
+func (h Handler) handleListPrices(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	items, err := h.Subscriptions.Prices(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+
+	results := make([]priceResult, 0, len(items))
+	for _, item := range items {
+		results = append(results, priceResult{
+			Price:         usecase.FormatAmount(item.Price, item.Currency),
+			PriceMinor:    item.Price,
+			Currency:      item.Currency,
+			EffectiveFrom: item.EffectiveFrom.Format("2006-01-02"),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, results)
+}
+
+type statusPayload struct {
+	EffectiveDate string `json:"effective_date"`
+}
+
+type statusTransition func(ctx context.Context, userID, id, effectiveDate string) (domain.Subscription, error)
+
+func (h Handler) handleSubscriptionStatus(transition statusTransition) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := userIDFromContext(r.Context())
+		if !ok {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+
+		var payload statusPayload
+		if err := decodeJSON(r, &payload); err != nil && !errors.Is(err, io.EOF) {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+			return
+		}
+
+		item, err := transition(r.Context(), userID, chi.URLParam(r, "id"), payload.EffectiveDate)
+		if err != nil {
+			writeSubscriptionError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, toSubscriptionResult(item))
+	}
+}
+
 func parseSubscriptionPayload(r *http.Request) (usecase.SubscriptionInput, error) {
 	var payload subscriptionPayload
 	if err := decodeJSON(r, &payload); err != nil {
 		return usecase.SubscriptionInput{}, errors.New("invalid payload")
 	}
+	return toSubscriptionInput(payload), nil
+}
+
+func toSubscriptionInput(payload subscriptionPayload) usecase.SubscriptionInput {
+	input := usecase.SubscriptionInput{
+		ServiceName:     payload.ServiceName,
+		PaymentMethodID: payload.PaymentMethodID,
+		BankName:        payload.BankName,
+		CardLast4:       payload.CardLast4,
+		Billing:         payload.Billing,
+		ChargeDate:      payload.ChargeDate,
+		Price:           payload.Price,
+		Currency:        payload.Currency,
+
+		PostTrialPrice: payload.PostTrialPrice,
 
-	return usecase.SubscriptionInput{
-		ServiceName: payload.ServiceName,
-		BankName:    payload.BankName,
-		CardLast4:   payload.CardLast4,
-		Billing:     payload.Billing,
-		ChargeDate:  payload.ChargeDate,
-	}, nil
+		PriceEffectiveFrom: payload.PriceEffectiveFrom,
+
+		CategoryID: payload.CategoryID,
+		TagIDs:     payload.TagIDs,
+	}
+	if payload.TrialEndDate != nil {
+		input.TrialEndDate = *payload.TrialEndDate
+		input.ClearTrial = strings.TrimSpace(input.TrialEndDate) == ""
+	}
+	if payload.Recurrence != nil {
+		input.BillingUnit = payload.Recurrence.Unit
+		input.Interval = payload.Recurrence.Interval
+	}
+	return input
 }
 
 func toSubscriptionResult(item domain.Subscription) subscriptionResult {
-	return subscriptionResult{
-		ID:          item.ID,
-		ServiceName: item.ServiceName,
-		BankName:    item.BankName,
-		CardLast4:   item.CardLast4,
-		Billing:     item.Billing,
-		ChargeDate:  item.ChargeDate.Format("2006-01-02"),
+	result := subscriptionResult{
+		ID:               item.ID,
+		ServiceName:      item.ServiceName,
+		PaymentMethodID:  item.PaymentMethodID,
+		BankName:         item.BankName,
+		CardLast4:        item.CardLast4,
+		Billing:          usecase.BillingPresetName(item.Billing),
+		Recurrence:       billingPayload{Unit: string(item.Billing.Unit), Interval: item.Billing.Interval},
+		ChargeDate:       item.ChargeDate.Format("2006-01-02"),
+		Price:            usecase.FormatAmount(item.Price, item.Currency),
+		PriceMinor:       item.Price,
+		Currency:         item.Currency,
+		Status:           string(item.Status),
+		StatusDate:       item.StatusEffectiveDate.Format("2006-01-02"),
+		ReminderLeadDays: item.ReminderLeadDays,
+		Tags:             make([]labelResult, 0, len(item.Tags)),
+	}
+	if item.Category != nil {
+		result.Category = &labelResult{ID: item.Category.ID, Name: item.Category.Name}
+	}
+	for _, tag := range item.Tags {
+		result.Tags = append(result.Tags, labelResult{ID: tag.ID, Name: tag.Name})
 	}
+	if !item.NextChargeDate.IsZero() {
+		result.NextCharge = item.NextChargeDate.Format("2006-01-02")
+		result.NextAmount = usecase.FormatAmount(item.NextChargeAmount, item.NextChargeCurrency)
+		result.NextCurrency = item.NextChargeCurrency
+	}
+	if item.TrialEndDate != nil {
+		result.TrialEndDate = item.TrialEndDate.Format("2006-01-02")
+		result.PostTrialPrice = usecase.FormatAmount(item.PostTrialPrice, item.Currency)
+	}
+	return result
 }
 
 func writeSubscriptionError(w http.ResponseWriter, err error) {
 	switch {
 	case errors.Is(err, usecase.ErrInvalidInput):
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid input"})
+		payload := map[string]string{"error": "invalid input"}
+		var fieldErr usecase.FieldError
+		if errors.As(err, &fieldErr) {
+			payload["field"] = fieldErr.Field
+		}
+		writeJSON(w, http.StatusBadRequest, payload)
 	case errors.Is(err, usecase.ErrUnauthorized):
 		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
 	case errors.Is(err, usecase.ErrNotFound):
 		writeJSON(w, http.StatusNotFound, map[string]string{"error": "subscription not found"})
+	case errors.Is(err, usecase.ErrInvalidTransition):
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "invalid status transition"})
+	case errors.Is(err, usecase.ErrEmailNotVerified):
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": "email not verified"})
 	default:
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "request failed"})
 	}
@@ -0,0 +1,188 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+type labelPayload struct {
+	Name string `json:"name"`
+}
+
+type labelResult struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func (h Handler) handleListCategories(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	items, err := h.Categories.List(r.Context(), userID)
+	if err != nil {
+		writeLabelError(w, "category", err)
+		return
+	}
+
+	results := make([]labelResult, 0, len(items))
+	for _, item := range items {
+		results = append(results, labelResult{ID: item.ID, Name: item.Name})
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+func (h Handler) handleCreateCategory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	var payload labelPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return
+	}
+
+	item, err := h.Categories.Create(r.Context(), userID, payload.Name)
+	if err != nil {
+		writeLabelError(w, "category", err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, labelResult{ID: item.ID, Name: item.Name})
+}
+
+func (h Handler) handleUpdateCategory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	var payload labelPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return
+	}
+
+	item, err := h.Categories.Rename(r.Context(), userID, chi.URLParam(r, "id"), payload.Name)
+	if err != nil {
+		writeLabelError(w, "category", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, labelResult{ID: item.ID, Name: item.Name})
+}
+
+func (h Handler) handleDeleteCategory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if err := h.Categories.Delete(r.Context(), userID, id); err != nil {
+		writeLabelError(w, "category", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"id": id})
+}
+
+func (h Handler) handleListTags(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	items, err := h.Tags.List(r.Context(), userID)
+	if err != nil {
+		writeLabelError(w, "tag", err)
+		return
+	}
+
+	results := make([]labelResult, 0, len(items))
+	for _, item := range items {
+		results = append(results, labelResult{ID: item.ID, Name: item.Name})
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+func (h Handler) handleCreateTag(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	var payload labelPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return
+	}
+
+	item, err := h.Tags.Create(r.Context(), userID, payload.Name)
+	if err != nil {
+		writeLabelError(w, "tag", err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, labelResult{ID: item.ID, Name: item.Name})
+}
+
+func (h Handler) handleUpdateTag(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	var payload labelPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return
+	}
+
+	item, err := h.Tags.Rename(r.Context(), userID, chi.URLParam(r, "id"), payload.Name)
+	if err != nil {
+		writeLabelError(w, "tag", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, labelResult{ID: item.ID, Name: item.Name})
+}
+
+func (h Handler) handleDeleteTag(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if err := h.Tags.Delete(r.Context(), userID, id); err != nil {
+		writeLabelError(w, "tag", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"id": id})
+}
+
+func writeLabelError(w http.ResponseWriter, kind string, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidInput):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid input"})
+	case errors.Is(err, usecase.ErrUnauthorized):
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	case errors.Is(err, usecase.ErrNotFound):
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": kind + " not found"})
+	case errors.Is(err, usecase.ErrNameExists):
+		writeJSON(w, http.StatusConflict, map[string]string{"error": kind + " already exists"})
+	default:
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "request failed"})
+	}
+}
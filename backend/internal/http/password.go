@@ -0,0 +1,62 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+type forgotPasswordPayload struct {
+	Email string `json:"email"`
+}
+
+type resetPasswordPayload struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+func (h Handler) handleForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var payload forgotPasswordPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return
+	}
+
+	if err := h.Passwords.Forgot(r.Context(), payload.Email); err != nil {
+		writePasswordError(w, err)
+		return
+	}
+	// The same response is sent whether or not the account exists.
+	writeJSON(w, http.StatusAccepted, map[string]bool{"sent": true})
+}
+
+func (h Handler) handleResetPassword(w http.ResponseWriter, r *http.Request) {
+	var payload resetPasswordPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return
+	}
+
+	if err := h.Passwords.Reset(r.Context(), payload.Token, payload.Password); err != nil {
+		writePasswordError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]bool{"reset": true})
+}
+
+func writePasswordError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidInput):
+		payload := map[string]string{"error": "invalid input"}
+		var fieldErr usecase.FieldError
+		if errors.As(err, &fieldErr) {
+			payload["field"] = fieldErr.Field
+		}
+		writeJSON(w, http.StatusBadRequest, payload)
+	case errors.Is(err, usecase.ErrUnauthorized):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid or expired token"})
+	default:
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "request failed"})
+	}
+}
@@ -0,0 +1,170 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"subscribe_tracker/backend/internal/domain"
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+type paymentMethodPayload struct {
+	BankName  string `json:"bank_name"`
+	CardLast4 string `json:"card_last4"`
+	Brand     string `json:"brand"`
+	ExpMonth  int    `json:"exp_month"`
+	ExpYear   int    `json:"exp_year"`
+	Nickname  string `json:"nickname"`
+}
+
+type paymentMethodResult struct {
+	ID        string `json:"id"`
+	BankName  string `json:"bank_name"`
+	CardLast4 string `json:"card_last4"`
+	Brand     string `json:"brand"`
+	ExpMonth  int    `json:"exp_month,omitempty"`
+	ExpYear   int    `json:"exp_year,omitempty"`
+	Nickname  string `json:"nickname"`
+}
+
+type reassignPayload struct {
+	To string `json:"to"`
+}
+
+func (h Handler) handleListPaymentMethods(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	items, err := h.PaymentMethods.List(r.Context(), userID)
+	if err != nil {
+		writePaymentMethodError(w, err)
+		return
+	}
+
+	results := make([]paymentMethodResult, 0, len(items))
+	for _, item := range items {
+		results = append(results, toPaymentMethodResult(item))
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+func (h Handler) handleCreatePaymentMethod(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	var payload paymentMethodPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return
+	}
+
+	item, err := h.PaymentMethods.Create(r.Context(), userID, toPaymentMethodInput(payload))
+	if err != nil {
+		writePaymentMethodError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, toPaymentMethodResult(item))
+}
+
+func (h Handler) handleUpdatePaymentMethod(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	var payload paymentMethodPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return
+	}
+
+	item, err := h.PaymentMethods.Update(r.Context(), userID, chi.URLParam(r, "id"), toPaymentMethodInput(payload))
+	if err != nil {
+		writePaymentMethodError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toPaymentMethodResult(item))
+}
+
+func (h Handler) handleDeletePaymentMethod(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if err := h.PaymentMethods.Delete(r.Context(), userID, id); err != nil {
+		writePaymentMethodError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"id": id})
+}
+
+func (h Handler) handleReassignPaymentMethod(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	var payload reassignPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return
+	}
+
+	moved, err := h.PaymentMethods.Reassign(r.Context(), userID, chi.URLParam(r, "id"), payload.To)
+	if err != nil {
+		writePaymentMethodError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int64{"moved": moved})
+}
+
+func toPaymentMethodInput(payload paymentMethodPayload) usecase.PaymentMethodInput {
+	return usecase.PaymentMethodInput{
+		BankName:  payload.BankName,
+		CardLast4: payload.CardLast4,
+		Brand:     payload.Brand,
+		ExpMonth:  payload.ExpMonth,
+		ExpYear:   payload.ExpYear,
+		Nickname:  payload.Nickname,
+	}
+}
+
+func toPaymentMethodResult(item domain.PaymentMethod) paymentMethodResult {
+	return paymentMethodResult{
+		ID:        item.ID,
+		BankName:  item.BankName,
+		CardLast4: item.CardLast4,
+		Brand:     item.Brand,
+		ExpMonth:  item.ExpMonth,
+		ExpYear:   item.ExpYear,
+		Nickname:  item.Nickname,
+	}
+}
+
+func writePaymentMethodError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidInput):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid input"})
+	case errors.Is(err, usecase.ErrUnauthorized):
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	case errors.Is(err, usecase.ErrNotFound):
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "payment method not found"})
+	case errors.Is(err, usecase.ErrInUse):
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "payment method is used by subscriptions"})
+	default:
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "request failed"})
+	}
+}
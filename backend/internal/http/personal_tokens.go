@@ -0,0 +1,172 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"subscribe_tracker/backend/internal/domain"
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+type personalTokenPayload struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays *int     `json:"expires_in_days"`
+}
+
+// personalTokenResult includes the token itself only when it is created.
+type personalTokenResult struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Token      string   `json:"token,omitempty"`
+	Prefix     string   `json:"prefix"`
+	Scopes     []string `json:"scopes"`
+	ExpiresAt  string   `json:"expires_at,omitempty"`
+	LastUsedAt string   `json:"last_used_at,omitempty"`
+	CreatedAt  string   `json:"created_at"`
+}
+
+func (h Handler) handleListPersonalTokens(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	items, err := h.PersonalTokens.List(r.Context(), userID)
+	if err != nil {
+		writePersonalTokenError(w, err)
+		return
+	}
+
+	results := make([]personalTokenResult, 0, len(items))
+	for _, item := range items {
+		results = append(results, toPersonalTokenResult(item))
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+func (h Handler) handleGetPersonalToken(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	item, err := h.PersonalTokens.Get(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		writePersonalTokenError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toPersonalTokenResult(item))
+}
+
+func (h Handler) handleCreatePersonalToken(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	var payload personalTokenPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return
+	}
+
+	item, token, err := h.PersonalTokens.Create(r.Context(), userID, toPersonalTokenInput(payload))
+	if err != nil {
+		writePersonalTokenError(w, err)
+		return
+	}
+	result := toPersonalTokenResult(item)
+	result.Token = token
+	writeJSON(w, http.StatusCreated, result)
+}
+
+func (h Handler) handleUpdatePersonalToken(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	var payload personalTokenPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return
+	}
+	if payload.ExpiresInDays != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid input", "field": "expires_in_days"})
+		return
+	}
+
+	item, err := h.PersonalTokens.Update(r.Context(), userID, chi.URLParam(r, "id"), toPersonalTokenInput(payload))
+	if err != nil {
+		writePersonalTokenError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toPersonalTokenResult(item))
+}
+
+func (h Handler) handleDeletePersonalToken(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if err := h.PersonalTokens.Delete(r.Context(), userID, id); err != nil {
+		writePersonalTokenError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"id": id})
+}
+
+func toPersonalTokenInput(payload personalTokenPayload) usecase.PersonalTokenInput {
+	return usecase.PersonalTokenInput{
+		Name:          payload.Name,
+		Scopes:        payload.Scopes,
+		ExpiresInDays: payload.ExpiresInDays,
+	}
+}
+
+func toPersonalTokenResult(item domain.PersonalToken) personalTokenResult {
+	result := personalTokenResult{
+		ID:        item.ID,
+		Name:      item.Name,
+		Prefix:    item.Prefix,
+		Scopes:    item.Scopes,
+		CreatedAt: item.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+	}
+	if item.ExpiresAt != nil {
+		result.ExpiresAt = item.ExpiresAt.UTC().Format("2006-01-02T15:04:05Z")
+	}
+	if item.LastUsedAt != nil {
+		result.LastUsedAt = item.LastUsedAt.UTC().Format("2006-01-02T15:04:05Z")
+	}
+	return result
+}
+
+func writePersonalTokenError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidInput):
+		payload := map[string]string{"error": "invalid input"}
+		var fieldErr usecase.FieldError
+		if errors.As(err, &fieldErr) {
+			payload["field"] = fieldErr.Field
+		}
+		writeJSON(w, http.StatusBadRequest, payload)
+	case errors.Is(err, usecase.ErrUnauthorized):
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	case errors.Is(err, usecase.ErrNotFound):
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "token not found"})
+	case errors.Is(err, usecase.ErrNameExists):
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "token already exists"})
+	default:
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "request failed"})
+	}
+}
@@ -0,0 +1,134 @@
+package httpapi
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+type reminderSettingsPayload struct {
+	LeadDays *int    `json:"lead_days"`
+	Locale   *string `json:"locale"`
+}
+
+type reminderSettingsResult struct {
+	LeadDays int    `json:"lead_days"`
+	Locale   string `json:"locale"`
+}
+
+// subscriptionReminderPayload takes a null lead_days to fall back to the
+// lead time of the user.
+type subscriptionReminderPayload struct {
+	LeadDays *int `json:"lead_days"`
+}
+
+type reminderResult struct {
+	ID             string `json:"id"`
+	SubscriptionID string `json:"subscription_id"`
+	ServiceName    string `json:"service_name"`
+	ChargeDate     string `json:"charge_date"`
+	RemindAt       string `json:"remind_at"`
+	Amount         string `json:"amount"`
+	Currency       string `json:"currency"`
+	Status         string `json:"status"`
+	Attempts       int    `json:"attempts"`
+	LastError      string `json:"last_error,omitempty"`
+	SentAt         string `json:"sent_at,omitempty"`
+}
+
+func (h Handler) handleListReminders(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	items, err := h.Reminders.List(r.Context(), userID)
+	if err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+
+	results := make([]reminderResult, 0, len(items))
+	for _, item := range items {
+		result := reminderResult{
+			ID:             item.ID,
+			SubscriptionID: item.SubscriptionID,
+			ServiceName:    item.ServiceName,
+			ChargeDate:     item.ChargeDate.Format("2006-01-02"),
+			RemindAt:       item.RemindAt.Format("2006-01-02"),
+			Amount:         usecase.FormatAmount(item.Amount, item.Currency),
+			Currency:       item.Currency,
+			Status:         string(item.Status),
+			Attempts:       item.Attempts,
+			LastError:      item.LastError,
+		}
+		if item.SentAt != nil {
+			result.SentAt = item.SentAt.UTC().Format("2006-01-02T15:04:05Z")
+		}
+		results = append(results, result)
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+func (h Handler) handleGetReminderSettings(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	settings, err := h.Reminders.Settings(r.Context(), userID)
+	if err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, reminderSettingsResult{LeadDays: settings.LeadDays, Locale: settings.Locale})
+}
+
+func (h Handler) handleUpdateReminderSettings(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	var payload reminderSettingsPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return
+	}
+
+	settings, err := h.Reminders.UpdateSettings(r.Context(), userID, usecase.NotificationSettingsInput{
+		LeadDays: payload.LeadDays,
+		Locale:   payload.Locale,
+	})
+	if err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, reminderSettingsResult{LeadDays: settings.LeadDays, Locale: settings.Locale})
+}
+
+func (h Handler) handleUpdateSubscriptionReminder(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	var payload subscriptionReminderPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return
+	}
+
+	item, err := h.Reminders.SetSubscriptionLeadDays(r.Context(), userID, chi.URLParam(r, "id"), payload.LeadDays)
+	if err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toSubscriptionResult(item))
+}
@@ -0,0 +1,122 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"subscribe_tracker/backend/internal/domain"
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+// fakeTokens accepts any token as the session of user-1.
+type fakeTokens struct {
+	usecase.TokenManager
+}
+
+func (fakeTokens) Parse(string) (usecase.AccessToken, error) {
+	return usecase.AccessToken{UserID: "user-1", SessionID: "session-1"}, nil
+}
+
+type fakeUsers struct {
+	usecase.UserRepository
+}
+
+func (fakeUsers) FindByID(_ context.Context, id string) (domain.User, error) {
+	return domain.User{ID: id, Email: "anna@example.com", ReminderLeadDays: 3}, nil
+}
+
+// fakeSubscriptions holds the subscriptions of user-1 by ID.
+type fakeSubscriptions struct {
+	usecase.SubscriptionRepository
+	subs map[string]domain.Subscription
+}
+
+func (f fakeSubscriptions) ListByUserID(context.Context, string) ([]domain.Subscription, error) {
+	var result []domain.Subscription
+	for _, sub := range f.subs {
+		result = append(result, sub)
+	}
+	return result, nil
+}
+
+func (f fakeSubscriptions) UpdateReminderLeadDays(_ context.Context, _, id string, days *int) (domain.Subscription, error) {
+	sub, ok := f.subs[id]
+	if !ok {
+		return domain.Subscription{}, usecase.ErrNotFound
+	}
+	sub.ReminderLeadDays = days
+	f.subs[id] = sub
+	return sub, nil
+}
+
+type fakeReminders struct {
+	usecase.ReminderRepository
+}
+
+func (fakeReminders) Sync(context.Context, string, []domain.Reminder) error {
+	return nil
+}
+
+func TestUpdateSubscriptionReminderReturnsLeadDays(t *testing.T) {
+	chargeDate := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	subs := fakeSubscriptions{subs: map[string]domain.Subscription{
+		"sub-1": {
+			ID:                  "sub-1",
+			UserID:              "user-1",
+			ServiceName:         "Netflix",
+			Billing:             domain.BillingCycle{Unit: domain.BillingMonth, Interval: 1},
+			ChargeDate:          chargeDate,
+			Price:               999,
+			Currency:            "EUR",
+			Status:              domain.StatusActive,
+			StatusEffectiveDate: chargeDate,
+		},
+	}}
+	handler := Handler{
+		Reminders: usecase.NewReminderUsecase(fakeReminders{}, subs, fakeUsers{}, nil, nil),
+		Tokens:    fakeTokens{},
+	}.Routes()
+
+	tests := []struct {
+		name string
+		body string
+		want *int
+	}{
+		{name: "override", body: `{"lead_days": 7}`, want: intPtr(7)},
+		{name: "no reminder", body: `{"lead_days": 0}`, want: intPtr(0)},
+		{name: "default of the user", body: `{"lead_days": null}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/api/subscriptions/sub-1/reminder", strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer token")
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+			}
+			var result subscriptionResult
+			if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+				t.Fatal(err)
+			}
+			switch {
+			case tt.want == nil && result.ReminderLeadDays != nil:
+				t.Errorf("reminder_lead_days = %d, want null", *result.ReminderLeadDays)
+			case tt.want != nil && result.ReminderLeadDays == nil:
+				t.Errorf("reminder_lead_days = null, want %d", *tt.want)
+			case tt.want != nil && *result.ReminderLeadDays != *tt.want:
+				t.Errorf("reminder_lead_days = %d, want %d", *result.ReminderLeadDays, *tt.want)
+			}
+		})
+	}
+}
+
+func intPtr(value int) *int {
+	return &value
+}
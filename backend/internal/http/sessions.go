@@ -0,0 +1,130 @@
+package httpapi
+
+import (
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"subscribe_tracker/backend/internal/domain"
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+type refreshPayload struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type sessionResult struct {
+	ID         string `json:"id"`
+	UserAgent  string `json:"user_agent"`
+	IPAddress  string `json:"ip_address"`
+	StartedAt  string `json:"started_at"`
+	LastUsedAt string `json:"last_used_at"`
+	ExpiresAt  string `json:"expires_at"`
+	Current    bool   `json:"current"`
+}
+
+func (h Handler) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	var payload refreshPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return
+	}
+
+	result, err := h.Auth.Refresh(r.Context(), payload.RefreshToken, clientInfo(r))
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toAuthResponse(result))
+}
+
+func (h Handler) handleLogout(w http.ResponseWriter, r *http.Request) {
+	var payload refreshPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return
+	}
+
+	if err := h.Auth.Logout(r.Context(), payload.RefreshToken); err != nil {
+		writeSessionError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]bool{"logged_out": true})
+}
+
+func (h Handler) handleListSessions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	items, err := h.Auth.ListSessions(r.Context(), userID)
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+
+	current := sessionIDFromContext(r.Context())
+	results := make([]sessionResult, 0, len(items))
+	for _, item := range items {
+		results = append(results, toSessionResult(item, current))
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+func (h Handler) handleRevokeSession(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if err := h.Auth.RevokeSession(r.Context(), userID, id); err != nil {
+		writeSessionError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"id": id})
+}
+
+func toSessionResult(session domain.Session, current string) sessionResult {
+	return sessionResult{
+		ID:         session.FamilyID,
+		UserAgent:  session.UserAgent,
+		IPAddress:  session.IPAddress,
+		StartedAt:  session.StartedAt.UTC().Format("2006-01-02T15:04:05Z"),
+		LastUsedAt: session.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+		ExpiresAt:  session.ExpiresAt.UTC().Format("2006-01-02T15:04:05Z"),
+		Current:    current != "" && session.FamilyID == current,
+	}
+}
+
+// clientInfo describes the device making the request. The address is the
+// peer of the connection.
+func clientInfo(r *http.Request) usecase.ClientInfo {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	userAgent := r.UserAgent()
+	if len(userAgent) > 512 {
+		userAgent = userAgent[:512]
+	}
+	return usecase.ClientInfo{UserAgent: userAgent, IPAddress: ip}
+}
+
+func writeSessionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidInput):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid input"})
+	case errors.Is(err, usecase.ErrUnauthorized):
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid refresh token"})
+	case errors.Is(err, usecase.ErrNotFound):
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "session not found"})
+	default:
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "request failed"})
+	}
+}
@@ -0,0 +1,123 @@
+package httpapi
+
+import (
+	"io"
+	"net/http"
+
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+// maxStatementSize bounds the multipart body of a statement upload.
+const maxStatementSize = 10 << 20
+
+// candidateResult mirrors subscriptionPayload so that a confirmed candidate
+// can be sent back after dropping the informational fields.
+type candidateResult struct {
+	ServiceName    string         `json:"service_name"`
+	Merchant       string         `json:"merchant"`
+	BankName       string         `json:"bank_name"`
+	CardLast4      string         `json:"card_last4"`
+	Billing        string         `json:"billing_cycle"`
+	Recurrence     billingPayload `json:"billing"`
+	ChargeDate     string         `json:"charge_date"`
+	NextChargeDate string         `json:"next_charge_date"`
+	Price          string         `json:"price"`
+	PriceMinor     int64          `json:"price_minor"`
+	Currency       string         `json:"currency"`
+	Charges        []string       `json:"charges"`
+	SubscriptionID string         `json:"subscription_id,omitempty"`
+}
+
+type confirmPayload struct {
+	Subscriptions []subscriptionPayload `json:"subscriptions"`
+}
+
+// handleImportStatement reads the statement from the "file" part; the
+// optional "bank_name" and "card_last4" parts are used when the statement
+// does not name them.
+func (h Handler) handleImportStatement(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxStatementSize)
+	if err := r.ParseMultipartForm(maxStatementSize); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return
+	}
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return
+	}
+
+	candidates, err := h.Statements.Detect(r.Context(), userID, data, usecase.StatementOptions{
+		BankName:  r.FormValue("bank_name"),
+		CardLast4: r.FormValue("card_last4"),
+	})
+	if err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+
+	results := make([]candidateResult, 0, len(candidates))
+	for _, item := range candidates {
+		result := candidateResult{
+			ServiceName:    item.ServiceName,
+			Merchant:       item.Merchant,
+			BankName:       item.BankName,
+			CardLast4:      item.CardLast4,
+			Billing:        usecase.BillingPresetName(item.Billing),
+			Recurrence:     billingPayload{Unit: string(item.Billing.Unit), Interval: item.Billing.Interval},
+			ChargeDate:     item.ChargeDate.Format("2006-01-02"),
+			NextChargeDate: item.NextChargeDate.Format("2006-01-02"),
+			Price:          usecase.FormatAmount(item.Price, item.Currency),
+			PriceMinor:     item.Price,
+			Currency:       item.Currency,
+			Charges:        make([]string, 0, len(item.Charges)),
+			SubscriptionID: item.SubscriptionID,
+		}
+		for _, date := range item.Charges {
+			result.Charges = append(result.Charges, date.Format("2006-01-02"))
+		}
+		results = append(results, result)
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+func (h Handler) handleConfirmStatement(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	var payload confirmPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return
+	}
+	inputs := make([]usecase.SubscriptionInput, 0, len(payload.Subscriptions))
+	for _, item := range payload.Subscriptions {
+		inputs = append(inputs, toSubscriptionInput(item))
+	}
+
+	items, err := h.Statements.Confirm(r.Context(), userID, inputs)
+	if err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+	results := make([]subscriptionResult, 0, len(items))
+	for _, item := range items {
+		results = append(results, toSubscriptionResult(item))
+	}
+	writeJSON(w, http.StatusCreated, results)
+}
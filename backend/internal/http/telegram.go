@@ -0,0 +1,75 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+type telegramCodeResult struct {
+	Code      string `json:"code"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+type telegramLinkResult struct {
+	Linked bool `json:"linked"`
+}
+
+func (h Handler) handleCreateTelegramCode(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	code, expiresAt, err := h.Telegram.CreateLinkCode(r.Context(), userID)
+	if err != nil {
+		writeTelegramError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, telegramCodeResult{
+		Code:      code,
+		ExpiresAt: expiresAt.UTC().Format("2006-01-02T15:04:05Z"),
+	})
+}
+
+func (h Handler) handleGetTelegramLink(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	_, err := h.Telegram.Chat(r.Context(), userID)
+	if err != nil && !errors.Is(err, usecase.ErrNotFound) {
+		writeTelegramError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, telegramLinkResult{Linked: err == nil})
+}
+
+func (h Handler) handleDeleteTelegramLink(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.Telegram.Unlink(r.Context(), userID); err != nil {
+		writeTelegramError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, telegramLinkResult{Linked: false})
+}
+
+func writeTelegramError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrUnauthorized):
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	case errors.Is(err, usecase.ErrNotFound):
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "telegram chat not linked"})
+	default:
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "request failed"})
+	}
+}
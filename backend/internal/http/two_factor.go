@@ -0,0 +1,179 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+type mfaRequiredResponse struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFATicket   string `json:"mfa_ticket"`
+}
+
+type loginMFAPayload struct {
+	Ticket string `json:"ticket"`
+	Code   string `json:"code"`
+}
+
+type twoFactorCodePayload struct {
+	Code string `json:"code"`
+}
+
+// reauthPayload confirms a sensitive change with the password and a code
+// from the authenticator app or a recovery code.
+type reauthPayload struct {
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
+type twoFactorStatusResult struct {
+	Enabled           bool `json:"enabled"`
+	Pending           bool `json:"pending"`
+	RecoveryCodesLeft int  `json:"recovery_codes_left"`
+}
+
+type twoFactorEnrollmentResult struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauth_uri"`
+}
+
+type recoveryCodesResult struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+func (h Handler) handleLoginMFA(w http.ResponseWriter, r *http.Request) {
+	var payload loginMFAPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return
+	}
+
+	result, err := h.Auth.LoginMFA(r.Context(), payload.Ticket, payload.Code, clientInfo(r))
+	if err != nil {
+		writeAuthError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toAuthResponse(result))
+}
+
+func (h Handler) handleGetTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	status, err := h.TwoFactor.Status(r.Context(), userID)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, twoFactorStatusResult{
+		Enabled:           status.Enabled,
+		Pending:           status.Pending,
+		RecoveryCodesLeft: status.RecoveryCodesLeft,
+	})
+}
+
+func (h Handler) handleEnrollTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	enrollment, err := h.TwoFactor.Enroll(r.Context(), userID)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, twoFactorEnrollmentResult{Secret: enrollment.Secret, OtpauthURI: enrollment.URI})
+}
+
+func (h Handler) handleConfirmTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	var payload twoFactorCodePayload
+	if err := decodeJSON(r, &payload); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return
+	}
+
+	codes, err := h.TwoFactor.Confirm(r.Context(), userID, payload.Code)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, recoveryCodesResult{RecoveryCodes: codes})
+}
+
+func (h Handler) handleDisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	var payload reauthPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return
+	}
+
+	if err := h.TwoFactor.Disable(r.Context(), userID, payload.Password, payload.Code, clientInfo(r)); err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, twoFactorStatusResult{})
+}
+
+func (h Handler) handleRegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	var payload reauthPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return
+	}
+
+	codes, err := h.TwoFactor.RegenerateRecoveryCodes(r.Context(), userID, payload.Password, payload.Code, clientInfo(r))
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, recoveryCodesResult{RecoveryCodes: codes})
+}
+
+func writeTwoFactorError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidInput):
+		payload := map[string]string{"error": "invalid input"}
+		var fieldErr usecase.FieldError
+		if errors.As(err, &fieldErr) {
+			payload["field"] = fieldErr.Field
+		}
+		writeJSON(w, http.StatusBadRequest, payload)
+	case errors.Is(err, usecase.ErrUnauthorized):
+		// The session is fine; the password or code it was confirmed with
+		// is not.
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": "invalid credentials"})
+	case errors.Is(err, usecase.ErrNotFound):
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "two-factor authentication not enrolled"})
+	case errors.Is(err, usecase.ErrTwoFactorEnabled):
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "two-factor authentication already enabled"})
+	case errors.Is(err, usecase.ErrTooManyAttempts):
+		writeTooManyAttempts(w, err)
+	default:
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "request failed"})
+	}
+}
@@ -0,0 +1,54 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+type verifyEmailPayload struct {
+	Token string `json:"token"`
+}
+
+func (h Handler) handleVerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var payload verifyEmailPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return
+	}
+
+	user, err := h.Verification.Verify(r.Context(), payload.Token)
+	if err != nil {
+		writeVerificationError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toUserResult(user))
+}
+
+func (h Handler) handleResendVerification(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	sent, err := h.Verification.Request(r.Context(), userID)
+	if err != nil {
+		writeVerificationError(w, err)
+		return
+	}
+	// Nothing is sent when the email is already verified.
+	writeJSON(w, http.StatusOK, map[string]bool{"sent": sent, "email_verified": !sent})
+}
+
+func writeVerificationError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrUnauthorized):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid or expired token"})
+	case errors.Is(err, usecase.ErrNotFound):
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "user not found"})
+	default:
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "request failed"})
+	}
+}
@@ -0,0 +1,199 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"subscribe_tracker/backend/internal/domain"
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+type webhookPayload struct {
+	URL    string   `json:"url"`
+	Secret string   `json:"secret"`
+	Events []string `json:"events"`
+	Active *bool    `json:"active"`
+}
+
+// webhookResult includes the secret only when the webhook is created.
+type webhookResult struct {
+	ID     string   `json:"id"`
+	URL    string   `json:"url"`
+	Secret string   `json:"secret,omitempty"`
+	Events []string `json:"events"`
+	Active bool     `json:"active"`
+}
+
+type deliveryResult struct {
+	ID             string `json:"id"`
+	Event          string `json:"event"`
+	Status         string `json:"status"`
+	Attempts       int    `json:"attempts"`
+	ResponseStatus int    `json:"response_status,omitempty"`
+	LastError      string `json:"last_error,omitempty"`
+	CreatedAt      string `json:"created_at"`
+	DeliveredAt    string `json:"delivered_at,omitempty"`
+}
+
+func (h Handler) handleListWebhooks(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	items, err := h.Webhooks.List(r.Context(), userID)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	results := make([]webhookResult, 0, len(items))
+	for _, item := range items {
+		results = append(results, toWebhookResult(item))
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+func (h Handler) handleCreateWebhook(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	var payload webhookPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return
+	}
+
+	item, err := h.Webhooks.Create(r.Context(), userID, toWebhookInput(payload))
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	result := toWebhookResult(item)
+	result.Secret = item.Secret
+	writeJSON(w, http.StatusCreated, result)
+}
+
+func (h Handler) handleUpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	var payload webhookPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return
+	}
+
+	item, err := h.Webhooks.Update(r.Context(), userID, chi.URLParam(r, "id"), toWebhookInput(payload))
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toWebhookResult(item))
+}
+
+func (h Handler) handleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if err := h.Webhooks.Delete(r.Context(), userID, id); err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"id": id})
+}
+
+func (h Handler) handleListDeliveries(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	items, err := h.Webhooks.Deliveries(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	results := make([]deliveryResult, 0, len(items))
+	for _, item := range items {
+		results = append(results, toDeliveryResult(item))
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+func (h Handler) handleRedeliver(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	item, err := h.Webhooks.Redeliver(r.Context(), userID, chi.URLParam(r, "id"), chi.URLParam(r, "deliveryID"))
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, toDeliveryResult(item))
+}
+
+func toWebhookInput(payload webhookPayload) usecase.WebhookInput {
+	return usecase.WebhookInput{
+		URL:    payload.URL,
+		Secret: payload.Secret,
+		Events: payload.Events,
+		Active: payload.Active,
+	}
+}
+
+func toWebhookResult(item domain.Webhook) webhookResult {
+	return webhookResult{
+		ID:     item.ID,
+		URL:    item.URL,
+		Events: item.Events,
+		Active: item.Active,
+	}
+}
+
+func toDeliveryResult(item domain.WebhookDelivery) deliveryResult {
+	result := deliveryResult{
+		ID:             item.ID,
+		Event:          item.Event,
+		Status:         string(item.Status),
+		Attempts:       item.Attempts,
+		ResponseStatus: item.ResponseStatus,
+		LastError:      item.LastError,
+		CreatedAt:      item.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+	}
+	if item.DeliveredAt != nil {
+		result.DeliveredAt = item.DeliveredAt.UTC().Format("2006-01-02T15:04:05Z")
+	}
+	return result
+}
+
+func writeWebhookError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidInput):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid input"})
+	case errors.Is(err, usecase.ErrUnauthorized):
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	case errors.Is(err, usecase.ErrNotFound):
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "webhook not found"})
+	default:
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "request failed"})
+	}
+}
@@ -0,0 +1,31 @@
+package notify
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"subscribe_tracker/backend/internal/domain"
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+// LogNotifier writes reminders and account emails to the server log. It is
+// used when no other notifier is configured.
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(_ context.Context, user domain.User, reminder domain.Reminder) error {
+	log.Printf("reminder for %s: %s charges %s %s on %s",
+		user.Email, reminder.ServiceName, usecase.FormatAmount(reminder.Amount, reminder.Currency),
+		reminder.Currency, reminder.ChargeDate.Format("2006-01-02"))
+	return nil
+}
+
+func (LogNotifier) SendPasswordReset(_ context.Context, user domain.User, link string, expiresAt time.Time) error {
+	log.Printf("password reset for %s: %s (valid until %s)", user.Email, link, expiresAt.UTC().Format(time.RFC3339))
+	return nil
+}
+
+func (LogNotifier) SendEmailVerification(_ context.Context, user domain.User, link string, expiresAt time.Time) error {
+	log.Printf("email verification for %s: %s (valid until %s)", user.Email, link, expiresAt.UTC().Format(time.RFC3339))
+	return nil
+}
@@ -0,0 +1,42 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"subscribe_tracker/backend/internal/domain"
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+// Multi sends every reminder through all of its notifiers. It fails only when
+// none of them delivered, so a broken channel does not cause duplicates on the
+// working ones when the reminder is retried. Notifiers that cannot reach the
+// user count neither way; when none can, it returns usecase.ErrNoRecipient.
+type Multi []usecase.Notifier
+
+func (m Multi) Notify(ctx context.Context, user domain.User, reminder domain.Reminder) error {
+	var (
+		delivered int
+		errs      []error
+	)
+	for _, notifier := range m {
+		err := notifier.Notify(ctx, user, reminder)
+		switch {
+		case err == nil:
+			delivered++
+		case errors.Is(err, usecase.ErrNoRecipient):
+		default:
+			log.Printf("notify %s: %v", user.Email, err)
+			errs = append(errs, err)
+		}
+	}
+	switch {
+	case delivered > 0 || len(m) == 0:
+		return nil
+	case len(errs) > 0:
+		return errors.Join(errs...)
+	default:
+		return usecase.ErrNoRecipient
+	}
+}
@@ -0,0 +1,63 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"subscribe_tracker/backend/internal/domain"
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+// countingNotifier fails with err and counts its calls.
+type countingNotifier struct {
+	err   error
+	calls int
+}
+
+func (n *countingNotifier) Notify(context.Context, domain.User, domain.Reminder) error {
+	n.calls++
+	return n.err
+}
+
+func TestMulti(t *testing.T) {
+	failure := errors.New("smtp down")
+
+	tests := []struct {
+		name    string
+		errs    []error
+		wantErr error
+	}{
+		{name: "all delivered", errs: []error{nil, nil}},
+		{name: "one of two failed", errs: []error{failure, nil}},
+		{name: "delivered where the user can be reached", errs: []error{usecase.ErrNoRecipient, nil}},
+		{name: "all failed", errs: []error{failure, failure}, wantErr: failure},
+		// Nothing was delivered, so the failure has to be retried.
+		{name: "failed where the user can be reached", errs: []error{failure, usecase.ErrNoRecipient}, wantErr: failure},
+		{name: "no recipient", errs: []error{usecase.ErrNoRecipient, usecase.ErrNoRecipient}, wantErr: usecase.ErrNoRecipient},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var multi Multi
+			var notifiers []*countingNotifier
+			for _, err := range tt.errs {
+				notifier := &countingNotifier{err: err}
+				notifiers = append(notifiers, notifier)
+				multi = append(multi, notifier)
+			}
+
+			err := multi.Notify(context.Background(), domain.User{Email: "anna@example.com"}, domain.Reminder{})
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("err = %v, want nil", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			for i, notifier := range notifiers {
+				if notifier.calls != 1 {
+					t.Errorf("notifier %d called %d times, want 1", i, notifier.calls)
+				}
+			}
+		})
+	}
+}
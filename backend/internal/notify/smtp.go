@@ -0,0 +1,240 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"subscribe_tracker/backend/internal/domain"
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+const smtpTimeout = 30 * time.Second
+
+// SMTPConfig points at a mail server. Authentication is skipped when
+// Username is empty. With StartTLS the connection is upgraded before
+// authenticating and servers without STARTTLS are refused; TLSConfig
+// overrides the default verification of the server certificate.
+type SMTPConfig struct {
+	Host      string
+	Port      string
+	Username  string
+	Password  string
+	From      string
+	StartTLS  bool
+	TLSConfig *tls.Config
+}
+
+// SMTPNotifier sends reminders and account emails over SMTP, rendered in the
+// locale of the recipient.
+type SMTPNotifier struct {
+	Config    SMTPConfig
+	templates templates
+}
+
+func NewSMTPNotifier(config SMTPConfig) (SMTPNotifier, error) {
+	if _, err := mail.ParseAddress(config.From); err != nil {
+		return SMTPNotifier{}, fmt.Errorf("smtp: invalid sender %q: %w", config.From, err)
+	}
+	set, err := loadTemplates()
+	if err != nil {
+		return SMTPNotifier{}, fmt.Errorf("smtp: templates: %w", err)
+	}
+	return SMTPNotifier{Config: config, templates: set}, nil
+}
+
+type reminderEmail struct {
+	Name        string
+	ServiceName string
+	Amount      string
+	Currency    string
+	ChargeDate  string
+}
+
+func (n SMTPNotifier) Notify(ctx context.Context, user domain.User, reminder domain.Reminder) error {
+	return n.Send(ctx, user, "reminder", reminderEmail{
+		Name:        user.Name,
+		ServiceName: reminder.ServiceName,
+		Amount:      usecase.FormatAmount(reminder.Amount, reminder.Currency),
+		Currency:    reminder.Currency,
+		ChargeDate:  formatDate(user.Locale, reminder.ChargeDate),
+	})
+}
+
+type passwordResetEmail struct {
+	Name      string
+	Link      string
+	ExpiresAt string
+}
+
+func (n SMTPNotifier) SendPasswordReset(ctx context.Context, user domain.User, link string, expiresAt time.Time) error {
+	return n.Send(ctx, user, "password_reset", passwordResetEmail{
+		Name:      user.Name,
+		Link:      link,
+		ExpiresAt: formatTime(user.Locale, expiresAt),
+	})
+}
+
+type emailVerificationEmail struct {
+	Name      string
+	Email     string
+	Link      string
+	ExpiresAt string
+}
+
+func (n SMTPNotifier) SendEmailVerification(ctx context.Context, user domain.User, link string, expiresAt time.Time) error {
+	return n.Send(ctx, user, "email_verification", emailVerificationEmail{
+		Name:      user.Name,
+		Email:     user.Email,
+		Link:      link,
+		ExpiresAt: formatTime(user.Locale, expiresAt),
+	})
+}
+
+// Send renders the template name with data in the locale of user and mails
+// it to them.
+func (n SMTPNotifier) Send(ctx context.Context, user domain.User, name string, data any) error {
+	msg, err := n.templates.render(user.Locale, name, data)
+	if err != nil {
+		return err
+	}
+	from, err := mail.ParseAddress(n.Config.From)
+	if err != nil {
+		return err
+	}
+	to := &mail.Address{Name: user.Name, Address: user.Email}
+
+	body, err := buildMessage(from, to, msg)
+	if err != nil {
+		return err
+	}
+	return n.deliver(ctx, from.Address, to.Address, body)
+}
+
+func (n SMTPNotifier) deliver(ctx context.Context, from, to string, body []byte) error {
+	dialer := net.Dialer{Timeout: smtpTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(n.Config.Host, n.Config.Port))
+	if err != nil {
+		return fmt.Errorf("smtp: dial: %w", err)
+	}
+	deadline := time.Now().Add(smtpTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, n.Config.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("smtp: greeting: %w", err)
+	}
+	defer client.Close()
+
+	if n.Config.StartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return errors.New("smtp: server does not support STARTTLS")
+		}
+		tlsConfig := n.Config.TLSConfig
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{ServerName: n.Config.Host}
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("smtp: starttls: %w", err)
+		}
+	}
+	if n.Config.Username != "" {
+		auth := smtp.PlainAuth("", n.Config.Username, n.Config.Password, n.Config.Host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("smtp: auth: %w", err)
+		}
+	}
+
+	if err := client.Mail(from); err != nil {
+		return fmt.Errorf("smtp: mail from: %w", err)
+	}
+	if err := client.Rcpt(to); err != nil {
+		return fmt.Errorf("smtp: rcpt to: %w", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp: data: %w", err)
+	}
+	if _, err := w.Write(body); err != nil {
+		return fmt.Errorf("smtp: data: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp: data: %w", err)
+	}
+	return client.Quit()
+}
+
+// buildMessage encodes msg as a multipart/alternative email with a plain-text
+// and an HTML part.
+func buildMessage(from, to *mail.Address, msg message) ([]byte, error) {
+	var buf bytes.Buffer
+	parts := multipart.NewWriter(&buf)
+
+	domainPart := from.Address[strings.LastIndex(from.Address, "@")+1:]
+	headers := []string{
+		"From: " + from.String(),
+		"To: " + to.String(),
+		"Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"Message-ID: <" + randomID() + "@" + domainPart + ">",
+		"MIME-Version: 1.0",
+		"Content-Type: multipart/alternative; boundary=" + parts.Boundary(),
+	}
+	var out bytes.Buffer
+	out.WriteString(strings.Join(headers, "\r\n") + "\r\n\r\n")
+
+	for _, part := range []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	out.Write(buf.Bytes())
+	return out.Bytes(), nil
+}
+
+func randomID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,349 @@
+package notify
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"subscribe_tracker/backend/internal/domain"
+)
+
+// fakeSMTP is an SMTP server for a single session. It records what the
+// client did and the message it sent.
+type fakeSMTP struct {
+	listener net.Listener
+	tls      *tls.Config
+	username string
+	password string
+
+	done     chan struct{}
+	startTLS bool
+	authed   bool
+	from     string
+	to       string
+	data     string
+}
+
+// newFakeSMTP starts a server; with tlsConfig it offers STARTTLS and with a
+// username it offers AUTH PLAIN and requires it before MAIL.
+func newFakeSMTP(t *testing.T, tlsConfig *tls.Config, username, password string) *fakeSMTP {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &fakeSMTP{listener: listener, tls: tlsConfig, username: username, password: password, done: make(chan struct{})}
+	t.Cleanup(func() { listener.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *fakeSMTP) config() SMTPConfig {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return SMTPConfig{Host: host, Port: port, From: "Subscribe Tracker <noreply@example.com>"}
+}
+
+// wait blocks until the session is over.
+func (s *fakeSMTP) wait(t *testing.T) {
+	t.Helper()
+	select {
+	case <-s.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("smtp session did not finish")
+	}
+}
+
+func (s *fakeSMTP) serve() {
+	defer close(s.done)
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer func() { conn.Close() }()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	text := textproto.NewConn(conn)
+	reply := func(line string) { text.PrintfLine("%s", line) }
+	reply("220 fake ESMTP")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			lines := []string{"fake"}
+			if s.tls != nil && !s.startTLS {
+				lines = append(lines, "STARTTLS")
+			}
+			if s.username != "" {
+				lines = append(lines, "AUTH PLAIN")
+			}
+			for i, l := range lines {
+				sep := "-"
+				if i == len(lines)-1 {
+					sep = " "
+				}
+				reply("250" + sep + l)
+			}
+		case "STARTTLS":
+			reply("220 ready")
+			tlsConn := tls.Server(conn, s.tls)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			text = textproto.NewConn(conn)
+			s.startTLS = true
+		case "AUTH":
+			mechanism, initial, _ := strings.Cut(arg, " ")
+			decoded, _ := base64.StdEncoding.DecodeString(initial)
+			if mechanism != "PLAIN" || string(decoded) != "\x00"+s.username+"\x00"+s.password {
+				reply("535 authentication failed")
+				continue
+			}
+			s.authed = true
+			reply("235 ok")
+		case "MAIL":
+			if s.username != "" && !s.authed {
+				reply("530 authentication required")
+				continue
+			}
+			s.from = arg
+			reply("250 ok")
+		case "RCPT":
+			s.to = arg
+			reply("250 ok")
+		case "DATA":
+			reply("354 go ahead")
+			data, err := io.ReadAll(text.DotReader())
+			if err != nil {
+				return
+			}
+			s.data = string(data)
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 unknown command")
+		}
+	}
+}
+
+// testTLS returns a certificate for 127.0.0.1 as a server config and a client
+// config trusting it.
+func testTLS(t *testing.T) (server, client *tls.Config) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	server = &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+	client = &tls.Config{RootCAs: pool, ServerName: "127.0.0.1"}
+	return server, client
+}
+
+func testNotifier(t *testing.T, config SMTPConfig) SMTPNotifier {
+	t.Helper()
+	notifier, err := NewSMTPNotifier(config)
+	if err != nil {
+		t.Fatalf("new notifier: %v", err)
+	}
+	return notifier
+}
+
+func testReminder() domain.Reminder {
+	return domain.Reminder{
+		ServiceName: "Кинопоиск",
+		ChargeDate:  time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC),
+		Amount:      29900,
+		Currency:    "RUB",
+	}
+}
+
+func TestSMTPNotifierStartTLSAndAuth(t *testing.T) {
+	serverTLS, clientTLS := testTLS(t)
+	server := newFakeSMTP(t, serverTLS, "user", "secret")
+	config := server.config()
+	config.StartTLS = true
+	config.TLSConfig = clientTLS
+	config.Username = "user"
+	config.Password = "secret"
+
+	user := domain.User{Name: "Анна", Email: "anna@example.com", Locale: "ru"}
+	if err := testNotifier(t, config).Notify(context.Background(), user, testReminder()); err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+	server.wait(t)
+
+	if !server.startTLS {
+		t.Error("connection was not upgraded with STARTTLS")
+	}
+	if !server.authed {
+		t.Error("client did not authenticate")
+	}
+	if server.from != "FROM:<noreply@example.com>" || server.to != "TO:<anna@example.com>" {
+		t.Errorf("envelope = %q, %q", server.from, server.to)
+	}
+	if server.data == "" {
+		t.Error("no message was sent")
+	}
+}
+
+func TestSMTPNotifierRequiresStartTLS(t *testing.T) {
+	server := newFakeSMTP(t, nil, "", "")
+	config := server.config()
+	config.StartTLS = true
+
+	err := testNotifier(t, config).Notify(context.Background(), domain.User{Email: "anna@example.com"}, testReminder())
+	if err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Fatalf("err = %v, want missing STARTTLS", err)
+	}
+	server.wait(t)
+	if server.data != "" {
+		t.Error("message was sent without STARTTLS")
+	}
+}
+
+func TestSMTPNotifierAuth(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		password string
+		wantErr  string
+	}{
+		{name: "wrong password", username: "user", password: "wrong", wantErr: "smtp: auth"},
+		{name: "not configured", wantErr: "smtp: mail from"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeSMTP(t, nil, "user", "secret")
+			config := server.config()
+			config.Username = tt.username
+			config.Password = tt.password
+
+			err := testNotifier(t, config).Notify(context.Background(), domain.User{Email: "anna@example.com"}, testReminder())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want %q", err, tt.wantErr)
+			}
+			server.wait(t)
+			if server.data != "" {
+				t.Error("message was sent without authentication")
+			}
+		})
+	}
+}
+
+func TestSMTPNotifierMessage(t *testing.T) {
+	tests := []struct {
+		locale  string
+		subject string
+		text    string
+		html    string
+	}{
+		{
+			locale:  "ru",
+			subject: "Скоро списание: Кинопоиск — 299.00 RUB",
+			text:    "05.03.2024 по подписке «Кинопоиск» будет списано 299.00 RUB.",
+			html:    "<strong>299.00 RUB</strong>",
+		},
+		{
+			locale:  "en",
+			subject: "Upcoming charge: Кинопоиск — 299.00 RUB",
+			text:    "Кинопоиск will charge 299.00 RUB on March 5, 2024.",
+			html:    "on <strong>March 5, 2024</strong>",
+		},
+		{
+			// Locales without templates fall back to the default one.
+			locale:  "de",
+			subject: "Скоро списание: Кинопоиск — 299.00 RUB",
+			text:    "Здравствуйте, Anna!",
+			html:    `<html lang="ru">`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			server := newFakeSMTP(t, nil, "", "")
+			user := domain.User{Name: "Anna", Email: "anna@example.com", Locale: tt.locale}
+			if err := testNotifier(t, server.config()).Notify(context.Background(), user, testReminder()); err != nil {
+				t.Fatalf("notify: %v", err)
+			}
+			server.wait(t)
+
+			msg, err := mail.ReadMessage(strings.NewReader(server.data))
+			if err != nil {
+				t.Fatalf("read message: %v", err)
+			}
+			rawSubject := msg.Header.Get("Subject")
+			if !strings.HasPrefix(rawSubject, "=?utf-8?q?") {
+				t.Errorf("subject %q is not Q-encoded", rawSubject)
+			}
+			subject, err := new(mime.WordDecoder).DecodeHeader(rawSubject)
+			if err != nil || subject != tt.subject {
+				t.Errorf("subject = %q (%v), want %q", subject, err, tt.subject)
+			}
+
+			mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+			if err != nil || mediaType != "multipart/alternative" {
+				t.Fatalf("content type = %q (%v)", mediaType, err)
+			}
+			parts := multipart.NewReader(msg.Body, params["boundary"])
+			for _, want := range []struct{ contentType, body string }{
+				{"text/plain; charset=utf-8", tt.text},
+				{"text/html; charset=utf-8", tt.html},
+			} {
+				part, err := parts.NextPart()
+				if err != nil {
+					t.Fatalf("next part: %v", err)
+				}
+				if got := part.Header.Get("Content-Type"); got != want.contentType {
+					t.Errorf("part content type = %q, want %q", got, want.contentType)
+				}
+				body, err := io.ReadAll(part)
+				if err != nil {
+					t.Fatalf("read part: %v", err)
+				}
+				if !strings.Contains(string(body), want.body) {
+					t.Errorf("%s part does not contain %q:\n%s", want.contentType, want.body, body)
+				}
+			}
+			if _, err := parts.NextPart(); err != io.EOF {
+				t.Errorf("unexpected extra part: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package notify
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// dateLayouts formats dates in messages of each locale.
+var dateLayouts = map[string]string{
+	"ru": "02.01.2006",
+	"en": "January 2, 2006",
+}
+
+// timeLayouts formats times of day in messages of each locale. Times are in
+// UTC since users have no time zone.
+var timeLayouts = map[string]string{
+	"ru": "02.01.2006 15:04 UTC",
+	"en": "January 2, 2006 15:04 UTC",
+}
+
+// message is a rendered email.
+type message struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// templates holds the email templates of every locale, keyed by
+// "<locale>/<name>". A template consists of <name>.txt defining "subject" and
+// "text" and <name>.html defining "html".
+type templates struct {
+	text map[string]*texttemplate.Template
+	html map[string]*htmltemplate.Template
+}
+
+func loadTemplates() (templates, error) {
+	set := templates{
+		text: map[string]*texttemplate.Template{},
+		html: map[string]*htmltemplate.Template{},
+	}
+	err := fs.WalkDir(templateFS, "templates", func(file string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		key := strings.TrimSuffix(strings.TrimPrefix(file, "templates/"), path.Ext(file))
+		switch path.Ext(file) {
+		case ".txt":
+			tmpl, err := texttemplate.ParseFS(templateFS, file)
+			if err != nil {
+				return err
+			}
+			set.text[key] = tmpl
+		case ".html":
+			tmpl, err := htmltemplate.ParseFS(templateFS, file)
+			if err != nil {
+				return err
+			}
+			set.html[key] = tmpl
+		}
+		return nil
+	})
+	return set, err
+}
+
+// render executes the template name in locale, falling back to the default
+// locale when it has no such template.
+func (t templates) render(locale, name string, data any) (message, error) {
+	key := locale + "/" + name
+	if t.text[key] == nil || t.html[key] == nil {
+		key = usecase.DefaultLocale + "/" + name
+	}
+	text, html := t.text[key], t.html[key]
+	if text == nil || html == nil {
+		return message{}, fmt.Errorf("notify: no template %q", name)
+	}
+
+	var subject, body, htmlBody bytes.Buffer
+	if err := text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return message{}, err
+	}
+	if err := text.ExecuteTemplate(&body, "text", data); err != nil {
+		return message{}, err
+	}
+	if err := html.ExecuteTemplate(&htmlBody, "html", data); err != nil {
+		return message{}, err
+	}
+	return message{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    body.String(),
+		HTML:    htmlBody.String(),
+	}, nil
+}
+
+func formatDate(locale string, date time.Time) string {
+	layout, ok := dateLayouts[locale]
+	if !ok {
+		layout = dateLayouts[usecase.DefaultLocale]
+	}
+	return date.Format(layout)
+}
+
+func formatTime(locale string, t time.Time) string {
+	layout, ok := timeLayouts[locale]
+	if !ok {
+		layout = timeLayouts[usecase.DefaultLocale]
+	}
+	return t.UTC().Format(layout)
+}
@@ -0,0 +1,26 @@
+package rates
+
+import (
+	"context"
+	"os"
+
+	"subscribe_tracker/backend/internal/domain"
+)
+
+// FileProvider reads rates from a JSON file maintained by hand. The file is
+// read on every refresh, so edits apply without a restart.
+type FileProvider struct {
+	Path string
+}
+
+func NewFileProvider(path string) FileProvider {
+	return FileProvider{Path: path}
+}
+
+func (p FileProvider) Rates(_ context.Context) ([]domain.Rate, error) {
+	data, err := os.ReadFile(p.Path)
+	if err != nil {
+		return nil, err
+	}
+	return decode(data, "file")
+}
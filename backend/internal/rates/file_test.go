@@ -0,0 +1,51 @@
+package rates
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	provider := NewFileProvider(path)
+
+	if _, err := provider.Rates(context.Background()); !os.IsNotExist(err) {
+		t.Errorf("err = %v, want a missing file", err)
+	}
+
+	// A file may list rates against several bases; a document without a
+	// date is dated today.
+	if err := os.WriteFile(path, []byte(`[
+		{"base": "USD", "date": "2026-01-31", "rates": {"EUR": 0.92}},
+		{"base": "EUR", "rates": {"RUB": 98.4}}
+	]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	rates, err := provider.Rates(context.Background())
+	if err != nil {
+		t.Fatalf("rates: %v", err)
+	}
+	sortRates(rates)
+	if len(rates) != 2 {
+		t.Fatalf("rates = %+v", rates)
+	}
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if got := rates[0]; got.Base != "EUR" || got.Quote != "RUB" || got.Rate != 98.4 || !got.Date.Equal(today) || got.Source != "file" {
+		t.Errorf("rates[0] = %+v", got)
+	}
+	if got := rates[1]; got.Base != "USD" || got.Quote != "EUR" || got.Rate != 0.92 || !got.Date.Equal(time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("rates[1] = %+v", got)
+	}
+
+	// The file is read again on every call.
+	if err := os.WriteFile(path, []byte(`{"base": "USD", "rates": {"GBP": 0.79}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	rates, err = provider.Rates(context.Background())
+	if err != nil || len(rates) != 1 || rates[0].Quote != "GBP" {
+		t.Errorf("rates = %+v, err = %v", rates, err)
+	}
+}
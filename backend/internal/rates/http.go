@@ -0,0 +1,50 @@
+package rates
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"subscribe_tracker/backend/internal/domain"
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+// maxResponseSize bounds the rates document read from the API.
+const maxResponseSize = 1 << 20
+
+// HTTPProvider fetches rates from URL, which may point at a local fake
+// server in tests.
+type HTTPProvider struct {
+	URL  string
+	HTTP usecase.HTTPClient
+}
+
+func NewHTTPProvider(url string, httpClient usecase.HTTPClient) HTTPProvider {
+	return HTTPProvider{
+		URL:  url,
+		HTTP: httpClient,
+	}
+}
+
+func (p HTTPProvider) Rates(ctx context.Context) ([]domain.Rate, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("rates: %s responded with status %d", p.URL, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, err
+	}
+	return decode(data, "http")
+}
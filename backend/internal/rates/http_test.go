@@ -0,0 +1,84 @@
+package rates
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"subscribe_tracker/backend/internal/domain"
+)
+
+// sortRates orders rates by base and quote, since documents hold them in a
+// map.
+func sortRates(rates []domain.Rate) {
+	sort.Slice(rates, func(i, j int) bool {
+		if rates[i].Base != rates[j].Base {
+			return rates[i].Base < rates[j].Base
+		}
+		return rates[i].Quote < rates[j].Quote
+	})
+}
+
+func TestHTTPProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/latest" || r.Header.Get("Accept") != "application/json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"base": "usd", "date": "2026-01-31", "rates": {"EUR": 0.92, "rub": 90.5, "USD": 1, "GBP": 0}}`))
+	}))
+	defer server.Close()
+
+	rates, err := NewHTTPProvider(server.URL+"/latest", server.Client()).Rates(context.Background())
+	if err != nil {
+		t.Fatalf("rates: %v", err)
+	}
+	sortRates(rates)
+
+	date := time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+	want := []domain.Rate{
+		{Base: "USD", Quote: "EUR", Rate: 0.92, Date: date, Source: "http"},
+		{Base: "USD", Quote: "RUB", Rate: 90.5, Date: date, Source: "http"},
+	}
+	if len(rates) != len(want) {
+		t.Fatalf("rates = %+v, want %+v", rates, want)
+	}
+	for i := range want {
+		if rates[i] != want[i] {
+			t.Errorf("rates[%d] = %+v, want %+v", i, rates[i], want[i])
+		}
+	}
+}
+
+func TestHTTPProviderErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{name: "error status", status: http.StatusServiceUnavailable, body: `{}`, wantErr: "status 503"},
+		{name: "invalid JSON", status: http.StatusOK, body: `<html>`, wantErr: "invalid character"},
+		{name: "missing base", status: http.StatusOK, body: `{"rates": {"EUR": 0.92}}`, wantErr: "missing base"},
+		{name: "invalid date", status: http.StatusOK, body: `{"base": "USD", "date": "31.01.2026"}`, wantErr: "invalid date"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			_, err := NewHTTPProvider(server.URL, server.Client()).Rates(context.Background())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
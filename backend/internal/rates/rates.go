@@ -0,0 +1,64 @@
+// Package rates implements exchange rate providers. Both read the same JSON
+// document, {"base": "USD", "date": "2026-01-31", "rates": {"EUR": 0.92}},
+// which is also what Frankfurter-style APIs return; a file may hold an array
+// of them to list rates against several bases.
+package rates
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"subscribe_tracker/backend/internal/domain"
+)
+
+type document struct {
+	Base  string             `json:"base"`
+	Date  string             `json:"date"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+// decode reads one document or an array of them. Documents without a date
+// are dated today.
+func decode(data []byte, source string) ([]domain.Rate, error) {
+	var docs []document
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("[")) {
+		if err := json.Unmarshal(data, &docs); err != nil {
+			return nil, err
+		}
+	} else {
+		var doc document
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+
+	var results []domain.Rate
+	for _, doc := range docs {
+		base := strings.ToUpper(strings.TrimSpace(doc.Base))
+		if base == "" {
+			return nil, errors.New("rates: missing base currency")
+		}
+		date := time.Now().UTC().Truncate(24 * time.Hour)
+		if doc.Date != "" {
+			parsed, err := time.Parse("2006-01-02", doc.Date)
+			if err != nil {
+				return nil, fmt.Errorf("rates: invalid date %q", doc.Date)
+			}
+			date = parsed
+		}
+		for quote, rate := range doc.Rates {
+			quote = strings.ToUpper(strings.TrimSpace(quote))
+			if rate <= 0 || quote == base {
+				continue
+			}
+			results = append(results, domain.Rate{Base: base, Quote: quote, Rate: rate, Date: date, Source: source})
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,103 @@
+// Package memory keeps state in the memory of the process. It suits a
+// single API instance and tests; replicas do not see each other's state.
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"subscribe_tracker/backend/internal/domain"
+)
+
+type loginAttemptKey struct {
+	scope   domain.LoginScope
+	subject string
+}
+
+type LoginAttemptRepository struct {
+	mu       sync.Mutex
+	attempts map[loginAttemptKey]domain.LoginAttempts
+	lockouts []domain.LoginLockout
+}
+
+func NewLoginAttemptRepository() *LoginAttemptRepository {
+	return &LoginAttemptRepository{attempts: map[loginAttemptKey]domain.LoginAttempts{}}
+}
+
+func (r *LoginAttemptRepository) Find(_ context.Context, scope domain.LoginScope, subject string) (domain.LoginAttempts, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if attempts, ok := r.attempts[loginAttemptKey{scope, subject}]; ok {
+		return attempts, nil
+	}
+	return domain.LoginAttempts{Scope: scope, Subject: subject}, nil
+}
+
+// RecordFailure prunes entries that are past their window and lockout on the
+// way, so the map does not grow with every address ever seen.
+func (r *LoginAttemptRepository) RecordFailure(_ context.Context, scope domain.LoginScope, subject string, window time.Duration) (domain.LoginAttempts, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for key, attempts := range r.attempts {
+		if now.Sub(attempts.LastFailedAt) > window && !locked(attempts, now) {
+			delete(r.attempts, key)
+		}
+	}
+
+	key := loginAttemptKey{scope, subject}
+	attempts, ok := r.attempts[key]
+	if !ok {
+		attempts = domain.LoginAttempts{Scope: scope, Subject: subject}
+	}
+	if now.Sub(attempts.LastFailedAt) > window {
+		attempts.Failures = 0
+	}
+	attempts.Failures++
+	attempts.LastFailedAt = now
+	r.attempts[key] = attempts
+	return attempts, nil
+}
+
+func (r *LoginAttemptRepository) Lock(_ context.Context, lockout domain.LoginLockout) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := loginAttemptKey{lockout.Scope, lockout.Subject}
+	if attempts, ok := r.attempts[key]; ok {
+		until := lockout.LockedUntil
+		attempts.Failures = 0
+		attempts.LockedUntil = &until
+		r.attempts[key] = attempts
+	}
+	lockout.CreatedAt = time.Now()
+	r.lockouts = append(r.lockouts, lockout)
+	return nil
+}
+
+// Reset leaves a lockout that started in the meantime in place.
+func (r *LoginAttemptRepository) Reset(_ context.Context, scope domain.LoginScope, subject string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := loginAttemptKey{scope, subject}
+	if attempts, ok := r.attempts[key]; ok && !locked(attempts, time.Now()) {
+		delete(r.attempts, key)
+	}
+	return nil
+}
+
+// Lockouts returns the lockouts recorded so far, oldest first.
+func (r *LoginAttemptRepository) Lockouts() []domain.LoginLockout {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]domain.LoginLockout(nil), r.lockouts...)
+}
+
+func locked(attempts domain.LoginAttempts, now time.Time) bool {
+	return attempts.LockedUntil != nil && attempts.LockedUntil.After(now)
+}
@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+type CalendarRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewCalendarRepository(db *pgxpool.Pool) CalendarRepository {
+	return CalendarRepository{DB: db}
+}
+
+func (r CalendarRepository) FindFeed(ctx context.Context, userID string) (time.Time, error) {
+	var createdAt time.Time
+	err := r.DB.QueryRow(ctx, `
+		SELECT created_at FROM calendar_feeds WHERE user_id = $1
+	`, userID).Scan(&createdAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return time.Time{}, usecase.ErrNotFound
+		}
+		return time.Time{}, err
+	}
+	return createdAt, nil
+}
+
+// SetFeed stores a new token for the user, replacing the previous one.
+func (r CalendarRepository) SetFeed(ctx context.Context, userID, tokenHash string) (time.Time, error) {
+	var createdAt time.Time
+	err := r.DB.QueryRow(ctx, `
+		INSERT INTO calendar_feeds (user_id, token_hash)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET token_hash = EXCLUDED.token_hash, created_at = NOW()
+		RETURNING created_at
+	`, userID, tokenHash).Scan(&createdAt)
+	return createdAt, err
+}
+
+func (r CalendarRepository) FindUserIDByFeed(ctx context.Context, tokenHash string) (string, error) {
+	var userID string
+	err := r.DB.QueryRow(ctx, `
+		SELECT user_id FROM calendar_feeds WHERE token_hash = $1
+	`, tokenHash).Scan(&userID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return "", usecase.ErrNotFound
+		}
+		return "", err
+	}
+	return userID, nil
+}
+
+func (r CalendarRepository) DeleteFeed(ctx context.Context, userID string) error {
+	cmd, err := r.DB.Exec(ctx, `
+		DELETE FROM calendar_feeds WHERE user_id = $1
+	`, userID)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return usecase.ErrNotFound
+	}
+	return nil
+}
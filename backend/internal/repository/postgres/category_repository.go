@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"subscribe_tracker/backend/internal/domain"
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+type CategoryRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewCategoryRepository(db *pgxpool.Pool) CategoryRepository {
+	return CategoryRepository{DB: db}
+}
+
+func (r CategoryRepository) ListByUserID(ctx context.Context, userID string) ([]domain.Category, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT id, user_id, name
+		FROM categories
+		WHERE user_id = $1
+		ORDER BY name ASC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []domain.Category
+	for rows.Next() {
+		var item domain.Category
+		if err := rows.Scan(&item.ID, &item.UserID, &item.Name); err != nil {
+			return nil, err
+		}
+		results = append(results, item)
+	}
+	return results, rows.Err()
+}
+
+func (r CategoryRepository) Create(ctx context.Context, category domain.Category) (domain.Category, error) {
+	var created domain.Category
+	err := r.DB.QueryRow(ctx, `
+		INSERT INTO categories (user_id, name)
+		VALUES ($1, $2)
+		RETURNING id, user_id, name
+	`, category.UserID, category.Name).Scan(&created.ID, &created.UserID, &created.Name)
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
+			return domain.Category{}, usecase.ErrNameExists
+		}
+		return domain.Category{}, err
+	}
+	return created, nil
+}
+
+func (r CategoryRepository) Update(ctx context.Context, category domain.Category) (domain.Category, error) {
+	var updated domain.Category
+	err := r.DB.QueryRow(ctx, `
+		UPDATE categories
+		SET name = $1
+		WHERE id = $2 AND user_id = $3
+		RETURNING id, user_id, name
+	`, category.Name, category.ID, category.UserID).Scan(&updated.ID, &updated.UserID, &updated.Name)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.Category{}, usecase.ErrNotFound
+		}
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
+			return domain.Category{}, usecase.ErrNameExists
+		}
+		return domain.Category{}, err
+	}
+	return updated, nil
+}
+
+func (r CategoryRepository) Delete(ctx context.Context, userID, id string) error {
+	cmd, err := r.DB.Exec(ctx, `
+		DELETE FROM categories WHERE id = $1 AND user_id = $2
+	`, id, userID)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return usecase.ErrNotFound
+	}
+	return nil
+}
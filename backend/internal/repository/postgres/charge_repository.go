@@ -0,0 +1,122 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"subscribe_tracker/backend/internal/domain"
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+const chargeColumns = `id, user_id, subscription_id, charge_date, amount_minor, currency, status, note`
+
+type ChargeRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewChargeRepository(db *pgxpool.Pool) ChargeRepository {
+	return ChargeRepository{DB: db}
+}
+
+func (r ChargeRepository) ListBySubscription(ctx context.Context, userID, subscriptionID string) ([]domain.Charge, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT `+chargeColumns+`
+		FROM charges
+		WHERE user_id = $1 AND subscription_id = $2
+		ORDER BY charge_date DESC, created_at DESC
+	`, userID, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []domain.Charge
+	for rows.Next() {
+		item, err := scanCharge(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, item)
+	}
+	return results, rows.Err()
+}
+
+func (r ChargeRepository) Create(ctx context.Context, charge domain.Charge) (domain.Charge, error) {
+	row := r.DB.QueryRow(ctx, `
+		INSERT INTO charges (user_id, subscription_id, charge_date, amount_minor, currency, status, note)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING `+chargeColumns,
+		charge.UserID, charge.SubscriptionID, charge.ChargeDate, charge.Amount, charge.Currency, charge.Status, charge.Note,
+	)
+	return scanCharge(row)
+}
+
+func (r ChargeRepository) Update(ctx context.Context, charge domain.Charge) (domain.Charge, error) {
+	row := r.DB.QueryRow(ctx, `
+		UPDATE charges
+		SET charge_date = $1, amount_minor = $2, currency = $3, status = $4, note = $5, updated_at = NOW()
+		WHERE id = $6 AND user_id = $7 AND subscription_id = $8
+		RETURNING `+chargeColumns,
+		charge.ChargeDate, charge.Amount, charge.Currency, charge.Status, charge.Note, charge.ID, charge.UserID, charge.SubscriptionID,
+	)
+	updated, err := scanCharge(row)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.Charge{}, usecase.ErrNotFound
+		}
+		return domain.Charge{}, err
+	}
+	return updated, nil
+}
+
+func (r ChargeRepository) CreateExpected(ctx context.Context, charges []domain.Charge) ([]domain.Charge, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var created []domain.Charge
+	for _, charge := range charges {
+		row := tx.QueryRow(ctx, `
+			INSERT INTO charges (user_id, subscription_id, charge_date, amount_minor, currency, status, note)
+			SELECT $1, $2, $3, $4, $5, $6, $7
+			WHERE NOT EXISTS (
+				SELECT 1 FROM charges WHERE subscription_id = $2 AND charge_date = $3
+			)
+			ON CONFLICT (subscription_id, charge_date) WHERE status = 'expected' DO NOTHING
+			RETURNING `+chargeColumns,
+			charge.UserID, charge.SubscriptionID, charge.ChargeDate, charge.Amount, charge.Currency, charge.Status, charge.Note,
+		)
+		item, err := scanCharge(row)
+		if err == pgx.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, item)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func scanCharge(row pgx.Row) (domain.Charge, error) {
+	var item domain.Charge
+	err := row.Scan(
+		&item.ID,
+		&item.UserID,
+		&item.SubscriptionID,
+		&item.ChargeDate,
+		&item.Amount,
+		&item.Currency,
+		&item.Status,
+		&item.Note,
+	)
+	return item, err
+}
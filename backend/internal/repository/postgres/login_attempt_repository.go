@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"subscribe_tracker/backend/internal/domain"
+)
+
+const loginAttemptColumns = `scope, subject, failures, last_failed_at, locked_until`
+
+type LoginAttemptRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewLoginAttemptRepository(db *pgxpool.Pool) LoginAttemptRepository {
+	return LoginAttemptRepository{DB: db}
+}
+
+func (r LoginAttemptRepository) Find(ctx context.Context, scope domain.LoginScope, subject string) (domain.LoginAttempts, error) {
+	row := r.DB.QueryRow(ctx, `
+		SELECT `+loginAttemptColumns+`
+		FROM login_attempts
+		WHERE scope = $1 AND subject = $2
+	`, scope, subject)
+	attempts, err := scanLoginAttempts(row)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.LoginAttempts{Scope: scope, Subject: subject}, nil
+		}
+		return domain.LoginAttempts{}, err
+	}
+	return attempts, nil
+}
+
+// RecordFailure counts the failure in a single statement, so concurrent
+// failures on several replicas are all counted. Rows that are past their
+// window and lockout are pruned on the way.
+func (r LoginAttemptRepository) RecordFailure(ctx context.Context, scope domain.LoginScope, subject string, window time.Duration) (domain.LoginAttempts, error) {
+	if _, err := r.DB.Exec(ctx, `
+		DELETE FROM login_attempts
+		WHERE last_failed_at < NOW() - $1::interval AND (locked_until IS NULL OR locked_until < NOW())
+	`, window); err != nil {
+		return domain.LoginAttempts{}, err
+	}
+
+	row := r.DB.QueryRow(ctx, `
+		INSERT INTO login_attempts (scope, subject, failures, last_failed_at)
+		VALUES ($1, $2, 1, NOW())
+		ON CONFLICT (scope, subject) DO UPDATE
+		SET failures = CASE
+				WHEN login_attempts.last_failed_at < NOW() - $3::interval THEN 1
+				ELSE login_attempts.failures + 1
+			END,
+			last_failed_at = NOW()
+		RETURNING `+loginAttemptColumns,
+		scope, subject, window,
+	)
+	return scanLoginAttempts(row)
+}
+
+func (r LoginAttemptRepository) Lock(ctx context.Context, lockout domain.LoginLockout) error {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE login_attempts
+		SET failures = 0, locked_until = $3
+		WHERE scope = $1 AND subject = $2
+	`, lockout.Scope, lockout.Subject, lockout.LockedUntil); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO login_lockouts (scope, subject, user_id, failures, ip_address, user_agent, locked_until)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`,
+		lockout.Scope, lockout.Subject, lockout.UserID, lockout.Failures,
+		lockout.IPAddress, lockout.UserAgent, lockout.LockedUntil,
+	); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Reset leaves a lockout that started in the meantime in place.
+func (r LoginAttemptRepository) Reset(ctx context.Context, scope domain.LoginScope, subject string) error {
+	_, err := r.DB.Exec(ctx, `
+		DELETE FROM login_attempts
+		WHERE scope = $1 AND subject = $2 AND (locked_until IS NULL OR locked_until < NOW())
+	`, scope, subject)
+	return err
+}
+
+func scanLoginAttempts(row pgx.Row) (domain.LoginAttempts, error) {
+	var item domain.LoginAttempts
+	err := row.Scan(
+		&item.Scope,
+		&item.Subject,
+		&item.Failures,
+		&item.LastFailedAt,
+		&item.LockedUntil,
+	)
+	return item, err
+}
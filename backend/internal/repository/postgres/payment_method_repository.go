@@ -0,0 +1,211 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"subscribe_tracker/backend/internal/domain"
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+const paymentMethodColumns = `id, user_id, bank_name, card_last4, brand, exp_month, exp_year, nickname`
+
+type PaymentMethodRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewPaymentMethodRepository(db *pgxpool.Pool) PaymentMethodRepository {
+	return PaymentMethodRepository{DB: db}
+}
+
+func (r PaymentMethodRepository) ListByUserID(ctx context.Context, userID string) ([]domain.PaymentMethod, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT `+paymentMethodColumns+`
+		FROM payment_methods
+		WHERE user_id = $1
+		ORDER BY bank_name ASC, card_last4 ASC, created_at ASC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []domain.PaymentMethod
+	for rows.Next() {
+		item, err := scanPaymentMethod(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, item)
+	}
+	return results, rows.Err()
+}
+
+func (r PaymentMethodRepository) Create(ctx context.Context, method domain.PaymentMethod) (domain.PaymentMethod, error) {
+	row := r.DB.QueryRow(ctx, `
+		INSERT INTO payment_methods (user_id, bank_name, card_last4, brand, exp_month, exp_year, nickname)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING `+paymentMethodColumns,
+		method.UserID, method.BankName, method.CardLast4, method.Brand,
+		nullableInt(method.ExpMonth), nullableInt(method.ExpYear), method.Nickname,
+	)
+	return scanPaymentMethod(row)
+}
+
+func (r PaymentMethodRepository) Update(ctx context.Context, method domain.PaymentMethod) (domain.PaymentMethod, error) {
+	row := r.DB.QueryRow(ctx, `
+		UPDATE payment_methods
+		SET bank_name = $1, card_last4 = $2, brand = $3, exp_month = $4, exp_year = $5, nickname = $6, updated_at = NOW()
+		WHERE id = $7 AND user_id = $8
+		RETURNING `+paymentMethodColumns,
+		method.BankName, method.CardLast4, method.Brand,
+		nullableInt(method.ExpMonth), nullableInt(method.ExpYear), method.Nickname, method.ID, method.UserID,
+	)
+	updated, err := scanPaymentMethod(row)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.PaymentMethod{}, usecase.ErrNotFound
+		}
+		return domain.PaymentMethod{}, err
+	}
+	return updated, nil
+}
+
+func (r PaymentMethodRepository) Delete(ctx context.Context, userID, id string) error {
+	cmd, err := r.DB.Exec(ctx, `
+		DELETE FROM payment_methods WHERE id = $1 AND user_id = $2
+	`, id, userID)
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23503" {
+			return usecase.ErrInUse
+		}
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return usecase.ErrNotFound
+	}
+	return nil
+}
+
+func (r PaymentMethodRepository) Reassign(ctx context.Context, userID, fromID, toID string) ([]string, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock both methods so neither can be deleted halfway through.
+	rows, err := tx.Query(ctx, `
+		SELECT id FROM payment_methods
+		WHERE id = ANY($1::uuid[]) AND user_id = $2
+		FOR UPDATE
+	`, []string{fromID, toID}, userID)
+	if err != nil {
+		return nil, err
+	}
+	var found int
+	for rows.Next() {
+		found++
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if found != 2 {
+		return nil, usecase.ErrNotFound
+	}
+
+	rows, err = tx.Query(ctx, `
+		UPDATE subscriptions
+		SET payment_method_id = $1, updated_at = NOW()
+		WHERE payment_method_id = $2 AND user_id = $3
+		RETURNING id
+	`, toID, fromID, userID)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// subscriptionPaymentMethodID returns the payment method sub is charged to.
+// A method given by ID must belong to the user; otherwise one matching the
+// bank and card is looked up and created when missing, so clients that only
+// send the free-text fields keep working. q must be a transaction: the
+// lookup holds a lock until it ends, so concurrent saves with the same bank
+// and card share one method.
+func subscriptionPaymentMethodID(ctx context.Context, q querier, sub domain.Subscription) (string, error) {
+	if sub.PaymentMethodID != "" {
+		var exists bool
+		if err := q.QueryRow(ctx, `
+			SELECT EXISTS (SELECT 1 FROM payment_methods WHERE id = $1 AND user_id = $2)
+		`, sub.PaymentMethodID, sub.UserID).Scan(&exists); err != nil {
+			return "", err
+		}
+		if !exists {
+			return "", usecase.ErrInvalidInput
+		}
+		return sub.PaymentMethodID, nil
+	}
+
+	// A user may keep several methods with the same bank and card, e.g. a
+	// reissued card, so no unique constraint covers the pair.
+	if _, err := q.Exec(ctx, `
+		SELECT pg_advisory_xact_lock(hashtextextended($1::text || '/' || $2::text || '/' || $3::text, 0))
+	`, sub.UserID, sub.BankName, sub.CardLast4); err != nil {
+		return "", err
+	}
+
+	var id string
+	err := q.QueryRow(ctx, `
+		SELECT id FROM payment_methods
+		WHERE user_id = $1 AND bank_name = $2 AND card_last4 = $3
+		ORDER BY created_at ASC
+		LIMIT 1
+	`, sub.UserID, sub.BankName, sub.CardLast4).Scan(&id)
+	if err != pgx.ErrNoRows {
+		return id, err
+	}
+	err = q.QueryRow(ctx, `
+		INSERT INTO payment_methods (user_id, bank_name, card_last4)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`, sub.UserID, sub.BankName, sub.CardLast4).Scan(&id)
+	return id, err
+}
+
+func scanPaymentMethod(row pgx.Row) (domain.PaymentMethod, error) {
+	var item domain.PaymentMethod
+	var expMonth, expYear *int
+	err := row.Scan(
+		&item.ID,
+		&item.UserID,
+		&item.BankName,
+		&item.CardLast4,
+		&item.Brand,
+		&expMonth,
+		&expYear,
+		&item.Nickname,
+	)
+	if expMonth != nil && expYear != nil {
+		item.ExpMonth, item.ExpYear = *expMonth, *expYear
+	}
+	return item, err
+}
+
+func nullableInt(value int) *int {
+	if value == 0 {
+		return nil
+	}
+	return &value
+}
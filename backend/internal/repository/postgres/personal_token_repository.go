@@ -0,0 +1,145 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"subscribe_tracker/backend/internal/domain"
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+const personalTokenColumns = `id, user_id, name, token_hash, prefix, scopes, expires_at, last_used_at, created_at`
+
+type PersonalTokenRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewPersonalTokenRepository(db *pgxpool.Pool) PersonalTokenRepository {
+	return PersonalTokenRepository{DB: db}
+}
+
+func (r PersonalTokenRepository) ListByUserID(ctx context.Context, userID string) ([]domain.PersonalToken, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT `+personalTokenColumns+`
+		FROM personal_tokens
+		WHERE user_id = $1
+		ORDER BY created_at ASC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []domain.PersonalToken
+	for rows.Next() {
+		item, err := scanPersonalToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, item)
+	}
+	return results, rows.Err()
+}
+
+func (r PersonalTokenRepository) FindByID(ctx context.Context, userID, id string) (domain.PersonalToken, error) {
+	return findPersonalToken(r.DB.QueryRow(ctx, `
+		SELECT `+personalTokenColumns+`
+		FROM personal_tokens
+		WHERE id = $1 AND user_id = $2
+	`, id, userID))
+}
+
+func (r PersonalTokenRepository) FindByHash(ctx context.Context, tokenHash string) (domain.PersonalToken, error) {
+	return findPersonalToken(r.DB.QueryRow(ctx, `
+		SELECT `+personalTokenColumns+`
+		FROM personal_tokens
+		WHERE token_hash = $1
+	`, tokenHash))
+}
+
+func (r PersonalTokenRepository) Create(ctx context.Context, token domain.PersonalToken) (domain.PersonalToken, error) {
+	created, err := scanPersonalToken(r.DB.QueryRow(ctx, `
+		INSERT INTO personal_tokens (user_id, name, token_hash, prefix, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING `+personalTokenColumns,
+		token.UserID, token.Name, token.TokenHash, token.Prefix, token.Scopes, token.ExpiresAt,
+	))
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
+			return domain.PersonalToken{}, usecase.ErrNameExists
+		}
+		return domain.PersonalToken{}, err
+	}
+	return created, nil
+}
+
+func (r PersonalTokenRepository) Update(ctx context.Context, token domain.PersonalToken) (domain.PersonalToken, error) {
+	updated, err := findPersonalToken(r.DB.QueryRow(ctx, `
+		UPDATE personal_tokens
+		SET name = $1, scopes = $2
+		WHERE id = $3 AND user_id = $4
+		RETURNING `+personalTokenColumns,
+		token.Name, token.Scopes, token.ID, token.UserID,
+	))
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
+			return domain.PersonalToken{}, usecase.ErrNameExists
+		}
+		return domain.PersonalToken{}, err
+	}
+	return updated, nil
+}
+
+func (r PersonalTokenRepository) Delete(ctx context.Context, userID, id string) error {
+	cmd, err := r.DB.Exec(ctx, `
+		DELETE FROM personal_tokens WHERE id = $1 AND user_id = $2
+	`, id, userID)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return usecase.ErrNotFound
+	}
+	return nil
+}
+
+// MarkUsed records the use at most once a minute, so scripts calling the
+// API in a loop do not write on every request.
+func (r PersonalTokenRepository) MarkUsed(ctx context.Context, id string) error {
+	_, err := r.DB.Exec(ctx, `
+		UPDATE personal_tokens
+		SET last_used_at = NOW()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')
+	`, id)
+	return err
+}
+
+func findPersonalToken(row pgx.Row) (domain.PersonalToken, error) {
+	item, err := scanPersonalToken(row)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.PersonalToken{}, usecase.ErrNotFound
+		}
+		return domain.PersonalToken{}, err
+	}
+	return item, nil
+}
+
+func scanPersonalToken(row pgx.Row) (domain.PersonalToken, error) {
+	var item domain.PersonalToken
+	err := row.Scan(
+		&item.ID,
+		&item.UserID,
+		&item.Name,
+		&item.TokenHash,
+		&item.Prefix,
+		&item.Scopes,
+		&item.ExpiresAt,
+		&item.LastUsedAt,
+		&item.CreatedAt,
+	)
+	return item, err
+}
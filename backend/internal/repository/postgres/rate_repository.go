@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"subscribe_tracker/backend/internal/domain"
+)
+
+type RateRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewRateRepository(db *pgxpool.Pool) RateRepository {
+	return RateRepository{DB: db}
+}
+
+func (r RateRepository) Save(ctx context.Context, rates []domain.Rate) error {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for _, rate := range rates {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO rates (base, quote, rate, rate_date, source)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (base, quote, rate_date) DO UPDATE
+			SET rate = EXCLUDED.rate, source = EXCLUDED.source, updated_at = NOW()
+		`, rate.Base, rate.Quote, rate.Rate, rate.Date, rate.Source); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (r RateRepository) Latest(ctx context.Context) ([]domain.Rate, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT DISTINCT ON (base, quote) base, quote, rate::float8, rate_date, source
+		FROM rates
+		ORDER BY base, quote, rate_date DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []domain.Rate
+	for rows.Next() {
+		var item domain.Rate
+		if err := rows.Scan(&item.Base, &item.Quote, &item.Rate, &item.Date, &item.Source); err != nil {
+			return nil, err
+		}
+		results = append(results, item)
+	}
+	return results, rows.Err()
+}
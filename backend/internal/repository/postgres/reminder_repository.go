@@ -0,0 +1,162 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"subscribe_tracker/backend/internal/domain"
+)
+
+const reminderColumns = `r.id, r.user_id, r.subscription_id, s.service_name, r.charge_date, r.remind_at,
+	r.amount_minor, r.currency, r.status, r.attempts, r.last_error, r.sent_at`
+
+type ReminderRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewReminderRepository(db *pgxpool.Pool) ReminderRepository {
+	return ReminderRepository{DB: db}
+}
+
+func (r ReminderRepository) ListByUserID(ctx context.Context, userID string) ([]domain.Reminder, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT `+reminderColumns+`
+		FROM reminders r
+		JOIN subscriptions s ON s.id = r.subscription_id
+		WHERE r.user_id = $1
+		ORDER BY r.charge_date DESC, s.service_name ASC
+		LIMIT 200
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	return collectReminders(rows)
+}
+
+func (r ReminderRepository) Sync(ctx context.Context, userID string, reminders []domain.Reminder) error {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	subscriptionIDs := make([]string, 0, len(reminders))
+	chargeDates := make([]time.Time, 0, len(reminders))
+	for _, reminder := range reminders {
+		subscriptionIDs = append(subscriptionIDs, reminder.SubscriptionID)
+		chargeDates = append(chargeDates, reminder.ChargeDate)
+	}
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM reminders r
+		WHERE r.user_id = $1 AND r.status = 'pending' AND NOT EXISTS (
+			SELECT 1 FROM unnest($2::uuid[], $3::date[]) AS k(subscription_id, charge_date)
+			WHERE k.subscription_id = r.subscription_id AND k.charge_date = r.charge_date
+		)
+	`, userID, subscriptionIDs, chargeDates); err != nil {
+		return err
+	}
+
+	for _, reminder := range reminders {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO reminders (user_id, subscription_id, charge_date, remind_at, amount_minor, currency)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (subscription_id, charge_date) DO UPDATE
+			SET remind_at = EXCLUDED.remind_at, amount_minor = EXCLUDED.amount_minor,
+				currency = EXCLUDED.currency, updated_at = NOW()
+			WHERE reminders.status = 'pending'
+		`, userID, reminder.SubscriptionID, reminder.ChargeDate, reminder.RemindAt, reminder.Amount, reminder.Currency); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r ReminderRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]domain.Reminder, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		WITH due AS (
+			SELECT id FROM reminders
+			WHERE status = 'pending' AND remind_at <= CURRENT_DATE AND charge_date >= CURRENT_DATE
+				AND next_attempt_at <= NOW()
+			ORDER BY remind_at ASC, next_attempt_at ASC
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		), claimed AS (
+			UPDATE reminders r
+			SET attempts = r.attempts + 1, next_attempt_at = NOW() + $2::interval, updated_at = NOW()
+			FROM due
+			WHERE r.id = due.id
+			RETURNING r.*
+		)
+		SELECT `+reminderColumns+`
+		FROM claimed r
+		JOIN subscriptions s ON s.id = r.subscription_id
+	`, limit, lease)
+	if err != nil {
+		return nil, err
+	}
+	reminders, err := collectReminders(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return reminders, nil
+}
+
+func (r ReminderRepository) MarkSent(ctx context.Context, id string) error {
+	_, err := r.DB.Exec(ctx, `
+		UPDATE reminders
+		SET status = 'sent', sent_at = NOW(), last_error = '', updated_at = NOW()
+		WHERE id = $1
+	`, id)
+	return err
+}
+
+func (r ReminderRepository) MarkFailed(ctx context.Context, id string, retryAt *time.Time, reason string) error {
+	_, err := r.DB.Exec(ctx, `
+		UPDATE reminders
+		SET status = CASE WHEN $2::timestamptz IS NULL THEN 'failed' ELSE 'pending' END,
+			next_attempt_at = COALESCE($2, next_attempt_at), last_error = $3, updated_at = NOW()
+		WHERE id = $1
+	`, id, retryAt, reason)
+	return err
+}
+
+func collectReminders(rows pgx.Rows) ([]domain.Reminder, error) {
+	defer rows.Close()
+
+	var results []domain.Reminder
+	for rows.Next() {
+		var item domain.Reminder
+		if err := rows.Scan(
+			&item.ID,
+			&item.UserID,
+			&item.SubscriptionID,
+			&item.ServiceName,
+			&item.ChargeDate,
+			&item.RemindAt,
+			&item.Amount,
+			&item.Currency,
+			&item.Status,
+			&item.Attempts,
+			&item.LastError,
+			&item.SentAt,
+		); err != nil {
+			return nil, err
+		}
+		results = append(results, item)
+	}
+	return results, rows.Err()
+}
@@ -0,0 +1,160 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"subscribe_tracker/backend/internal/domain"
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+const sessionColumns = `id, family_id, user_id, token_hash, user_agent, ip_address, started_at, created_at, expires_at, rotated_at, revoked_at`
+
+type SessionRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewSessionRepository(db *pgxpool.Pool) SessionRepository {
+	return SessionRepository{DB: db}
+}
+
+// Create starts a new family. Expired tokens of the user are pruned on the
+// way, since they can no longer be refreshed or reused.
+func (r SessionRepository) Create(ctx context.Context, session domain.Session) (domain.Session, error) {
+	if _, err := r.DB.Exec(ctx, `
+		DELETE FROM sessions WHERE user_id = $1 AND expires_at < NOW()
+	`, session.UserID); err != nil {
+		return domain.Session{}, err
+	}
+
+	row := r.DB.QueryRow(ctx, `
+		INSERT INTO sessions (family_id, user_id, token_hash, user_agent, ip_address, expires_at)
+		VALUES (gen_random_uuid(), $1, $2, $3, $4, $5)
+		RETURNING `+sessionColumns,
+		session.UserID, session.TokenHash, session.UserAgent, session.IPAddress, session.ExpiresAt,
+	)
+	return scanSession(row)
+}
+
+func (r SessionRepository) FindByToken(ctx context.Context, tokenHash string) (domain.Session, error) {
+	row := r.DB.QueryRow(ctx, `
+		SELECT `+sessionColumns+`
+		FROM sessions
+		WHERE token_hash = $1
+	`, tokenHash)
+	session, err := scanSession(row)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.Session{}, usecase.ErrNotFound
+		}
+		return domain.Session{}, err
+	}
+	return session, nil
+}
+
+func (r SessionRepository) Rotate(ctx context.Context, id string, next domain.Session) (domain.Session, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return domain.Session{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	var familyID, userID string
+	err = tx.QueryRow(ctx, `
+		UPDATE sessions
+		SET rotated_at = NOW()
+		WHERE id = $1 AND rotated_at IS NULL AND revoked_at IS NULL AND expires_at > NOW()
+		RETURNING family_id, user_id
+	`, id).Scan(&familyID, &userID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.Session{}, usecase.ErrNotFound
+		}
+		return domain.Session{}, err
+	}
+
+	row := tx.QueryRow(ctx, `
+		INSERT INTO sessions (family_id, user_id, token_hash, user_agent, ip_address, started_at, expires_at)
+		SELECT family_id, user_id, $2, $3, $4, started_at, $5
+		FROM sessions
+		WHERE id = $1
+		RETURNING `+sessionColumns,
+		id, next.TokenHash, next.UserAgent, next.IPAddress, next.ExpiresAt,
+	)
+	created, err := scanSession(row)
+	if err != nil {
+		return domain.Session{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.Session{}, err
+	}
+	return created, nil
+}
+
+func (r SessionRepository) ListActive(ctx context.Context, userID string) ([]domain.Session, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT `+sessionColumns+`
+		FROM sessions
+		WHERE user_id = $1 AND rotated_at IS NULL AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY created_at DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []domain.Session
+	for rows.Next() {
+		item, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, item)
+	}
+	return results, rows.Err()
+}
+
+func (r SessionRepository) RevokeFamily(ctx context.Context, userID, familyID string) error {
+	cmd, err := r.DB.Exec(ctx, `
+		UPDATE sessions
+		SET revoked_at = NOW()
+		WHERE family_id = $1 AND user_id = $2 AND revoked_at IS NULL
+	`, familyID, userID)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return usecase.ErrNotFound
+	}
+	return nil
+}
+
+func (r SessionRepository) RevokeAll(ctx context.Context, userID string) error {
+	_, err := r.DB.Exec(ctx, `
+		UPDATE sessions
+		SET revoked_at = NOW()
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`, userID)
+	return err
+}
+
+func scanSession(row pgx.Row) (domain.Session, error) {
+	var item domain.Session
+	err := row.Scan(
+		&item.ID,
+		&item.FamilyID,
+		&item.UserID,
+		&item.TokenHash,
+		&item.UserAgent,
+		&item.IPAddress,
+		&item.StartedAt,
+		&item.CreatedAt,
+		&item.ExpiresAt,
+		&item.RotatedAt,
+		&item.RevokedAt,
+	)
+	return item, err
+}
@@ -2,15 +2,32 @@ package postgres
 
 import (
 	"context"
-	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"subscribe_tracker/backend/internal/domain"
 	"subscribe_tracker/backend/internal/usecase"
 )
 
+const subscriptionSelect = `
+	SELECT s.id, s.user_id, s.service_name, s.payment_method_id, pm.bank_name, pm.card_last4, s.billing_unit, s.billing_interval, s.charge_date,
+		s.price_minor, s.currency, s.trial_end_date, s.post_trial_price_minor, s.status, s.status_effective_date,
+		s.reminder_lead_days, c.id, c.name
+	FROM subscriptions s
+	JOIN payment_methods pm ON pm.id = s.payment_method_id
+	LEFT JOIN categories c ON c.id = s.category_id`
+
+const priceColumns = `id, subscription_id, price_minor, currency, effective_from`
+
+// querier is implemented by both *pgxpool.Pool and pgx.Tx.
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
 type SubscriptionRepository struct {
 	DB *pgxpool.Pool
 }
@@ -20,11 +37,9 @@ func NewSubscriptionRepository(db *pgxpool.Pool) SubscriptionRepository {
 }
 
 func (r SubscriptionRepository) ListByUserID(ctx context.Context, userID string) ([]domain.Subscription, error) {
-	rows, err := r.DB.Query(ctx, `
-		SELECT id, user_id, service_name, bank_name, card_last4, billing_cycle, charge_date
-		FROM subscriptions
-		WHERE user_id = $1
-		ORDER BY charge_date ASC
+	rows, err := r.DB.Query(ctx, subscriptionSelect+`
+		WHERE s.user_id = $1
+		ORDER BY s.service_name ASC
 	`, userID)
 	if err != nil {
 		return nil, err
@@ -33,73 +48,209 @@ func (r SubscriptionRepository) ListByUserID(ctx context.Context, userID string)
 
 	var results []domain.Subscription
 	for rows.Next() {
-		var item domain.Subscription
-		var chargeDate time.Time
-		if err := rows.Scan(
-			&item.ID,
-			&item.UserID,
-			&item.ServiceName,
-			&item.BankName,
-			&item.CardLast4,
-			&item.Billing,
-			&chargeDate,
-		); err != nil {
+		item, err := scanSubscription(rows)
+		if err != nil {
 			return nil, err
 		}
-		item.ChargeDate = chargeDate
 		results = append(results, item)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	prices, err := listPrices(ctx, r.DB, `
+		SELECT p.id, p.subscription_id, p.price_minor, p.currency, p.effective_from
+		FROM subscription_prices p
+		JOIN subscriptions s ON s.id = p.subscription_id
+		WHERE s.user_id = $1
+		ORDER BY p.effective_from ASC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := listSubscriptionTags(ctx, r.DB, `
+		SELECT st.subscription_id, t.id, t.user_id, t.name
+		FROM subscription_tags st
+		JOIN tags t ON t.id = st.tag_id
+		WHERE t.user_id = $1
+		ORDER BY t.name ASC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].Prices = prices[results[i].ID]
+		results[i].Tags = tags[results[i].ID]
+	}
 	return results, nil
 }
 
+func (r SubscriptionRepository) FindByID(ctx context.Context, userID, id string) (domain.Subscription, error) {
+	return findSubscription(ctx, r.DB, userID, id)
+}
+
 func (r SubscriptionRepository) Create(ctx context.Context, sub domain.Subscription) (domain.Subscription, error) {
-	var created domain.Subscription
-	var chargeDate time.Time
-	err := r.DB.QueryRow(ctx, `
-		INSERT INTO subscriptions (user_id, service_name, bank_name, card_last4, billing_cycle, charge_date)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id, user_id, service_name, bank_name, card_last4, billing_cycle, charge_date
-	`, sub.UserID, sub.ServiceName, sub.BankName, sub.CardLast4, sub.Billing, sub.ChargeDate).Scan(
-		&created.ID,
-		&created.UserID,
-		&created.ServiceName,
-		&created.BankName,
-		&created.CardLast4,
-		&created.Billing,
-		&chargeDate,
-	)
+	tx, err := r.DB.Begin(ctx)
 	if err != nil {
 		return domain.Subscription{}, err
 	}
-	created.ChargeDate = chargeDate
+	defer tx.Rollback(ctx)
+
+	created, err := createSubscription(ctx, tx, sub)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.Subscription{}, err
+	}
 	return created, nil
 }
 
+func (r SubscriptionRepository) CreateMany(ctx context.Context, subs []domain.Subscription) ([]domain.Subscription, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	results := make([]domain.Subscription, 0, len(subs))
+	for _, sub := range subs {
+		created, err := createSubscription(ctx, tx, sub)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, created)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Update overwrites the subscription, its category and tags, and appends
+// sub.Prices to its price history; an entry on a date that already has one
+// replaces it. When the trial is cleared or moves, the post-trial price entry
+// of the old trial end is removed first.
 func (r SubscriptionRepository) Update(ctx context.Context, sub domain.Subscription) (domain.Subscription, error) {
-	var updated domain.Subscription
-	var chargeDate time.Time
-	err := r.DB.QueryRow(ctx, `
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	categoryID, err := ownedCategoryID(ctx, tx, sub)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+	paymentMethodID, err := subscriptionPaymentMethodID(ctx, tx, sub)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM subscription_prices p
+		USING subscriptions s
+		WHERE s.id = $1 AND s.user_id = $2 AND p.subscription_id = s.id
+			AND p.effective_from = s.trial_end_date AND s.trial_end_date IS DISTINCT FROM $3::date
+	`, sub.ID, sub.UserID, sub.TrialEndDate); err != nil {
+		return domain.Subscription{}, err
+	}
+
+	cmd, err := tx.Exec(ctx, `
 		UPDATE subscriptions
-		SET service_name = $1, bank_name = $2, card_last4 = $3, billing_cycle = $4, charge_date = $5, updated_at = NOW()
-		WHERE id = $6 AND user_id = $7
-		RETURNING id, user_id, service_name, bank_name, card_last4, billing_cycle, charge_date
-	`, sub.ServiceName, sub.BankName, sub.CardLast4, sub.Billing, sub.ChargeDate, sub.ID, sub.UserID).Scan(
-		&updated.ID,
-		&updated.UserID,
-		&updated.ServiceName,
-		&updated.BankName,
-		&updated.CardLast4,
-		&updated.Billing,
-		&chargeDate,
+		SET service_name = $1, payment_method_id = $2, billing_unit = $3, billing_interval = $4,
+			charge_date = $5, price_minor = $6, currency = $7, trial_end_date = $8, post_trial_price_minor = $9,
+			category_id = $10, updated_at = NOW()
+		WHERE id = $11 AND user_id = $12
+	`,
+		sub.ServiceName, paymentMethodID, sub.Billing.Unit, sub.Billing.Interval, sub.ChargeDate,
+		sub.Price, sub.Currency, sub.TrialEndDate, sub.PostTrialPrice, categoryID, sub.ID, sub.UserID,
 	)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+	if cmd.RowsAffected() == 0 {
+		return domain.Subscription{}, usecase.ErrNotFound
+	}
+	if err := insertPrices(ctx, tx, sub.ID, sub.Prices); err != nil {
+		return domain.Subscription{}, err
+	}
+	if err := setSubscriptionTags(ctx, tx, sub.UserID, sub.ID, sub.Tags); err != nil {
+		return domain.Subscription{}, err
+	}
+	updated, err := findSubscription(ctx, tx, sub.UserID, sub.ID)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.Subscription{}, err
+	}
+	return updated, nil
+}
+
+func (r SubscriptionRepository) UpdateStatus(ctx context.Context, sub domain.Subscription, from domain.SubscriptionStatus) (domain.Subscription, error) {
+	var id string
+	err := r.DB.QueryRow(ctx, `
+		WITH updated AS (
+			UPDATE subscriptions
+			SET status = $1, status_effective_date = $2, updated_at = NOW()
+			WHERE id = $3 AND user_id = $4 AND status = $5
+			RETURNING id, status, status_effective_date
+		), history AS (
+			INSERT INTO subscription_status_history (subscription_id, status, effective_date)
+			SELECT id, status, status_effective_date FROM updated
+		)
+		SELECT id FROM updated`,
+		sub.Status, sub.StatusEffectiveDate, sub.ID, sub.UserID, from,
+	).Scan(&id)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return domain.Subscription{}, usecase.ErrNotFound
+			return domain.Subscription{}, usecase.ErrInvalidTransition
 		}
 		return domain.Subscription{}, err
 	}
-	updated.ChargeDate = chargeDate
-	return updated, nil
+	return findSubscription(ctx, r.DB, sub.UserID, id)
+}
+
+func (r SubscriptionRepository) ListStatusHistory(ctx context.Context, userID, id string) ([]domain.StatusChange, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT h.status, h.effective_date
+		FROM subscription_status_history h
+		JOIN subscriptions s ON s.id = h.subscription_id
+		WHERE h.subscription_id = $1 AND s.user_id = $2
+		ORDER BY h.effective_date, h.created_at
+	`, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []domain.StatusChange
+	for rows.Next() {
+		var item domain.StatusChange
+		if err := rows.Scan(&item.Status, &item.EffectiveDate); err != nil {
+			return nil, err
+		}
+		results = append(results, item)
+	}
+	return results, rows.Err()
+}
+
+func (r SubscriptionRepository) UpdateReminderLeadDays(ctx context.Context, userID, id string, days *int) (domain.Subscription, error) {
+	cmd, err := r.DB.Exec(ctx, `
+		UPDATE subscriptions
+		SET reminder_lead_days = $1, updated_at = NOW()
+		WHERE id = $2 AND user_id = $3
+	`, days, id, userID)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+	if cmd.RowsAffected() == 0 {
+		return domain.Subscription{}, usecase.ErrNotFound
+	}
+	return findSubscription(ctx, r.DB, userID, id)
 }
 
 func (r SubscriptionRepository) Delete(ctx context.Context, userID, id string) error {
@@ -114,3 +265,214 @@ func (r SubscriptionRepository) Delete(ctx context.Context, userID, id string) e
 	}
 	return nil
 }
+
+// createSubscription inserts sub with its category, payment method, prices and
+// tags and returns it as stored.
+func createSubscription(ctx context.Context, q querier, sub domain.Subscription) (domain.Subscription, error) {
+	categoryID, err := ownedCategoryID(ctx, q, sub)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+	paymentMethodID, err := subscriptionPaymentMethodID(ctx, q, sub)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+
+	var id string
+	err = q.QueryRow(ctx, `
+		WITH created AS (
+			INSERT INTO subscriptions (
+				user_id, service_name, payment_method_id, billing_unit, billing_interval, charge_date,
+				price_minor, currency, trial_end_date, post_trial_price_minor, status, status_effective_date, category_id
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+			RETURNING id, status, status_effective_date
+		), history AS (
+			INSERT INTO subscription_status_history (subscription_id, status, effective_date)
+			SELECT id, status, status_effective_date FROM created
+		)
+		SELECT id FROM created`,
+		sub.UserID, sub.ServiceName, paymentMethodID, sub.Billing.Unit, sub.Billing.Interval, sub.ChargeDate,
+		sub.Price, sub.Currency, sub.TrialEndDate, sub.PostTrialPrice, sub.Status, sub.StatusEffectiveDate, categoryID,
+	).Scan(&id)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+	if err := insertPrices(ctx, q, id, sub.Prices); err != nil {
+		return domain.Subscription{}, err
+	}
+	if err := setSubscriptionTags(ctx, q, sub.UserID, id, sub.Tags); err != nil {
+		return domain.Subscription{}, err
+	}
+	return findSubscription(ctx, q, sub.UserID, id)
+}
+
+func findSubscription(ctx context.Context, q querier, userID, id string) (domain.Subscription, error) {
+	row := q.QueryRow(ctx, subscriptionSelect+`
+		WHERE s.id = $1 AND s.user_id = $2
+	`, id, userID)
+	item, err := scanSubscription(row)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.Subscription{}, usecase.ErrNotFound
+		}
+		return domain.Subscription{}, err
+	}
+
+	prices, err := listPrices(ctx, q, `
+		SELECT `+priceColumns+`
+		FROM subscription_prices
+		WHERE subscription_id = $1
+		ORDER BY effective_from ASC
+	`, item.ID)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+	item.Prices = prices[item.ID]
+
+	tags, err := listSubscriptionTags(ctx, q, `
+		SELECT st.subscription_id, t.id, t.user_id, t.name
+		FROM subscription_tags st
+		JOIN tags t ON t.id = st.tag_id
+		WHERE st.subscription_id = $1
+		ORDER BY t.name ASC
+	`, item.ID)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+	item.Tags = tags[item.ID]
+	return item, nil
+}
+
+// ownedCategoryID returns the category of sub as a nullable value, failing
+// with ErrInvalidInput when the category belongs to someone else.
+func ownedCategoryID(ctx context.Context, q querier, sub domain.Subscription) (*string, error) {
+	if sub.Category == nil {
+		return nil, nil
+	}
+	var exists bool
+	if err := q.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM categories WHERE id = $1 AND user_id = $2)
+	`, sub.Category.ID, sub.UserID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, usecase.ErrInvalidInput
+	}
+	return &sub.Category.ID, nil
+}
+
+// setSubscriptionTags replaces the tags of a subscription, failing with
+// ErrInvalidInput when any tag does not belong to the user.
+func setSubscriptionTags(ctx context.Context, q querier, userID, subscriptionID string, tags []domain.Tag) error {
+	if _, err := q.Exec(ctx, `DELETE FROM subscription_tags WHERE subscription_id = $1`, subscriptionID); err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		ids = append(ids, tag.ID)
+	}
+	cmd, err := q.Exec(ctx, `
+		INSERT INTO subscription_tags (subscription_id, tag_id)
+		SELECT $1, id FROM tags WHERE id = ANY($2::uuid[]) AND user_id = $3
+	`, subscriptionID, ids, userID)
+	if err != nil {
+		return err
+	}
+	if int(cmd.RowsAffected()) != len(ids) {
+		return usecase.ErrInvalidInput
+	}
+	return nil
+}
+
+// listSubscriptionTags runs a tag query and groups the rows by subscription.
+func listSubscriptionTags(ctx context.Context, q querier, sql string, args ...any) (map[string][]domain.Tag, error) {
+	rows, err := q.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := map[string][]domain.Tag{}
+	for rows.Next() {
+		var subscriptionID string
+		var item domain.Tag
+		if err := rows.Scan(&subscriptionID, &item.ID, &item.UserID, &item.Name); err != nil {
+			return nil, err
+		}
+		results[subscriptionID] = append(results[subscriptionID], item)
+	}
+	return results, rows.Err()
+}
+
+func insertPrices(ctx context.Context, q querier, subscriptionID string, prices []domain.PriceChange) error {
+	for _, price := range prices {
+		if _, err := q.Exec(ctx, `
+			INSERT INTO subscription_prices (subscription_id, price_minor, currency, effective_from)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (subscription_id, effective_from)
+			DO UPDATE SET price_minor = EXCLUDED.price_minor, currency = EXCLUDED.currency, created_at = NOW()
+		`, subscriptionID, price.Price, price.Currency, price.EffectiveFrom); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listPrices runs a price history query and groups the rows by subscription.
+func listPrices(ctx context.Context, q querier, sql string, args ...any) (map[string][]domain.PriceChange, error) {
+	rows, err := q.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := map[string][]domain.PriceChange{}
+	for rows.Next() {
+		var item domain.PriceChange
+		if err := rows.Scan(
+			&item.ID,
+			&item.SubscriptionID,
+			&item.Price,
+			&item.Currency,
+			&item.EffectiveFrom,
+		); err != nil {
+			return nil, err
+		}
+		results[item.SubscriptionID] = append(results[item.SubscriptionID], item)
+	}
+	return results, rows.Err()
+}
+
+func scanSubscription(row pgx.Row) (domain.Subscription, error) {
+	var item domain.Subscription
+	var categoryID, categoryName *string
+	err := row.Scan(
+		&item.ID,
+		&item.UserID,
+		&item.ServiceName,
+		&item.PaymentMethodID,
+		&item.BankName,
+		&item.CardLast4,
+		&item.Billing.Unit,
+		&item.Billing.Interval,
+		&item.ChargeDate,
+		&item.Price,
+		&item.Currency,
+		&item.TrialEndDate,
+		&item.PostTrialPrice,
+		&item.Status,
+		&item.StatusEffectiveDate,
+		&item.ReminderLeadDays,
+		&categoryID,
+		&categoryName,
+	)
+	if err == nil && categoryID != nil {
+		item.Category = &domain.Category{ID: *categoryID, UserID: item.UserID, Name: *categoryName}
+	}
+	return item, err
+}
@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"subscribe_tracker/backend/internal/domain"
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+type TagRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewTagRepository(db *pgxpool.Pool) TagRepository {
+	return TagRepository{DB: db}
+}
+
+func (r TagRepository) ListByUserID(ctx context.Context, userID string) ([]domain.Tag, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT id, user_id, name
+		FROM tags
+		WHERE user_id = $1
+		ORDER BY name ASC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []domain.Tag
+	for rows.Next() {
+		var item domain.Tag
+		if err := rows.Scan(&item.ID, &item.UserID, &item.Name); err != nil {
+			return nil, err
+		}
+		results = append(results, item)
+	}
+	return results, rows.Err()
+}
+
+func (r TagRepository) Create(ctx context.Context, tag domain.Tag) (domain.Tag, error) {
+	var created domain.Tag
+	err := r.DB.QueryRow(ctx, `
+		INSERT INTO tags (user_id, name)
+		VALUES ($1, $2)
+		RETURNING id, user_id, name
+	`, tag.UserID, tag.Name).Scan(&created.ID, &created.UserID, &created.Name)
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
+			return domain.Tag{}, usecase.ErrNameExists
+		}
+		return domain.Tag{}, err
+	}
+	return created, nil
+}
+
+func (r TagRepository) Update(ctx context.Context, tag domain.Tag) (domain.Tag, error) {
+	var updated domain.Tag
+	err := r.DB.QueryRow(ctx, `
+		UPDATE tags
+		SET name = $1
+		WHERE id = $2 AND user_id = $3
+		RETURNING id, user_id, name
+	`, tag.Name, tag.ID, tag.UserID).Scan(&updated.ID, &updated.UserID, &updated.Name)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.Tag{}, usecase.ErrNotFound
+		}
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
+			return domain.Tag{}, usecase.ErrNameExists
+		}
+		return domain.Tag{}, err
+	}
+	return updated, nil
+}
+
+func (r TagRepository) Delete(ctx context.Context, userID, id string) error {
+	cmd, err := r.DB.Exec(ctx, `
+		DELETE FROM tags WHERE id = $1 AND user_id = $2
+	`, id, userID)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return usecase.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+type TelegramRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewTelegramRepository(db *pgxpool.Pool) TelegramRepository {
+	return TelegramRepository{DB: db}
+}
+
+func (r TelegramRepository) CreateLinkCode(ctx context.Context, userID, codeHash string, expiresAt time.Time) error {
+	_, err := r.DB.Exec(ctx, `
+		INSERT INTO telegram_link_codes (code_hash, user_id, expires_at)
+		VALUES ($1, $2, $3)
+	`, codeHash, userID, expiresAt)
+	return err
+}
+
+func (r TelegramRepository) ConsumeLinkCode(ctx context.Context, codeHash string, chatID int64) (string, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	var userID string
+	err = tx.QueryRow(ctx, `
+		UPDATE telegram_link_codes
+		SET used_at = NOW()
+		WHERE code_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id
+	`, codeHash).Scan(&userID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return "", usecase.ErrNotFound
+		}
+		return "", err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM telegram_chats WHERE chat_id = $1 OR user_id = $2`, chatID, userID); err != nil {
+		return "", err
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO telegram_chats (user_id, chat_id) VALUES ($1, $2)
+	`, userID, chatID); err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", err
+	}
+	return userID, nil
+}
+
+func (r TelegramRepository) FindUserIDByChat(ctx context.Context, chatID int64) (string, error) {
+	var userID string
+	err := r.DB.QueryRow(ctx, `
+		SELECT user_id FROM telegram_chats WHERE chat_id = $1
+	`, chatID).Scan(&userID)
+	if err == pgx.ErrNoRows {
+		return "", usecase.ErrNotFound
+	}
+	return userID, err
+}
+
+func (r TelegramRepository) FindChatByUserID(ctx context.Context, userID string) (int64, error) {
+	var chatID int64
+	err := r.DB.QueryRow(ctx, `
+		SELECT chat_id FROM telegram_chats WHERE user_id = $1
+	`, userID).Scan(&chatID)
+	if err == pgx.ErrNoRows {
+		return 0, usecase.ErrNotFound
+	}
+	return chatID, err
+}
+
+func (r TelegramRepository) Unlink(ctx context.Context, userID string) error {
+	cmd, err := r.DB.Exec(ctx, `
+		DELETE FROM telegram_chats WHERE user_id = $1
+	`, userID)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return usecase.ErrNotFound
+	}
+	return nil
+}
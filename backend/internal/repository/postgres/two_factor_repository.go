@@ -0,0 +1,151 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"subscribe_tracker/backend/internal/domain"
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+type TwoFactorRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewTwoFactorRepository(db *pgxpool.Pool) TwoFactorRepository {
+	return TwoFactorRepository{DB: db}
+}
+
+func (r TwoFactorRepository) Find(ctx context.Context, userID string) (domain.TwoFactor, error) {
+	var item domain.TwoFactor
+	err := r.DB.QueryRow(ctx, `
+		SELECT t.user_id, t.secret, t.confirmed_at, t.last_step,
+			(SELECT COUNT(*) FROM recovery_codes c WHERE c.user_id = t.user_id AND c.used_at IS NULL)
+		FROM totp_credentials t
+		WHERE t.user_id = $1
+	`, userID).Scan(&item.UserID, &item.Secret, &item.ConfirmedAt, &item.LastStep, &item.RecoveryCodesLeft)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.TwoFactor{}, usecase.ErrNotFound
+		}
+		return domain.TwoFactor{}, err
+	}
+	return item, nil
+}
+
+func (r TwoFactorRepository) SetPending(ctx context.Context, userID, secret string) error {
+	cmd, err := r.DB.Exec(ctx, `
+		INSERT INTO totp_credentials (user_id, secret)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret, last_step = 0, created_at = NOW()
+		WHERE totp_credentials.confirmed_at IS NULL
+	`, userID, secret)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return usecase.ErrTwoFactorEnabled
+	}
+	return nil
+}
+
+func (r TwoFactorRepository) Confirm(ctx context.Context, userID string, step int64, codeHashes []string) error {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	cmd, err := tx.Exec(ctx, `
+		UPDATE totp_credentials
+		SET confirmed_at = NOW(), last_step = $2
+		WHERE user_id = $1 AND confirmed_at IS NULL AND last_step < $2
+	`, userID, step)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return usecase.ErrNotFound
+	}
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r TwoFactorRepository) UseStep(ctx context.Context, userID string, step int64) error {
+	cmd, err := r.DB.Exec(ctx, `
+		UPDATE totp_credentials
+		SET last_step = $2
+		WHERE user_id = $1 AND confirmed_at IS NOT NULL AND last_step < $2
+	`, userID, step)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return usecase.ErrNotFound
+	}
+	return nil
+}
+
+func (r TwoFactorRepository) UseRecoveryCode(ctx context.Context, userID, codeHash string) error {
+	cmd, err := r.DB.Exec(ctx, `
+		UPDATE recovery_codes
+		SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`, userID, codeHash)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return usecase.ErrNotFound
+	}
+	return nil
+}
+
+func (r TwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r TwoFactorRepository) Delete(ctx context.Context, userID string) error {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	cmd, err := tx.Exec(ctx, `DELETE FROM totp_credentials WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return usecase.ErrNotFound
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func replaceRecoveryCodes(ctx context.Context, q querier, userID string, codeHashes []string) error {
+	if _, err := q.Exec(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	_, err := q.Exec(ctx, `
+		INSERT INTO recovery_codes (user_id, code_hash)
+		SELECT $1, unnest($2::text[])
+	`, userID, codeHashes)
+	return err
+}
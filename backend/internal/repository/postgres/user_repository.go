@@ -11,6 +11,8 @@ import (
 	"subscribe_tracker/backend/internal/usecase"
 )
 
+const userColumns = `id, name, email, password_hash, reminder_lead_days, locale, base_currency, email_verified_at`
+
 type UserRepository struct {
 	DB *pgxpool.Pool
 }
@@ -20,12 +22,12 @@ func NewUserRepository(db *pgxpool.Pool) UserRepository {
 }
 
 func (r UserRepository) Create(ctx context.Context, name, email, passwordHash string) (domain.User, error) {
-	var user domain.User
-	err := r.DB.QueryRow(ctx, `
+	user, err := scanUser(r.DB.QueryRow(ctx, `
 		INSERT INTO users (name, email, password_hash)
 		VALUES ($1, $2, $3)
-		RETURNING id, name, email, password_hash
-	`, name, email, passwordHash).Scan(&user.ID, &user.Name, &user.Email, &user.PasswordHash)
+		RETURNING `+userColumns,
+		name, email, passwordHash,
+	))
 	if err != nil {
 		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
 			return domain.User{}, usecase.ErrEmailExists
@@ -36,12 +38,11 @@ func (r UserRepository) Create(ctx context.Context, name, email, passwordHash st
 }
 
 func (r UserRepository) FindByEmail(ctx context.Context, email string) (domain.User, error) {
-	var user domain.User
-	err := r.DB.QueryRow(ctx, `
-		SELECT id, name, email, password_hash
+	user, err := scanUser(r.DB.QueryRow(ctx, `
+		SELECT `+userColumns+`
 		FROM users
 		WHERE email = $1
-	`, email).Scan(&user.ID, &user.Name, &user.Email, &user.PasswordHash)
+	`, email))
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return domain.User{}, usecase.ErrUnauthorized
@@ -50,3 +51,112 @@ func (r UserRepository) FindByEmail(ctx context.Context, email string) (domain.U
 	}
 	return user, nil
 }
+
+func (r UserRepository) FindByID(ctx context.Context, id string) (domain.User, error) {
+	user, err := scanUser(r.DB.QueryRow(ctx, `
+		SELECT `+userColumns+`
+		FROM users
+		WHERE id = $1
+	`, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.User{}, usecase.ErrNotFound
+		}
+		return domain.User{}, err
+	}
+	return user, nil
+}
+
+func (r UserRepository) List(ctx context.Context) ([]domain.User, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT `+userColumns+`
+		FROM users
+		ORDER BY created_at ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []domain.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, user)
+	}
+	return results, rows.Err()
+}
+
+func (r UserRepository) UpdateNotificationSettings(ctx context.Context, id string, leadDays int, locale string) (domain.User, error) {
+	user, err := scanUser(r.DB.QueryRow(ctx, `
+		UPDATE users
+		SET reminder_lead_days = $1, locale = $2
+		WHERE id = $3
+		RETURNING `+userColumns,
+		leadDays, locale, id,
+	))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.User{}, usecase.ErrNotFound
+		}
+		return domain.User{}, err
+	}
+	return user, nil
+}
+
+func (r UserRepository) UpdateBaseCurrency(ctx context.Context, id, currency string) (domain.User, error) {
+	user, err := scanUser(r.DB.QueryRow(ctx, `
+		UPDATE users
+		SET base_currency = $1
+		WHERE id = $2
+		RETURNING `+userColumns,
+		currency, id,
+	))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.User{}, usecase.ErrNotFound
+		}
+		return domain.User{}, err
+	}
+	return user, nil
+}
+
+// MarkEmailVerified records that the user confirmed their email, keeping the
+// time of the first confirmation.
+func (r UserRepository) MarkEmailVerified(ctx context.Context, id string) (domain.User, error) {
+	user, err := scanUser(r.DB.QueryRow(ctx, `
+		UPDATE users
+		SET email_verified_at = COALESCE(email_verified_at, NOW())
+		WHERE id = $1
+		RETURNING `+userColumns,
+		id,
+	))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.User{}, usecase.ErrNotFound
+		}
+		return domain.User{}, err
+	}
+	return user, nil
+}
+
+func (r UserRepository) UpdatePassword(ctx context.Context, id, passwordHash string) error {
+	cmd, err := r.DB.Exec(ctx, `
+		UPDATE users SET password_hash = $1 WHERE id = $2
+	`, passwordHash, id)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return usecase.ErrNotFound
+	}
+	return nil
+}
+
+func scanUser(row pgx.Row) (domain.User, error) {
+	var user domain.User
+	err := row.Scan(&user.ID, &user.Name, &user.Email, &user.PasswordHash, &user.ReminderLeadDays, &user.Locale, &user.BaseCurrency, &user.EmailVerifiedAt)
+	return user, err
+}
@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+type UserTokenRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewUserTokenRepository(db *pgxpool.Pool) UserTokenRepository {
+	return UserTokenRepository{DB: db}
+}
+
+func (r UserTokenRepository) Create(ctx context.Context, userID, purpose, tokenHash string, expiresAt time.Time) error {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM user_tokens WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL
+	`, userID, purpose); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO user_tokens (token_hash, user_id, purpose, expires_at)
+		VALUES ($1, $2, $3, $4)
+	`, tokenHash, userID, purpose, expiresAt); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r UserTokenRepository) Consume(ctx context.Context, purpose, tokenHash string) (string, error) {
+	var userID string
+	err := r.DB.QueryRow(ctx, `
+		UPDATE user_tokens
+		SET used_at = NOW()
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id
+	`, tokenHash, purpose).Scan(&userID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return "", usecase.ErrNotFound
+		}
+		return "", err
+	}
+	return userID, nil
+}
+
+func (r UserTokenRepository) Find(ctx context.Context, purpose, tokenHash string) (string, error) {
+	var userID string
+	err := r.DB.QueryRow(ctx, `
+		SELECT user_id
+		FROM user_tokens
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()
+	`, tokenHash, purpose).Scan(&userID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return "", usecase.ErrNotFound
+		}
+		return "", err
+	}
+	return userID, nil
+}
+
+func (r UserTokenRepository) RecordFailure(ctx context.Context, purpose, tokenHash string, maxAttempts int) error {
+	_, err := r.DB.Exec(ctx, `
+		UPDATE user_tokens
+		SET attempts = attempts + 1,
+			used_at = CASE WHEN attempts + 1 >= $3 THEN NOW() ELSE used_at END
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL
+	`, tokenHash, purpose, maxAttempts)
+	return err
+}
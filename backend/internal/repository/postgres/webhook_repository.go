@@ -0,0 +1,233 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"subscribe_tracker/backend/internal/domain"
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+const webhookColumns = `id, user_id, url, secret, events, active`
+
+const deliveryColumns = `d.id, d.webhook_id, d.event, d.payload, d.status, d.attempts, COALESCE(d.response_status, 0),
+	d.last_error, d.created_at, d.delivered_at, w.url, w.secret`
+
+type WebhookRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewWebhookRepository(db *pgxpool.Pool) WebhookRepository {
+	return WebhookRepository{DB: db}
+}
+
+func (r WebhookRepository) ListByUserID(ctx context.Context, userID string) ([]domain.Webhook, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT `+webhookColumns+`
+		FROM webhooks
+		WHERE user_id = $1
+		ORDER BY created_at ASC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []domain.Webhook
+	for rows.Next() {
+		item, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, item)
+	}
+	return results, rows.Err()
+}
+
+func (r WebhookRepository) Create(ctx context.Context, webhook domain.Webhook) (domain.Webhook, error) {
+	return scanWebhook(r.DB.QueryRow(ctx, `
+		INSERT INTO webhooks (user_id, url, secret, events, active)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+webhookColumns,
+		webhook.UserID, webhook.URL, webhook.Secret, webhook.Events, webhook.Active,
+	))
+}
+
+// Update keeps the stored secret when webhook.Secret is empty.
+func (r WebhookRepository) Update(ctx context.Context, webhook domain.Webhook) (domain.Webhook, error) {
+	updated, err := scanWebhook(r.DB.QueryRow(ctx, `
+		UPDATE webhooks
+		SET url = $1, secret = COALESCE(NULLIF($2, ''), secret), events = $3, active = $4, updated_at = NOW()
+		WHERE id = $5 AND user_id = $6
+		RETURNING `+webhookColumns,
+		webhook.URL, webhook.Secret, webhook.Events, webhook.Active, webhook.ID, webhook.UserID,
+	))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.Webhook{}, usecase.ErrNotFound
+		}
+		return domain.Webhook{}, err
+	}
+	return updated, nil
+}
+
+func (r WebhookRepository) Delete(ctx context.Context, userID, id string) error {
+	cmd, err := r.DB.Exec(ctx, `
+		DELETE FROM webhooks WHERE id = $1 AND user_id = $2
+	`, id, userID)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return usecase.ErrNotFound
+	}
+	return nil
+}
+
+func (r WebhookRepository) Enqueue(ctx context.Context, userID, event string, payload []byte) error {
+	_, err := r.DB.Exec(ctx, `
+		INSERT INTO webhook_deliveries (webhook_id, event, payload)
+		SELECT id, $2, $3
+		FROM webhooks
+		WHERE user_id = $1 AND active AND $2 = ANY(events)
+	`, userID, event, payload)
+	return err
+}
+
+func (r WebhookRepository) ListDeliveries(ctx context.Context, userID, webhookID string) ([]domain.WebhookDelivery, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT `+deliveryColumns+`
+		FROM webhook_deliveries d
+		JOIN webhooks w ON w.id = d.webhook_id
+		WHERE w.id = $1 AND w.user_id = $2
+		ORDER BY d.created_at DESC
+		LIMIT 100
+	`, webhookID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return collectDeliveries(rows)
+}
+
+func (r WebhookRepository) Redeliver(ctx context.Context, userID, webhookID, deliveryID string) (domain.WebhookDelivery, error) {
+	rows, err := r.DB.Query(ctx, `
+		WITH copied AS (
+			INSERT INTO webhook_deliveries (webhook_id, event, payload)
+			SELECT d.webhook_id, d.event, d.payload
+			FROM webhook_deliveries d
+			JOIN webhooks w ON w.id = d.webhook_id
+			WHERE d.id = $1 AND w.id = $2 AND w.user_id = $3
+			RETURNING *
+		)
+		SELECT `+deliveryColumns+`
+		FROM copied d
+		JOIN webhooks w ON w.id = d.webhook_id
+	`, deliveryID, webhookID, userID)
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	deliveries, err := collectDeliveries(rows)
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	if len(deliveries) == 0 {
+		return domain.WebhookDelivery{}, usecase.ErrNotFound
+	}
+	return deliveries[0], nil
+}
+
+func (r WebhookRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]domain.WebhookDelivery, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		WITH due AS (
+			SELECT id FROM webhook_deliveries d
+			WHERE status = 'pending' AND next_attempt_at <= NOW()
+				AND EXISTS (SELECT 1 FROM webhooks w WHERE w.id = d.webhook_id AND w.active)
+			ORDER BY next_attempt_at ASC
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		), claimed AS (
+			UPDATE webhook_deliveries d
+			SET attempts = d.attempts + 1, next_attempt_at = NOW() + $2::interval, updated_at = NOW()
+			FROM due
+			WHERE d.id = due.id
+			RETURNING d.*
+		)
+		SELECT `+deliveryColumns+`
+		FROM claimed d
+		JOIN webhooks w ON w.id = d.webhook_id
+	`, limit, lease)
+	if err != nil {
+		return nil, err
+	}
+	deliveries, err := collectDeliveries(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (r WebhookRepository) MarkDelivered(ctx context.Context, id string, responseStatus int) error {
+	_, err := r.DB.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET status = 'delivered', response_status = $2, last_error = '', delivered_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+	`, id, responseStatus)
+	return err
+}
+
+func (r WebhookRepository) MarkFailed(ctx context.Context, id string, responseStatus int, retryAt *time.Time, reason string) error {
+	_, err := r.DB.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET status = CASE WHEN $3::timestamptz IS NULL THEN 'failed' ELSE 'pending' END,
+			response_status = NULLIF($2, 0), next_attempt_at = COALESCE($3, next_attempt_at),
+			last_error = $4, updated_at = NOW()
+		WHERE id = $1
+	`, id, responseStatus, retryAt, reason)
+	return err
+}
+
+func scanWebhook(row pgx.Row) (domain.Webhook, error) {
+	var item domain.Webhook
+	err := row.Scan(&item.ID, &item.UserID, &item.URL, &item.Secret, &item.Events, &item.Active)
+	return item, err
+}
+
+func collectDeliveries(rows pgx.Rows) ([]domain.WebhookDelivery, error) {
+	defer rows.Close()
+
+	var results []domain.WebhookDelivery
+	for rows.Next() {
+		var item domain.WebhookDelivery
+		if err := rows.Scan(
+			&item.ID,
+			&item.WebhookID,
+			&item.Event,
+			&item.Payload,
+			&item.Status,
+			&item.Attempts,
+			&item.ResponseStatus,
+			&item.LastError,
+			&item.CreatedAt,
+			&item.DeliveredAt,
+			&item.URL,
+			&item.Secret,
+		); err != nil {
+			return nil, err
+		}
+		results = append(results, item)
+	}
+	return results, rows.Err()
+}
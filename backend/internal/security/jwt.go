@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+
+	"subscribe_tracker/backend/internal/usecase"
 )
 
 type JWTManager struct {
@@ -19,10 +21,13 @@ func NewJWTManager(secret []byte, ttl time.Duration) JWTManager {
 	}
 }
 
-func (m JWTManager) Sign(userID, email string) (string, error) {
+// Sign issues an access token; sessionID is the session it was issued
+// for.
+func (m JWTManager) Sign(userID, email, sessionID string) (string, error) {
 	claims := jwt.MapClaims{
 		"sub":   userID,
 		"email": email,
+		"sid":   sessionID,
 		"exp":   time.Now().Add(m.TTL).Unix(),
 	}
 
@@ -30,7 +35,7 @@ func (m JWTManager) Sign(userID, email string) (string, error) {
 	return token.SignedString(m.Secret)
 }
 
-func (m JWTManager) Parse(tokenValue string) (string, error) {
+func (m JWTManager) Parse(tokenValue string) (usecase.AccessToken, error) {
 	claims := jwt.MapClaims{}
 	token, err := jwt.ParseWithClaims(tokenValue, claims, func(token *jwt.Token) (interface{}, error) {
 		if token.Method.Alg() != jwt.SigningMethodHS256.Alg() {
@@ -39,12 +44,13 @@ func (m JWTManager) Parse(tokenValue string) (string, error) {
 		return m.Secret, nil
 	})
 	if err != nil || !token.Valid {
-		return "", errors.New("invalid token")
+		return usecase.AccessToken{}, errors.New("invalid token")
 	}
 
 	userID, ok := claims["sub"].(string)
 	if !ok || userID == "" {
-		return "", errors.New("invalid token")
+		return usecase.AccessToken{}, errors.New("invalid token")
 	}
-	return userID, nil
+	sessionID, _ := claims["sid"].(string)
+	return usecase.AccessToken{UserID: userID, SessionID: sessionID}, nil
 }
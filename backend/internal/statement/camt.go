@@ -0,0 +1,159 @@
+package statement
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"time"
+
+	"subscribe_tracker/backend/internal/domain"
+)
+
+// camtDocument holds the parts of a CAMT.053 BankToCustomerStatement used
+// here. Element names are matched without namespace, so all message versions
+// are read the same way.
+type camtDocument struct {
+	Statements []struct {
+		Account struct {
+			ID struct {
+				IBAN  string `xml:"IBAN"`
+				Other string `xml:"Othr>Id"`
+			} `xml:"Id"`
+			Currency string `xml:"Ccy"`
+			Servicer struct {
+				Name  string `xml:"FinInstnId>Nm"`
+				BIC   string `xml:"FinInstnId>BIC"`
+				BICFI string `xml:"FinInstnId>BICFI"`
+			} `xml:"Svcr"`
+		} `xml:"Acct"`
+		Entries []camtEntry `xml:"Ntry"`
+	} `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtEntry struct {
+	Amount struct {
+		Value    string `xml:",chardata"`
+		Currency string `xml:"Ccy,attr"`
+	} `xml:"Amt"`
+	CreditDebit string `xml:"CdtDbtInd"`
+	// Status is BOOK for booked entries; PDNG and INFO ones may still
+	// change. Versions before 8 hold the code directly, later ones in Cd.
+	Status struct {
+		Value string `xml:",chardata"`
+		Code  string `xml:"Cd"`
+	} `xml:"Sts"`
+	Reversal    bool `xml:"RvslInd"`
+	BookingDate struct {
+		Date     string `xml:"Dt"`
+		DateTime string `xml:"DtTm"`
+	} `xml:"BookgDt"`
+	ValueDate struct {
+		Date     string `xml:"Dt"`
+		DateTime string `xml:"DtTm"`
+	} `xml:"ValDt"`
+	Info    string `xml:"AddtlNtryInf"`
+	Details []struct {
+		Creditor   string   `xml:"RltdPties>Cdtr>Nm"`
+		CreditorV8 string   `xml:"RltdPties>Cdtr>Pty>Nm"`
+		Remittance []string `xml:"RmtInf>Ustrd"`
+		Info       string   `xml:"AddtlTxInf"`
+	} `xml:"NtryDtls>TxDtls"`
+}
+
+// ParseCAMT053 reads an ISO 20022 BankToCustomerStatement. Only booked
+// entries are returned; a statement file may hold several accounts, whose
+// entries are all returned together.
+func ParseCAMT053(data []byte) (domain.Statement, error) {
+	var doc camtDocument
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) { return input, nil }
+	if err := decoder.Decode(&doc); err != nil {
+		return domain.Statement{}, err
+	}
+	if len(doc.Statements) == 0 {
+		return domain.Statement{}, ErrUnsupported
+	}
+
+	var stmt domain.Statement
+	for _, item := range doc.Statements {
+		account := item.Account
+		if stmt.AccountID == "" {
+			stmt.AccountID = firstNonEmpty(account.ID.IBAN, account.ID.Other)
+			stmt.BankName = firstNonEmpty(account.Servicer.Name, account.Servicer.BICFI, account.Servicer.BIC)
+			stmt.Currency = strings.ToUpper(account.Currency)
+		}
+
+		for _, entry := range item.Entries {
+			if status := firstNonEmpty(entry.Status.Code, entry.Status.Value); status != "" && !strings.EqualFold(status, "BOOK") {
+				continue
+			}
+			date := camtDate(entry.BookingDate.Date, entry.BookingDate.DateTime)
+			if date.IsZero() {
+				date = camtDate(entry.ValueDate.Date, entry.ValueDate.DateTime)
+			}
+			amount := normalizeAmount(entry.Amount.Value)
+			debit := strings.EqualFold(entry.CreditDebit, "DBIT")
+			if debit != entry.Reversal {
+				amount = "-" + amount
+			}
+
+			// The creditor names the merchant best; remittance lines often
+			// carry a reference that changes with every charge.
+			var names, parts []string
+			for _, details := range entry.Details {
+				names = append(names, firstNonEmpty(details.Creditor, details.CreditorV8))
+				parts = append(parts, details.Remittance...)
+				parts = append(parts, details.Info)
+			}
+			parts = append(parts, entry.Info)
+			description := joinNonEmpty(names)
+			if description == "" {
+				description = joinNonEmpty(parts)
+			}
+
+			txn := domain.StatementTransaction{
+				Date:        date,
+				Amount:      amount,
+				Currency:    strings.ToUpper(firstNonEmpty(entry.Amount.Currency, account.Currency)),
+				Description: description,
+			}
+			if !validTransaction(txn) {
+				return domain.Statement{}, ErrMalformed
+			}
+			stmt.Transactions = append(stmt.Transactions, txn)
+		}
+	}
+	return stmt, nil
+}
+
+func camtDate(date, dateTime string) time.Time {
+	value := firstNonEmpty(date, dateTime)
+	if len(value) < 10 {
+		return time.Time{}
+	}
+	parsed, err := time.Parse("2006-01-02", value[:10])
+	if err != nil {
+		return time.Time{}
+	}
+	return parsed
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func joinNonEmpty(values []string) string {
+	var parts []string
+	for _, value := range values {
+		if value = strings.Join(strings.Fields(value), " "); value != "" {
+			parts = append(parts, value)
+		}
+	}
+	return strings.Join(parts, " ")
+}
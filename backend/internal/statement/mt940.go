@@ -0,0 +1,144 @@
+package statement
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"subscribe_tracker/backend/internal/domain"
+)
+
+var (
+	mt940Tag     = regexp.MustCompile(`^:(\d{2}[A-Z]?):`)
+	mt940Line    = regexp.MustCompile(`^(\d{6})(\d{4})?(R?[CD])[A-Z]?(\d+,\d*)`)
+	mt940Balance = regexp.MustCompile(`^[CD]\d{6}([A-Z]{3})`)
+	// mt940Subfield matches the ?NN codes of structured :86: fields.
+	mt940Subfield = regexp.MustCompile(`\?(\d{2})`)
+)
+
+// ParseMT940 reads a SWIFT MT940 customer statement. Bank name is not part of
+// the format; the BIC from the account field is used when present.
+func ParseMT940(data []byte) (domain.Statement, error) {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+
+	var (
+		stmt   domain.Statement
+		fields []mt940Field
+	)
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " \r")
+		if match := mt940Tag.FindStringSubmatch(line); match != nil {
+			fields = append(fields, mt940Field{tag: match[1], value: line[len(match[0]):]})
+			continue
+		}
+		if len(fields) > 0 && line != "" && line != "-" && !strings.HasPrefix(line, "{") {
+			last := &fields[len(fields)-1]
+			last.value += "\n" + line
+		}
+	}
+
+	var (
+		txn    *domain.StatementTransaction
+		closed bool
+	)
+	flush := func() {
+		if txn != nil {
+			stmt.Transactions = append(stmt.Transactions, *txn)
+			txn = nil
+		}
+	}
+	for _, field := range fields {
+		switch field.tag {
+		case "25":
+			if stmt.AccountID == "" {
+				account := strings.TrimSpace(field.value)
+				if bic, number, ok := strings.Cut(account, "/"); ok {
+					stmt.BankName = bic
+					account = number
+				}
+				stmt.AccountID = account
+			}
+		case "60F", "60M":
+			if match := mt940Balance.FindStringSubmatch(field.value); match != nil && stmt.Currency == "" {
+				stmt.Currency = match[1]
+			}
+		case "62F", "62M":
+			closed = true
+		case "61":
+			flush()
+			match := mt940Line.FindStringSubmatch(field.value)
+			if match == nil {
+				return domain.Statement{}, ErrMalformed
+			}
+			date, err := time.Parse("060102", match[1])
+			if err != nil {
+				return domain.Statement{}, ErrMalformed
+			}
+			amount := normalizeAmount(match[4])
+			// Debits and reversals of credits take money out.
+			if match[3] == "D" || match[3] == "RC" {
+				amount = "-" + amount
+			}
+			txn = &domain.StatementTransaction{
+				Date:     date,
+				Amount:   amount,
+				Currency: stmt.Currency,
+			}
+			// The supplementary details after "//" hold a short reference.
+			if _, details, ok := strings.Cut(field.value, "\n"); ok {
+				txn.Description = strings.Join(strings.Fields(details), " ")
+			}
+		case "86":
+			if txn != nil {
+				txn.Description = joinNonEmpty([]string{mt940Description(field.value), txn.Description})
+			}
+			flush()
+		}
+	}
+	flush()
+
+	if len(stmt.Transactions) == 0 && stmt.AccountID == "" {
+		return domain.Statement{}, ErrUnsupported
+	}
+	// Every statement ends with its closing balance; without one the file
+	// was cut short.
+	if !closed {
+		return domain.Statement{}, ErrMalformed
+	}
+	return stmt, nil
+}
+
+type mt940Field struct {
+	tag   string
+	value string
+}
+
+// mt940Description returns the text of an :86: field. Of structured fields
+// only the counterparty name ("?32"/"?33") is kept, or the remittance lines
+// ("?20" to "?29", "?60" to "?63") when there is no name.
+func mt940Description(value string) string {
+	value = strings.ReplaceAll(value, "\n", "")
+	indexes := mt940Subfield.FindAllStringSubmatchIndex(value, -1)
+	if len(indexes) == 0 {
+		return strings.Join(strings.Fields(value), " ")
+	}
+
+	var name, remittance []string
+	for i, index := range indexes {
+		end := len(value)
+		if i+1 < len(indexes) {
+			end = indexes[i+1][0]
+		}
+		code, content := value[index[2]:index[3]], value[index[1]:end]
+		switch {
+		case code == "32" || code == "33":
+			name = append(name, content)
+		case code >= "20" && code <= "29", code >= "60" && code <= "63":
+			remittance = append(remittance, content)
+		}
+	}
+	if len(name) > 0 {
+		return joinNonEmpty([]string{strings.Join(name, "")})
+	}
+	return joinNonEmpty([]string{strings.Join(remittance, "")})
+}
@@ -0,0 +1,150 @@
+package statement
+
+import (
+	"bytes"
+	"strings"
+	"time"
+
+	"subscribe_tracker/backend/internal/domain"
+)
+
+// ParseOFX reads OFX 1.x (SGML, closing tags optional) and 2.x (XML)
+// statements; QFX files are OFX with extra Intuit tags. Bank and credit card
+// statements are both supported; for the latter the account number gives the
+// card's last four digits.
+func ParseOFX(data []byte) (domain.Statement, error) {
+	start := bytes.Index(bytes.ToUpper(data), []byte("<OFX>"))
+	if start < 0 {
+		return domain.Statement{}, ErrUnsupported
+	}
+
+	var (
+		stmt       domain.Statement
+		txn        *domain.StatementTransaction
+		name, memo string
+		creditCard bool
+		foreign    bool
+	)
+	flush := func() error {
+		if txn == nil {
+			return nil
+		}
+		txn.Description = strings.TrimSpace(name + " " + memo)
+		if !validTransaction(*txn) {
+			return ErrMalformed
+		}
+		stmt.Transactions = append(stmt.Transactions, *txn)
+		txn, name, memo, foreign = nil, "", "", false
+		return nil
+	}
+
+	rest := string(data[start:])
+	closed := false
+	for {
+		open := strings.IndexByte(rest, '<')
+		if open < 0 {
+			break
+		}
+		end := strings.IndexByte(rest[open:], '>')
+		if end < 0 {
+			return domain.Statement{}, ErrMalformed
+		}
+		tag := strings.ToUpper(strings.TrimSpace(rest[open+1 : open+end]))
+		if strings.ContainsRune(tag, '<') {
+			return domain.Statement{}, ErrMalformed
+		}
+		rest = rest[open+end+1:]
+		value := rest
+		if next := strings.IndexByte(rest, '<'); next >= 0 {
+			value = rest[:next]
+		}
+		value = unescapeOFX(strings.TrimSpace(value))
+
+		switch tag {
+		case "STMTTRN":
+			if err := flush(); err != nil {
+				return domain.Statement{}, err
+			}
+			txn = &domain.StatementTransaction{}
+		case "/STMTTRN", "/BANKTRANLIST":
+			if err := flush(); err != nil {
+				return domain.Statement{}, err
+			}
+		case "/OFX":
+			if err := flush(); err != nil {
+				return domain.Statement{}, err
+			}
+			closed = true
+		case "CCACCTFROM":
+			creditCard = true
+		case "ORG":
+			if stmt.BankName == "" {
+				stmt.BankName = value
+			}
+		case "ACCTID":
+			if stmt.AccountID == "" {
+				stmt.AccountID = value
+			}
+		case "CURDEF":
+			stmt.Currency = strings.ToUpper(value)
+		}
+		if txn == nil {
+			continue
+		}
+		switch tag {
+		case "DTPOSTED":
+			txn.Date = parseOFXDate(value)
+		case "TRNAMT":
+			txn.Amount = normalizeAmount(value)
+		case "NAME", "PAYEE":
+			if name == "" {
+				name = value
+			}
+		case "MEMO":
+			memo = value
+		case "CURRENCY":
+			// Amounts are in this currency; with ORIGCURRENCY they were
+			// converted to the statement currency and CURSYM is ignored.
+			foreign = true
+		case "ORIGCURRENCY":
+			foreign = false
+		case "CURSYM":
+			if foreign {
+				txn.Currency = strings.ToUpper(value)
+			}
+		}
+	}
+	// Closing tags of elements are optional in OFX 1.x, but not that of the
+	// document.
+	if !closed {
+		return domain.Statement{}, ErrMalformed
+	}
+
+	for i := range stmt.Transactions {
+		if stmt.Transactions[i].Currency == "" {
+			stmt.Transactions[i].Currency = stmt.Currency
+		}
+	}
+	if creditCard {
+		stmt.CardLast4 = lastDigits(stmt.AccountID, 4)
+	}
+	return stmt, nil
+}
+
+// parseOFXDate reads the date part of YYYYMMDD[HHMMSS[.XXX]][TZ].
+func parseOFXDate(value string) time.Time {
+	if len(value) < 8 {
+		return time.Time{}
+	}
+	date, err := time.Parse("20060102", value[:8])
+	if err != nil {
+		return time.Time{}
+	}
+	return date
+}
+
+var ofxEntities = strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">", "&quot;", `"`, "&apos;", "'", "&nbsp;", " ")
+
+func unescapeOFX(value string) string {
+	return ofxEntities.Replace(value)
+}
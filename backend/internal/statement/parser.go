@@ -0,0 +1,89 @@
+// Package statement reads bank statements in OFX/QFX, ISO 20022 CAMT.053 and
+// SWIFT MT940 formats.
+package statement
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"strings"
+
+	"subscribe_tracker/backend/internal/domain"
+)
+
+// ErrUnsupported is returned for files in none of the supported formats.
+var ErrUnsupported = errors.New("unsupported statement format")
+
+// ErrMalformed is returned for statements that are cut short or have a
+// transaction without a valid date or amount.
+var ErrMalformed = errors.New("malformed statement")
+
+// amountPattern matches amounts as normalizeAmount returns them.
+var amountPattern = regexp.MustCompile(`^-?\d+(\.\d+)?$`)
+
+// Parser detects the format of a statement and parses it.
+type Parser struct{}
+
+func (Parser) Parse(data []byte) (domain.Statement, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	head := data
+	if len(head) > 4096 {
+		head = head[:4096]
+	}
+	switch {
+	case bytes.Contains(head, []byte("OFXHEADER")) || bytes.Contains(bytes.ToUpper(head), []byte("<OFX>")):
+		return ParseOFX(data)
+	case bytes.Contains(head, []byte("camt.053")) || bytes.Contains(data, []byte("BkToCstmrStmt")):
+		return ParseCAMT053(data)
+	case bytes.Contains(data, []byte(":20:")) && bytes.Contains(data, []byte(":61:")):
+		return ParseMT940(data)
+	default:
+		return domain.Statement{}, ErrUnsupported
+	}
+}
+
+// normalizeAmount turns "1.234,56", "1 234.56" or "+12" into a plain decimal
+// with a dot separator, keeping a leading minus.
+func normalizeAmount(value string) string {
+	value = strings.TrimSpace(value)
+	sign := ""
+	if strings.HasPrefix(value, "-") {
+		sign = "-"
+	}
+	value = strings.TrimLeft(value, "+-")
+	value = strings.NewReplacer(" ", "", " ", "", "'", "").Replace(value)
+
+	// The last separator is the decimal one when both are present.
+	if i := strings.LastIndexAny(value, ".,"); i >= 0 {
+		whole := strings.NewReplacer(".", "", ",", "").Replace(value[:i])
+		fraction := value[i+1:]
+		value = whole
+		if fraction != "" {
+			value += "." + fraction
+		}
+	}
+	return sign + value
+}
+
+// validTransaction reports whether txn has a date and a well-formed amount.
+func validTransaction(txn domain.StatementTransaction) bool {
+	return !txn.Date.IsZero() && amountPattern.MatchString(txn.Amount)
+}
+
+func lastDigits(value string, n int) string {
+	var digits []byte
+	for i := len(value) - 1; i >= 0 && len(digits) < n; i-- {
+		c := value[i]
+		if c < '0' || c > '9' {
+			if len(digits) > 0 {
+				break
+			}
+			continue
+		}
+		digits = append([]byte{c}, digits...)
+	}
+	if len(digits) < n {
+		return ""
+	}
+	return string(digits)
+}
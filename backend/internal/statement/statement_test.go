@@ -0,0 +1,210 @@
+package statement
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"subscribe_tracker/backend/internal/domain"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func assertStatement(t *testing.T, got, want domain.Statement) {
+	t.Helper()
+	if got.BankName != want.BankName || got.AccountID != want.AccountID || got.CardLast4 != want.CardLast4 || got.Currency != want.Currency {
+		t.Errorf("statement = %q %q %q %q, want %q %q %q %q",
+			got.BankName, got.AccountID, got.CardLast4, got.Currency,
+			want.BankName, want.AccountID, want.CardLast4, want.Currency)
+	}
+	if len(got.Transactions) != len(want.Transactions) {
+		t.Fatalf("got %d transactions %+v, want %d", len(got.Transactions), got.Transactions, len(want.Transactions))
+	}
+	for i, txn := range got.Transactions {
+		expected := want.Transactions[i]
+		if !txn.Date.Equal(expected.Date) || txn.Amount != expected.Amount || txn.Currency != expected.Currency || txn.Description != expected.Description {
+			t.Errorf("transaction %d = %s %q %q %q, want %s %q %q %q", i,
+				txn.Date.Format("2006-01-02"), txn.Amount, txn.Currency, txn.Description,
+				expected.Date.Format("2006-01-02"), expected.Amount, expected.Currency, expected.Description)
+		}
+	}
+}
+
+func TestParseOFXCreditCard(t *testing.T) {
+	stmt, err := ParseOFX(readFixture(t, "card.ofx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertStatement(t, stmt, domain.Statement{
+		BankName:  "Example Bank",
+		AccountID: "4111111111114242",
+		CardLast4: "4242",
+		Currency:  "USD",
+		Transactions: []domain.StatementTransaction{
+			{Date: date(2026, 1, 5), Amount: "-15.49", Currency: "USD", Description: "NETFLIX.COM Streaming"},
+			{Date: date(2026, 1, 10), Amount: "1234.50", Currency: "USD", Description: "Refund & Co"},
+			// Charged in euros; the decimal comma is read as such.
+			{Date: date(2026, 2, 5), Amount: "-13.99", Currency: "EUR", Description: "SPOTIFY"},
+		},
+	})
+}
+
+func TestParseOFXBankXML(t *testing.T) {
+	stmt, err := ParseOFX(readFixture(t, "bank.ofx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertStatement(t, stmt, domain.Statement{
+		AccountID: "0532013000",
+		Currency:  "EUR",
+		Transactions: []domain.StatementTransaction{
+			// Converted from dollars, so the amount is in the statement
+			// currency.
+			{Date: date(2026, 3, 1), Amount: "-9.99", Currency: "EUR", Description: "Spotify AB"},
+		},
+	})
+}
+
+func TestParseCAMT053(t *testing.T) {
+	stmt, err := ParseCAMT053(readFixture(t, "statement.camt053.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertStatement(t, stmt, domain.Statement{
+		BankName:  "COBADEFFXXX",
+		AccountID: "DE89370400440532013000",
+		Currency:  "EUR",
+		Transactions: []domain.StatementTransaction{
+			{Date: date(2026, 1, 3), Amount: "-9.99", Currency: "EUR", Description: "Spotify AB"},
+			// A reversed credit takes the money back out.
+			{Date: date(2026, 1, 10), Amount: "-9.99", Currency: "EUR", Description: "Storno Spotify"},
+			{Date: date(2026, 1, 15), Amount: "20.00", Currency: "USD", Description: "Salary January"},
+		},
+	})
+}
+
+func TestParseMT940(t *testing.T) {
+	stmt, err := ParseMT940(readFixture(t, "statement.mt940"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertStatement(t, stmt, domain.Statement{
+		BankName:  "COBADEFFXXX",
+		AccountID: "532013000",
+		Currency:  "EUR",
+		Transactions: []domain.StatementTransaction{
+			{Date: date(2026, 1, 5), Amount: "-15.49", Currency: "EUR", Description: "NETFLIX INTERNATIONAL B.V."},
+			{Date: date(2026, 1, 10), Amount: "100", Currency: "EUR", Description: "Refund from shop"},
+			// A reversed credit without an :86: field.
+			{Date: date(2026, 2, 5), Amount: "-5", Currency: "EUR", Description: "CARD 4242"},
+		},
+	})
+}
+
+func TestParserDetectsFormat(t *testing.T) {
+	for _, name := range []string{"card.ofx", "bank.ofx", "statement.camt053.xml", "statement.mt940"} {
+		stmt, err := Parser{}.Parse(readFixture(t, name))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if len(stmt.Transactions) == 0 {
+			t.Errorf("%s: no transactions", name)
+		}
+	}
+	if _, err := (Parser{}).Parse([]byte("date,amount\n2026-01-01,-9.99\n")); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("csv: err = %v, want ErrUnsupported", err)
+	}
+}
+
+// TestTruncated cuts every fixture short at every byte before its closing
+// tag or balance and expects an error, not a panic or a partial statement.
+func TestTruncated(t *testing.T) {
+	tests := []struct {
+		name  string
+		end   string
+		parse func([]byte) (domain.Statement, error)
+	}{
+		{name: "card.ofx", end: "</OFX>", parse: ParseOFX},
+		{name: "bank.ofx", end: "</OFX>", parse: ParseOFX},
+		{name: "statement.camt053.xml", end: "</Document>", parse: ParseCAMT053},
+		{name: "statement.mt940", end: ":62F:", parse: ParseMT940},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := readFixture(t, tt.name)
+			end := bytes.Index(data, []byte(tt.end))
+			for i := 0; i < end; i++ {
+				if _, err := tt.parse(data[:i]); err == nil {
+					t.Fatalf("cut at %d: no error", i)
+				}
+			}
+		})
+	}
+}
+
+func TestMalformed(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture string
+		old     string
+		new     string
+		parse   func([]byte) (domain.Statement, error)
+	}{
+		{name: "ofx amount", fixture: "card.ofx", old: "<TRNAMT>-15.49", new: "<TRNAMT>abc", parse: ParseOFX},
+		{name: "ofx date", fixture: "card.ofx", old: "<DTPOSTED>20260110", new: "<DTPOSTED>20261310", parse: ParseOFX},
+		{name: "ofx missing amount", fixture: "bank.ofx", old: "<TRNAMT>-9.99</TRNAMT>", new: "", parse: ParseOFX},
+		{name: "ofx unclosed tag", fixture: "card.ofx", old: "<NAME>SPOTIFY", new: "<NAME SPOTIFY", parse: ParseOFX},
+		{name: "camt amount", fixture: "statement.camt053.xml", old: `<Amt Ccy="USD">20.00`, new: `<Amt Ccy="USD">twenty`, parse: ParseCAMT053},
+		{name: "camt date", fixture: "statement.camt053.xml", old: "<Dt>2026-01-15</Dt>", new: "<Dt>15.01.2026</Dt>", parse: ParseCAMT053},
+		{name: "camt missing date", fixture: "statement.camt053.xml", old: "<ValDt><Dt>2026-01-15</Dt></ValDt>", new: "", parse: ParseCAMT053},
+		{name: "camt mismatched tag", fixture: "statement.camt053.xml", old: "</Ntry>", new: "</Entry>", parse: ParseCAMT053},
+		{name: "mt940 amount", fixture: "statement.mt940", old: "DR15,49", new: "DR15.49", parse: ParseMT940},
+		{name: "mt940 date", fixture: "statement.mt940", old: ":61:260110", new: ":61:261310", parse: ParseMT940},
+		{name: "mt940 debit credit mark", fixture: "statement.mt940", old: "260110C100", new: "260110X100", parse: ParseMT940},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := string(readFixture(t, tt.fixture))
+			if !strings.Contains(data, tt.old) {
+				t.Fatalf("%s does not contain %q", tt.fixture, tt.old)
+			}
+			if _, err := tt.parse([]byte(strings.Replace(data, tt.old, tt.new, 1))); err == nil {
+				t.Error("no error")
+			}
+		})
+	}
+}
+
+func TestNormalizeAmount(t *testing.T) {
+	tests := map[string]string{
+		"12.99":     "12.99",
+		"12,99":     "12.99",
+		"-1.234,56": "-1234.56",
+		"+1,234.56": "1234.56",
+		"1 234.56":  "1234.56",
+		"1'234.50":  "1234.50",
+		"100,":      "100",
+		"42":        "42",
+	}
+	for value, want := range tests {
+		if got := normalizeAmount(value); got != want {
+			t.Errorf("normalizeAmount(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"subscribe_tracker/backend/internal/domain"
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+const (
+	pollTimeout    = 30 * time.Second
+	retryDelay     = 5 * time.Second
+	upcomingWindow = 30
+)
+
+// Bot answers commands sent to the bot. It long-polls for updates, so only
+// one instance per bot token may run at a time.
+type Bot struct {
+	Client        Client
+	Links         usecase.TelegramUsecase
+	Subscriptions usecase.SubscriptionUsecase
+	Analytics     usecase.AnalyticsUsecase
+}
+
+func NewBot(client Client, links usecase.TelegramUsecase, subscriptions usecase.SubscriptionUsecase, analytics usecase.AnalyticsUsecase) Bot {
+	return Bot{
+		Client:        client,
+		Links:         links,
+		Subscriptions: subscriptions,
+		Analytics:     analytics,
+	}
+}
+
+// Run polls for updates until ctx is cancelled.
+func (b Bot) Run(ctx context.Context) {
+	var offset int64
+	for ctx.Err() == nil {
+		updates, err := b.Client.GetUpdates(ctx, offset, pollTimeout)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("telegram: %v", err)
+				select {
+				case <-ctx.Done():
+				case <-time.After(retryDelay):
+				}
+			}
+			continue
+		}
+		for _, update := range updates {
+			offset = update.UpdateID + 1
+			if update.Message == nil || update.Message.Text == "" {
+				continue
+			}
+			reply := b.Handle(ctx, *update.Message)
+			if err := b.Client.SendMessage(ctx, update.Message.Chat.ID, reply); err != nil && ctx.Err() == nil {
+				log.Printf("telegram: %v", err)
+			}
+		}
+	}
+}
+
+// Handle returns the reply to a message.
+func (b Bot) Handle(ctx context.Context, message Message) string {
+	locale := usecase.DefaultLocale
+	if message.From != nil {
+		if value, ok := usecase.NormalizeLocale(message.From.LanguageCode); ok {
+			locale = value
+		}
+	}
+
+	command, argument := parseCommand(message.Text)
+	if command == "/start" || command == "/link" {
+		if argument == "" {
+			return text(locale, "help")
+		}
+		user, err := b.Links.LinkChat(ctx, message.Chat.ID, argument)
+		if err != nil {
+			if errors.Is(err, usecase.ErrNotFound) || errors.Is(err, usecase.ErrInvalidInput) {
+				return text(locale, "bad_code")
+			}
+			log.Printf("telegram: link chat: %v", err)
+			return text(locale, "failed")
+		}
+		return text(user.Locale, "linked", user.Email)
+	}
+	if command == "/help" {
+		return text(locale, "help")
+	}
+
+	user, err := b.Links.ChatUser(ctx, message.Chat.ID)
+	if err != nil {
+		if errors.Is(err, usecase.ErrNotFound) {
+			return text(locale, "not_linked")
+		}
+		log.Printf("telegram: chat user: %v", err)
+		return text(locale, "failed")
+	}
+
+	var reply string
+	switch command {
+	case "/list":
+		reply, err = b.list(ctx, user)
+	case "/upcoming":
+		reply, err = b.upcoming(ctx, user)
+	case "/total":
+		reply, err = b.total(ctx, user)
+	default:
+		return text(user.Locale, "unknown")
+	}
+	if err != nil {
+		log.Printf("telegram: %s: %v", command, err)
+		return text(user.Locale, "failed")
+	}
+	return reply
+}
+
+func (b Bot) list(ctx context.Context, user domain.User) (string, error) {
+	subs, err := b.Subscriptions.List(ctx, user.ID, usecase.SubscriptionFilter{Status: domain.StatusActive})
+	if err != nil {
+		return "", err
+	}
+	if len(subs) == 0 {
+		return text(user.Locale, "list_empty"), nil
+	}
+
+	lines := []string{text(user.Locale, "list")}
+	for _, sub := range subs {
+		if sub.NextChargeDate.IsZero() {
+			lines = append(lines, text(user.Locale, "list_none",
+				sub.ServiceName, usecase.FormatAmount(sub.Price, sub.Currency), sub.Currency))
+			continue
+		}
+		lines = append(lines, text(user.Locale, "list_item",
+			sub.ServiceName,
+			usecase.FormatAmount(sub.NextChargeAmount, sub.NextChargeCurrency),
+			sub.NextChargeCurrency,
+			sub.NextChargeDate.Format(text(user.Locale, "date")),
+		))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func (b Bot) upcoming(ctx context.Context, user domain.User) (string, error) {
+	report, err := b.Analytics.Spend(ctx, user.ID)
+	if err != nil {
+		return "", err
+	}
+
+	until := report.From.AddDate(0, 0, upcomingWindow)
+	lines := []string{text(user.Locale, "upcoming")}
+	for _, month := range report.Forecast {
+		for _, charge := range month.Charges {
+			if charge.Date.After(until) {
+				continue
+			}
+			lines = append(lines, text(user.Locale, "charge_item",
+				charge.Date.Format(text(user.Locale, "date")),
+				charge.Subscription.ServiceName,
+				usecase.FormatAmount(charge.Amount, charge.Currency),
+				charge.Currency,
+			))
+		}
+	}
+	if len(lines) == 1 {
+		return text(user.Locale, "upcoming_empty"), nil
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func (b Bot) total(ctx context.Context, user domain.User) (string, error) {
+	report, err := b.Analytics.Spend(ctx, user.ID)
+	if err != nil {
+		return "", err
+	}
+	if len(report.ByCurrency) == 0 {
+		return text(user.Locale, "total_empty"), nil
+	}
+
+	lines := []string{text(user.Locale, "total")}
+	for _, total := range report.ByCurrency {
+		lines = append(lines, text(user.Locale, "total_item",
+			total.Currency,
+			usecase.FormatAmount(total.Monthly, total.Currency),
+			usecase.FormatAmount(total.Yearly, total.Currency),
+		))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// parseCommand splits "/cmd@bot argument" into "/cmd" and "argument".
+func parseCommand(message string) (string, string) {
+	fields := strings.Fields(message)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return "", ""
+	}
+	command := strings.ToLower(fields[0])
+	if i := strings.Index(command, "@"); i > 0 {
+		command = command[:i]
+	}
+	return command, strings.Join(fields[1:], " ")
+}
@@ -0,0 +1,192 @@
+package telegram
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"subscribe_tracker/backend/internal/domain"
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+// fakeLinks links chats to users; codes maps a link code to its user.
+type fakeLinks struct {
+	usecase.TelegramRepository
+	codes map[string]string
+	chats map[int64]string
+}
+
+func (f *fakeLinks) ConsumeLinkCode(_ context.Context, codeHash string, chatID int64) (string, error) {
+	for code, userID := range f.codes {
+		sum := sha256.Sum256([]byte(code))
+		if hex.EncodeToString(sum[:]) == codeHash {
+			delete(f.codes, code)
+			f.chats[chatID] = userID
+			return userID, nil
+		}
+	}
+	return "", usecase.ErrNotFound
+}
+
+func (f *fakeLinks) FindUserIDByChat(_ context.Context, chatID int64) (string, error) {
+	if userID, ok := f.chats[chatID]; ok {
+		return userID, nil
+	}
+	return "", usecase.ErrNotFound
+}
+
+type fakeUsers struct {
+	usecase.UserRepository
+	users map[string]domain.User
+}
+
+func (f fakeUsers) FindByID(_ context.Context, id string) (domain.User, error) {
+	if user, ok := f.users[id]; ok {
+		return user, nil
+	}
+	return domain.User{}, usecase.ErrNotFound
+}
+
+type fakeSubscriptions struct {
+	usecase.SubscriptionRepository
+	subs map[string][]domain.Subscription
+}
+
+func (f fakeSubscriptions) ListByUserID(_ context.Context, userID string) ([]domain.Subscription, error) {
+	return f.subs[userID], nil
+}
+
+type fakeRates struct {
+	usecase.RateRepository
+}
+
+func (fakeRates) Latest(context.Context) ([]domain.Rate, error) {
+	return nil, nil
+}
+
+// testBot returns a bot whose chat 1 is linked to a Russian-speaking user
+// with one monthly subscription charged in five days and chat 2 to an
+// English-speaking user without subscriptions. The code "ABCD2345" links a
+// chat to the first user.
+func testBot(t *testing.T, client Client) (Bot, time.Time) {
+	t.Helper()
+	now := time.Now().UTC()
+	next := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 5)
+
+	users := fakeUsers{users: map[string]domain.User{
+		"ru-user": {ID: "ru-user", Email: "anna@example.com", Locale: "ru", BaseCurrency: "RUB"},
+		"en-user": {ID: "en-user", Email: "bob@example.com", Locale: "en", BaseCurrency: "RUB"},
+	}}
+	links := &fakeLinks{
+		codes: map[string]string{"ABCD2345": "ru-user"},
+		chats: map[int64]string{1: "ru-user", 2: "en-user"},
+	}
+	subs := fakeSubscriptions{subs: map[string][]domain.Subscription{
+		"ru-user": {{
+			ID:          "sub-1",
+			UserID:      "ru-user",
+			ServiceName: "Кинопоиск",
+			Billing:     domain.BillingCycle{Unit: domain.BillingMonth, Interval: 1},
+			ChargeDate:  next.AddDate(0, -1, 0),
+			Price:       29900,
+			Currency:    "RUB",
+			Status:      domain.StatusActive,
+		}},
+	}}
+
+	bot := NewBot(
+		client,
+		usecase.NewTelegramUsecase(links, users),
+		usecase.NewSubscriptionUsecase(subs, nil, users, false),
+		usecase.NewAnalyticsUsecase(subs, users, fakeRates{}),
+	)
+	return bot, next
+}
+
+func TestBotHandle(t *testing.T) {
+	bot, next := testBot(t, Client{})
+	date := next.Format("02.01.2006")
+
+	tests := []struct {
+		name    string
+		chatID  int64
+		lang    string
+		text    string
+		want    string
+		wantAll []string
+	}{
+		{name: "start without code", chatID: 3, text: "/start", want: text("ru", "help")},
+		{name: "start with code", chatID: 3, text: "/start abcd2345", want: text("ru", "linked", "anna@example.com")},
+		{name: "start with used code", chatID: 4, lang: "en", text: "/start ABCD2345", want: text("en", "bad_code")},
+		{name: "unlinked chat", chatID: 4, lang: "en", text: "/list", want: text("en", "not_linked")},
+		{name: "list", chatID: 1, text: "/list@tracker_bot", wantAll: []string{
+			text("ru", "list"),
+			text("ru", "list_item", "Кинопоиск", "299.00", "RUB", date),
+		}},
+		{name: "list empty", chatID: 2, text: "/list", want: text("en", "list_empty")},
+		{name: "upcoming", chatID: 1, text: "/upcoming", wantAll: []string{
+			text("ru", "upcoming"),
+			text("ru", "charge_item", date, "Кинопоиск", "299.00", "RUB"),
+		}},
+		{name: "upcoming empty", chatID: 2, text: "/upcoming", want: text("en", "upcoming_empty")},
+		{name: "total", chatID: 1, text: "/total", wantAll: []string{
+			text("ru", "total"),
+			text("ru", "total_item", "RUB", "299.00", "3588.00"),
+		}},
+		{name: "total empty", chatID: 2, text: "/total", want: text("en", "total_empty")},
+		{name: "unknown command", chatID: 2, text: "/foo", want: text("en", "unknown")},
+	}
+	// The cases run in order: the code is used up by the second one.
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := Message{Chat: Chat{ID: tt.chatID}, From: &User{LanguageCode: tt.lang}, Text: tt.text}
+			got := bot.Handle(context.Background(), message)
+			if tt.want != "" && got != tt.want {
+				t.Errorf("reply = %q, want %q", got, tt.want)
+			}
+			if tt.wantAll != nil && got != strings.Join(tt.wantAll, "\n") {
+				t.Errorf("reply = %q, want %q", got, strings.Join(tt.wantAll, "\n"))
+			}
+		})
+	}
+}
+
+func TestBotRun(t *testing.T) {
+	api := newFakeBotAPI(t, "123:secret")
+	api.updates = [][]Update{{
+		{UpdateID: 10, Message: &Message{Chat: Chat{ID: 2}, Text: "/total"}},
+		{UpdateID: 11},
+		{UpdateID: 12, Message: &Message{Chat: Chat{ID: 5}, From: &User{LanguageCode: "en"}, Text: "/help"}},
+	}}
+	bot, _ := testBot(t, api.client())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		bot.Run(ctx)
+	}()
+	for len(api.sent()) < 2 && ctx.Err() == nil {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	want := []sentMessage{
+		{ChatID: 2, Text: text("en", "total_empty")},
+		{ChatID: 5, Text: text("en", "help")},
+	}
+	sent := api.sent()
+	if len(sent) != len(want) || sent[0] != want[0] || sent[1] != want[1] {
+		t.Errorf("sent = %+v, want %+v", sent, want)
+	}
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if len(api.offsets) < 2 || api.offsets[0] != 0 || api.offsets[1] != 13 {
+		t.Errorf("offsets = %v, want to continue after update 12", api.offsets)
+	}
+}
@@ -0,0 +1,110 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+// DefaultBaseURL is the public Bot API.
+const DefaultBaseURL = "https://api.telegram.org"
+
+// Client calls the Bot API at BaseURL, which may point at a local fake
+// server in tests.
+type Client struct {
+	BaseURL string
+	Token   string
+	HTTP    usecase.HTTPClient
+}
+
+func NewClient(baseURL, token string, httpClient usecase.HTTPClient) Client {
+	return Client{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Token:   token,
+		HTTP:    httpClient,
+	}
+}
+
+type Update struct {
+	UpdateID int64    `json:"update_id"`
+	Message  *Message `json:"message"`
+}
+
+type Message struct {
+	MessageID int64  `json:"message_id"`
+	Chat      Chat   `json:"chat"`
+	From      *User  `json:"from"`
+	Text      string `json:"text"`
+}
+
+type Chat struct {
+	ID int64 `json:"id"`
+}
+
+type User struct {
+	ID           int64  `json:"id"`
+	LanguageCode string `json:"language_code"`
+}
+
+type apiResponse struct {
+	OK          bool            `json:"ok"`
+	Result      json.RawMessage `json:"result"`
+	Description string          `json:"description"`
+}
+
+// SendMessage sends plain text to a chat.
+func (c Client) SendMessage(ctx context.Context, chatID int64, text string) error {
+	return c.call(ctx, "sendMessage", map[string]any{
+		"chat_id": chatID,
+		"text":    text,
+	}, nil)
+}
+
+// GetUpdates long-polls for updates after offset for up to timeout; the
+// HTTP client timeout has to be longer than that.
+func (c Client) GetUpdates(ctx context.Context, offset int64, timeout time.Duration) ([]Update, error) {
+	var updates []Update
+	err := c.call(ctx, "getUpdates", map[string]any{
+		"offset":          offset,
+		"timeout":         int(timeout.Seconds()),
+		"allowed_updates": []string{"message"},
+	}, &updates)
+	return updates, err
+}
+
+func (c Client) call(ctx context.Context, method string, params, result any) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+"/bot"+c.Token+"/"+method, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		// The error includes the URL and with it the bot token.
+		return fmt.Errorf("telegram: %s: request failed", method)
+	}
+	defer resp.Body.Close()
+
+	var payload apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return fmt.Errorf("telegram: %s: %s", method, resp.Status)
+	}
+	if !payload.OK {
+		return fmt.Errorf("telegram: %s: %s", method, payload.Description)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(payload.Result, result)
+}
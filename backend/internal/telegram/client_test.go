@@ -0,0 +1,141 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeBotAPI is a local Bot API server. It answers getUpdates with the
+// queued batches, one per call, and records every sendMessage.
+type fakeBotAPI struct {
+	server *httptest.Server
+	token  string
+
+	mu       sync.Mutex
+	updates  [][]Update
+	offsets  []int64
+	messages []sentMessage
+	fail     string
+}
+
+type sentMessage struct {
+	ChatID int64  `json:"chat_id"`
+	Text   string `json:"text"`
+}
+
+func newFakeBotAPI(t *testing.T, token string) *fakeBotAPI {
+	t.Helper()
+	api := &fakeBotAPI{token: token}
+	api.server = httptest.NewServer(http.HandlerFunc(api.handle))
+	t.Cleanup(api.server.Close)
+	return api
+}
+
+func (a *fakeBotAPI) client() Client {
+	return NewClient(a.server.URL+"/", a.token, a.server.Client())
+}
+
+func (a *fakeBotAPI) handle(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	method, ok := strings.CutPrefix(r.URL.Path, "/bot"+a.token+"/")
+	if !ok || r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]any{"ok": false, "description": "Not Found"})
+		return
+	}
+	if a.fail != "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]any{"ok": false, "description": a.fail})
+		return
+	}
+
+	var result any = true
+	switch method {
+	case "getUpdates":
+		var params struct {
+			Offset  int64 `json:"offset"`
+			Timeout int   `json:"timeout"`
+		}
+		json.NewDecoder(r.Body).Decode(&params)
+		a.offsets = append(a.offsets, params.Offset)
+		batch := []Update{}
+		if len(a.updates) > 0 {
+			batch, a.updates = a.updates[0], a.updates[1:]
+		}
+		result = batch
+	case "sendMessage":
+		var message sentMessage
+		json.NewDecoder(r.Body).Decode(&message)
+		a.messages = append(a.messages, message)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]any{"ok": false, "description": "Not Found: method not found"})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": result})
+}
+
+func (a *fakeBotAPI) sent() []sentMessage {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]sentMessage(nil), a.messages...)
+}
+
+func TestClientGetUpdates(t *testing.T) {
+	api := newFakeBotAPI(t, "123:secret")
+	api.updates = [][]Update{{
+		{UpdateID: 7, Message: &Message{MessageID: 1, Chat: Chat{ID: 42}, From: &User{ID: 5, LanguageCode: "en"}, Text: "/list"}},
+	}}
+
+	updates, err := api.client().GetUpdates(context.Background(), 7, 30*time.Second)
+	if err != nil {
+		t.Fatalf("get updates: %v", err)
+	}
+	if len(updates) != 1 || updates[0].UpdateID != 7 || updates[0].Message == nil {
+		t.Fatalf("updates = %+v", updates)
+	}
+	message := updates[0].Message
+	if message.Chat.ID != 42 || message.Text != "/list" || message.From.LanguageCode != "en" {
+		t.Errorf("message = %+v", message)
+	}
+	if len(api.offsets) != 1 || api.offsets[0] != 7 {
+		t.Errorf("offsets = %v, want [7]", api.offsets)
+	}
+}
+
+func TestClientSendMessage(t *testing.T) {
+	api := newFakeBotAPI(t, "123:secret")
+
+	if err := api.client().SendMessage(context.Background(), 42, "привет"); err != nil {
+		t.Fatalf("send message: %v", err)
+	}
+	sent := api.sent()
+	if len(sent) != 1 || sent[0] != (sentMessage{ChatID: 42, Text: "привет"}) {
+		t.Errorf("sent = %+v", sent)
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	api := newFakeBotAPI(t, "123:secret")
+	api.fail = "Bad Request: chat not found"
+
+	err := api.client().SendMessage(context.Background(), 42, "hi")
+	if err == nil || err.Error() != "telegram: sendMessage: Bad Request: chat not found" {
+		t.Errorf("err = %v", err)
+	}
+
+	// Transport errors carry the URL, which includes the token.
+	api.server.Close()
+	_, err = api.client().GetUpdates(context.Background(), 0, time.Second)
+	if err == nil || strings.Contains(err.Error(), "secret") {
+		t.Errorf("err = %v, want an error without the token", err)
+	}
+}
@@ -0,0 +1,64 @@
+package telegram
+
+import (
+	"fmt"
+
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+var messages = map[string]map[string]string{
+	"ru": {
+		"help": "Команды:\n/list — активные подписки\n/upcoming — списания на ближайшие 30 дней\n/total — расходы в месяц и в год\n\n" +
+			"Чтобы привязать чат, получите код в личном кабинете и отправьте /start КОД.",
+		"not_linked":     "Чат не привязан к аккаунту. Получите код в личном кабинете и отправьте /start КОД.",
+		"bad_code":       "Код не подошёл: он неверный, уже использован или истёк. Получите новый в личном кабинете.",
+		"linked":         "Чат привязан к аккаунту %s. Сюда будут приходить напоминания о списаниях.",
+		"failed":         "Что-то пошло не так, попробуйте позже.",
+		"list":           "Активные подписки:",
+		"list_empty":     "Активных подписок нет.",
+		"list_item":      "• %s — %s %s, следующее списание %s",
+		"list_none":      "• %s — %s %s",
+		"upcoming":       "Списания на ближайшие 30 дней:",
+		"upcoming_empty": "В ближайшие 30 дней списаний нет.",
+		"total":          "Расходы на активные подписки:",
+		"total_empty":    "Активных подписок нет.",
+		"total_item":     "• %s: %s в месяц, %s в год",
+		"reminder":       "Напоминание: %s по подписке «%s» будет списано %s %s.",
+		"date":           "02.01.2006",
+		"charge_item":    "• %s %s — %s %s",
+		"unknown":        "Не понимаю. Отправьте /help, чтобы увидеть список команд.",
+	},
+	"en": {
+		"help": "Commands:\n/list — active subscriptions\n/upcoming — charges in the next 30 days\n/total — monthly and yearly spend\n\n" +
+			"To link this chat, get a code in your account and send /start CODE.",
+		"not_linked":     "This chat is not linked to an account. Get a code in your account and send /start CODE.",
+		"bad_code":       "The code is wrong, already used or expired. Get a new one in your account.",
+		"linked":         "This chat is now linked to %s. Charge reminders will arrive here.",
+		"failed":         "Something went wrong, please try again later.",
+		"list":           "Active subscriptions:",
+		"list_empty":     "No active subscriptions.",
+		"list_item":      "• %s — %s %s, next charge on %s",
+		"list_none":      "• %s — %s %s",
+		"upcoming":       "Charges in the next 30 days:",
+		"upcoming_empty": "No charges in the next 30 days.",
+		"total":          "Spend on active subscriptions:",
+		"total_empty":    "No active subscriptions.",
+		"total_item":     "• %s: %s a month, %s a year",
+		"reminder":       "Reminder: %[2]s will charge %[3]s %[4]s on %[1]s.",
+		"date":           "Jan 2, 2006",
+		"charge_item":    "• %s %s — %s %s",
+		"unknown":        "Sorry, I don't understand. Send /help to see the commands.",
+	},
+}
+
+// text returns the message key in locale, formatted with args.
+func text(locale, key string, args ...any) string {
+	set, ok := messages[locale]
+	if !ok {
+		set = messages[usecase.DefaultLocale]
+	}
+	if len(args) == 0 {
+		return set[key]
+	}
+	return fmt.Sprintf(set[key], args...)
+}
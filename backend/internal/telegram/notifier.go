@@ -0,0 +1,39 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+
+	"subscribe_tracker/backend/internal/domain"
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+// Notifier sends reminders to the Telegram chat linked to the user. Users
+// without a linked chat get usecase.ErrNoRecipient.
+type Notifier struct {
+	Client Client
+	Links  usecase.TelegramUsecase
+}
+
+func NewNotifier(client Client, links usecase.TelegramUsecase) Notifier {
+	return Notifier{
+		Client: client,
+		Links:  links,
+	}
+}
+
+func (n Notifier) Notify(ctx context.Context, user domain.User, reminder domain.Reminder) error {
+	chatID, err := n.Links.Chat(ctx, user.ID)
+	if errors.Is(err, usecase.ErrNotFound) {
+		return usecase.ErrNoRecipient
+	}
+	if err != nil {
+		return err
+	}
+	return n.Client.SendMessage(ctx, chatID, text(user.Locale, "reminder",
+		reminder.ChargeDate.Format(text(user.Locale, "date")),
+		reminder.ServiceName,
+		usecase.FormatAmount(reminder.Amount, reminder.Currency),
+		reminder.Currency,
+	))
+}
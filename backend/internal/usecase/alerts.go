@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"subscribe_tracker/backend/internal/domain"
+)
+
+type AlertUsecase struct {
+	PaymentMethods PaymentMethodRepository
+	Subscriptions  SubscriptionRepository
+}
+
+func NewAlertUsecase(paymentMethods PaymentMethodRepository, subscriptions SubscriptionRepository) AlertUsecase {
+	return AlertUsecase{
+		PaymentMethods: paymentMethods,
+		Subscriptions:  subscriptions,
+	}
+}
+
+// ExpiringCard is a payment method that stops working soon together with the
+// active subscriptions charged to it. Monthly sums their normalized monthly
+// cost per currency.
+type ExpiringCard struct {
+	PaymentMethod domain.PaymentMethod
+	ExpiresOn     time.Time
+	Expired       bool
+	Subscriptions []domain.Subscription
+	Monthly       []CurrencyAmount
+}
+
+// ExpiringCards lists payment methods whose expiry falls within the given
+// number of days from today, soonest first. Cards that have already expired
+// are included as well, since their subscriptions are failing already. A card
+// is valid through the last day of its expiry month.
+func (u AlertUsecase) ExpiringCards(ctx context.Context, userID string, days int) ([]ExpiringCard, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, ErrUnauthorized
+	}
+	if days < 0 {
+		return nil, ErrInvalidInput
+	}
+	methods, err := u.PaymentMethods.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	subs, err := u.Subscriptions.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := today()
+	until := now.AddDate(0, 0, days)
+	var results []ExpiringCard
+	for _, method := range methods {
+		if method.ExpYear == 0 {
+			continue
+		}
+		month := time.Month(method.ExpMonth)
+		expiresOn := time.Date(method.ExpYear, month, daysInMonth(method.ExpYear, month), 0, 0, 0, 0, time.UTC)
+		if expiresOn.After(until) {
+			continue
+		}
+
+		card := ExpiringCard{
+			PaymentMethod: method,
+			ExpiresOn:     expiresOn,
+			Expired:       expiresOn.Before(now),
+		}
+		monthly := map[string]*CurrencyAmount{}
+		for _, sub := range subs {
+			if sub.PaymentMethodID != method.ID || sub.Status != domain.StatusActive {
+				continue
+			}
+			sub = withNextCharge(sub, now)
+			card.Subscriptions = append(card.Subscriptions, sub)
+			if sub.NextChargeDate.IsZero() {
+				continue
+			}
+
+			item := spendItem(sub)
+			total, ok := monthly[item.Currency]
+			if !ok {
+				total = &CurrencyAmount{Currency: item.Currency}
+				monthly[item.Currency] = total
+			}
+			total.Amount += item.Monthly
+			total.Count++
+		}
+		for _, total := range monthly {
+			card.Monthly = append(card.Monthly, *total)
+		}
+		sort.Slice(card.Monthly, func(i, j int) bool {
+			return card.Monthly[i].Currency < card.Monthly[j].Currency
+		})
+		results = append(results, card)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].ExpiresOn.Before(results[j].ExpiresOn)
+	})
+	return results, nil
+}
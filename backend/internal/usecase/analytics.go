@@ -0,0 +1,395 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"subscribe_tracker/backend/internal/domain"
+)
+
+const forecastMonths = 12
+
+type AnalyticsUsecase struct {
+	Subscriptions SubscriptionRepository
+	Users         UserRepository
+	Rates         RateRepository
+}
+
+func NewAnalyticsUsecase(subscriptions SubscriptionRepository, users UserRepository, rates RateRepository) AnalyticsUsecase {
+	return AnalyticsUsecase{
+		Subscriptions: subscriptions,
+		Users:         users,
+		Rates:         rates,
+	}
+}
+
+// SpendItem is a subscription with its cost normalized to a month and a year
+// in the currency of its next charge.
+type SpendItem struct {
+	Subscription domain.Subscription
+	Currency     string
+	Monthly      int64
+	Yearly       int64
+}
+
+// SpendTotal sums normalized costs of one group. Amounts in different
+// currencies are never added up, so every group is keyed by currency too.
+type SpendTotal struct {
+	BankName  string
+	CardLast4 string
+	Category  string
+	Tag       string
+	Currency  string
+	Monthly   int64
+	Yearly    int64
+	Count     int
+
+	// BaseMonthly and BaseYearly are Monthly and Yearly in the base currency
+	// of the report; Converted is false when there is no rate for Currency.
+	BaseMonthly int64
+	BaseYearly  int64
+	Converted   bool
+}
+
+type ProjectedCharge struct {
+	Date         time.Time
+	Subscription domain.Subscription
+	Amount       int64
+	Currency     string
+}
+
+type CurrencyAmount struct {
+	Currency string
+	Amount   int64
+	Count    int
+
+	BaseAmount int64
+	Converted  bool
+}
+
+type ForecastMonth struct {
+	Month   time.Time
+	Totals  []CurrencyAmount
+	Charges []ProjectedCharge
+	// BaseTotal sums the converted Totals.
+	BaseTotal int64
+}
+
+type SpendReport struct {
+	From       time.Time
+	Items      []SpendItem
+	ByCurrency []SpendTotal
+	ByBank     []SpendTotal
+	ByCard     []SpendTotal
+	// ByCategory has an entry with an empty Category for uncategorized
+	// subscriptions; ByTag counts a subscription once per tag.
+	ByCategory []SpendTotal
+	ByTag      []SpendTotal
+	Forecast   []ForecastMonth
+
+	// Base is the currency totals are converted into and Total the sum of
+	// all converted totals in it. Rates are the rates applied; RateDate is
+	// the date of the oldest one. Unconverted lists currencies without a
+	// rate, which are left out of Total.
+	Base        string
+	Total       SpendTotal
+	Rates       []AppliedRate
+	RateDate    time.Time
+	Unconverted []string
+}
+
+type AnalyticsSettings struct {
+	BaseCurrency string
+}
+
+func (u AnalyticsUsecase) Settings(ctx context.Context, userID string) (AnalyticsSettings, error) {
+	if strings.TrimSpace(userID) == "" {
+		return AnalyticsSettings{}, ErrUnauthorized
+	}
+	user, err := u.Users.FindByID(ctx, userID)
+	if err != nil {
+		return AnalyticsSettings{}, err
+	}
+	return AnalyticsSettings{BaseCurrency: user.BaseCurrency}, nil
+}
+
+func (u AnalyticsUsecase) UpdateSettings(ctx context.Context, userID string, input AnalyticsSettings) (AnalyticsSettings, error) {
+	if strings.TrimSpace(userID) == "" {
+		return AnalyticsSettings{}, ErrUnauthorized
+	}
+	base, err := normalizeBaseCurrency(input.BaseCurrency)
+	if err != nil {
+		return AnalyticsSettings{}, err
+	}
+	user, err := u.Users.UpdateBaseCurrency(ctx, userID, base)
+	if err != nil {
+		return AnalyticsSettings{}, err
+	}
+	return AnalyticsSettings{BaseCurrency: user.BaseCurrency}, nil
+}
+
+// Spend normalizes every active subscription to monthly and yearly cost
+// and projects charges over the next twelve months, each at the price
+// effective on its date. Cancelled and paused subscriptions are left out of
+// the totals; their remaining charges before the effective date still show
+// up in the forecast. Totals are also converted into the user's base
+// currency.
+func (u AnalyticsUsecase) Spend(ctx context.Context, userID string) (SpendReport, error) {
+	return u.SpendIn(ctx, userID, "")
+}
+
+// SpendIn is Spend with totals converted into base instead of the user's
+// base currency; an empty base keeps the user's.
+func (u AnalyticsUsecase) SpendIn(ctx context.Context, userID, base string) (SpendReport, error) {
+	if strings.TrimSpace(userID) == "" {
+		return SpendReport{}, ErrUnauthorized
+	}
+	if strings.TrimSpace(base) == "" {
+		user, err := u.Users.FindByID(ctx, userID)
+		if err != nil {
+			return SpendReport{}, err
+		}
+		base = user.BaseCurrency
+	}
+	base, err := normalizeBaseCurrency(base)
+	if err != nil {
+		return SpendReport{}, err
+	}
+	rates, err := u.Rates.Latest(ctx)
+	if err != nil {
+		return SpendReport{}, err
+	}
+	subs, err := u.Subscriptions.ListByUserID(ctx, userID)
+	if err != nil {
+		return SpendReport{}, err
+	}
+
+	now := today()
+	report := SpendReport{From: now}
+	byCurrency := map[string]*SpendTotal{}
+	byBank := map[string]*SpendTotal{}
+	byCard := map[string]*SpendTotal{}
+	byCategory := map[string]*SpendTotal{}
+	byTag := map[string]*SpendTotal{}
+
+	for _, sub := range subs {
+		sub = withNextCharge(sub, now)
+		if sub.Status != domain.StatusActive || sub.NextChargeDate.IsZero() {
+			continue
+		}
+
+		item := spendItem(sub)
+		report.Items = append(report.Items, item)
+
+		currency := sub.NextChargeCurrency
+		addSpend(byCurrency, currency, SpendTotal{Currency: currency}, item)
+		addSpend(byBank, sub.BankName+"|"+currency, SpendTotal{BankName: sub.BankName, Currency: currency}, item)
+		addSpend(byCard, sub.BankName+"|"+sub.CardLast4+"|"+currency,
+			SpendTotal{BankName: sub.BankName, CardLast4: sub.CardLast4, Currency: currency}, item)
+
+		category := ""
+		if sub.Category != nil {
+			category = sub.Category.Name
+		}
+		addSpend(byCategory, category+"|"+currency, SpendTotal{Category: category, Currency: currency}, item)
+		for _, tag := range sub.Tags {
+			addSpend(byTag, tag.Name+"|"+currency, SpendTotal{Tag: tag.Name, Currency: currency}, item)
+		}
+	}
+
+	report.ByCurrency = sortedTotals(byCurrency)
+	report.ByBank = sortedTotals(byBank)
+	report.ByCard = sortedTotals(byCard)
+	report.ByCategory = sortedTotals(byCategory)
+	report.ByTag = sortedTotals(byTag)
+	report.Forecast = forecast(subs, now)
+	report.convert(newConverter(base, rates))
+	return report, nil
+}
+
+// convert fills in the base currency amounts of every total.
+func (r *SpendReport) convert(c converter) {
+	r.Base = c.base
+	r.Total = SpendTotal{Currency: c.base, Converted: true}
+
+	applied := map[string]*AppliedRate{}
+	missing := map[string]bool{}
+	lookup := func(currency string) (AppliedRate, bool) {
+		if rate, ok := applied[currency]; ok {
+			return *rate, true
+		}
+		if missing[currency] {
+			return AppliedRate{}, false
+		}
+		rate, ok := c.rate(currency)
+		if !ok {
+			missing[currency] = true
+			r.Unconverted = append(r.Unconverted, currency)
+			return AppliedRate{}, false
+		}
+		applied[currency] = &rate
+		if currency != c.base {
+			r.Rates = append(r.Rates, rate)
+			if r.RateDate.IsZero() || rate.Date.Before(r.RateDate) {
+				r.RateDate = rate.Date
+			}
+		}
+		return rate, true
+	}
+	convertTotals := func(totals []SpendTotal) {
+		for i := range totals {
+			rate, ok := lookup(totals[i].Currency)
+			if !ok {
+				continue
+			}
+			totals[i].BaseMonthly = convertAmount(totals[i].Monthly, totals[i].Currency, rate, c.base)
+			totals[i].BaseYearly = convertAmount(totals[i].Yearly, totals[i].Currency, rate, c.base)
+			totals[i].Converted = true
+		}
+	}
+
+	convertTotals(r.ByCurrency)
+	convertTotals(r.ByBank)
+	convertTotals(r.ByCard)
+	convertTotals(r.ByCategory)
+	convertTotals(r.ByTag)
+	for _, total := range r.ByCurrency {
+		r.Total.Count += total.Count
+		if total.Converted {
+			r.Total.Monthly += total.BaseMonthly
+			r.Total.Yearly += total.BaseYearly
+		}
+	}
+	r.Total.BaseMonthly, r.Total.BaseYearly = r.Total.Monthly, r.Total.Yearly
+
+	for i := range r.Forecast {
+		month := &r.Forecast[i]
+		for j := range month.Totals {
+			rate, ok := lookup(month.Totals[j].Currency)
+			if !ok {
+				continue
+			}
+			month.Totals[j].BaseAmount = convertAmount(month.Totals[j].Amount, month.Totals[j].Currency, rate, c.base)
+			month.Totals[j].Converted = true
+			month.BaseTotal += month.Totals[j].BaseAmount
+		}
+	}
+	sort.Strings(r.Unconverted)
+	sort.Slice(r.Rates, func(i, j int) bool { return r.Rates[i].Currency < r.Rates[j].Currency })
+}
+
+func forecast(subs []domain.Subscription, now time.Time) []ForecastMonth {
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, forecastMonths, 0)
+	limit := int(end.Sub(now).Hours()/24) + 1
+
+	months := make([]ForecastMonth, forecastMonths)
+	totals := make([]map[string]*CurrencyAmount, forecastMonths)
+	for i := range months {
+		months[i].Month = start.AddDate(0, i, 0)
+		totals[i] = map[string]*CurrencyAmount{}
+	}
+
+	for _, sub := range subs {
+		for _, date := range upcomingCharges(sub, now, limit) {
+			if !date.Before(end) {
+				break
+			}
+			i := (date.Year()-start.Year())*12 + int(date.Month()-start.Month())
+			amount, currency := chargePrice(sub, date)
+			months[i].Charges = append(months[i].Charges, ProjectedCharge{
+				Date:         date,
+				Subscription: sub,
+				Amount:       amount,
+				Currency:     currency,
+			})
+
+			total, ok := totals[i][currency]
+			if !ok {
+				total = &CurrencyAmount{Currency: currency}
+				totals[i][currency] = total
+			}
+			total.Amount += amount
+			total.Count++
+		}
+	}
+
+	for i := range months {
+		sort.SliceStable(months[i].Charges, func(a, b int) bool {
+			return months[i].Charges[a].Date.Before(months[i].Charges[b].Date)
+		})
+		for _, total := range totals[i] {
+			months[i].Totals = append(months[i].Totals, *total)
+		}
+		sort.Slice(months[i].Totals, func(a, b int) bool {
+			return months[i].Totals[a].Currency < months[i].Totals[b].Currency
+		})
+	}
+	return months
+}
+
+// spendItem normalizes the next charge of sub to a month and a year.
+func spendItem(sub domain.Subscription) SpendItem {
+	yearly := float64(sub.NextChargeAmount) * chargesPerYear(sub.Billing)
+	return SpendItem{
+		Subscription: sub,
+		Currency:     sub.NextChargeCurrency,
+		Monthly:      int64(math.Round(yearly / 12)),
+		Yearly:       int64(math.Round(yearly)),
+	}
+}
+
+// chargesPerYear returns the average number of charges a year of cycle.
+func chargesPerYear(cycle domain.BillingCycle) float64 {
+	if cycle.Interval < 1 {
+		return 0
+	}
+	interval := float64(cycle.Interval)
+	switch cycle.Unit {
+	case domain.BillingDay:
+		return 365.2425 / interval
+	case domain.BillingWeek:
+		return 365.2425 / 7 / interval
+	case domain.BillingMonth:
+		return 12 / interval
+	case domain.BillingYear:
+		return 1 / interval
+	default:
+		return 0
+	}
+}
+
+func addSpend(groups map[string]*SpendTotal, key string, empty SpendTotal, item SpendItem) {
+	total, ok := groups[key]
+	if !ok {
+		total = &empty
+		groups[key] = total
+	}
+	total.Monthly += item.Monthly
+	total.Yearly += item.Yearly
+	total.Count++
+}
+
+func sortedTotals(groups map[string]*SpendTotal) []SpendTotal {
+	results := make([]SpendTotal, 0, len(groups))
+	for _, total := range groups {
+		results = append(results, *total)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Currency != results[j].Currency {
+			return results[i].Currency < results[j].Currency
+		}
+		if results[i].Monthly != results[j].Monthly {
+			return results[i].Monthly > results[j].Monthly
+		}
+		return groupKey(results[i]) < groupKey(results[j])
+	})
+	return results
+}
+
+func groupKey(total SpendTotal) string {
+	return total.BankName + "|" + total.CardLast4 + "|" + total.Category + "|" + total.Tag
+}
@@ -2,35 +2,67 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"log"
+	"net/mail"
 	"strings"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 	"subscribe_tracker/backend/internal/domain"
 )
 
 type AuthUsecase struct {
-	Users  UserRepository
-	Tokens TokenManager
+	Users    UserRepository
+	Sessions SessionRepository
+	Tokens   TokenManager
+	// RefreshTTL is how long a refresh token stays valid; each refresh
+	// extends the session by as much.
+	RefreshTTL time.Duration
+	// Verification sends new users a link to confirm their email.
+	Verification VerificationUsecase
+	// TwoFactor asks users who enabled it for a code after the password.
+	TwoFactor TwoFactorUsecase
+	// Guard slows down and locks out password guessing.
+	Guard LoginGuard
 }
 
-func NewAuthUsecase(users UserRepository, tokens TokenManager) AuthUsecase {
+func NewAuthUsecase(users UserRepository, sessions SessionRepository, tokens TokenManager, refreshTTL time.Duration, verification VerificationUsecase, twoFactor TwoFactorUsecase, guard LoginGuard) AuthUsecase {
 	return AuthUsecase{
-		Users:  users,
-		Tokens: tokens,
+		Users:        users,
+		Sessions:     sessions,
+		Tokens:       tokens,
+		RefreshTTL:   refreshTTL,
+		Verification: verification,
+		TwoFactor:    twoFactor,
+		Guard:        guard,
 	}
 }
 
 type AuthResult struct {
-	Token string
-	User  domain.User
+	Token        string
+	RefreshToken string
+	User         domain.User
+	// MFATicket is set instead of the tokens when the password was right but
+	// a second factor is still needed; see LoginMFA.
+	MFATicket string
 }
 
-func (u AuthUsecase) Register(ctx context.Context, name, email, password string) (AuthResult, error) {
+// ClientInfo describes the device a session is started or refreshed from.
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+func (u AuthUsecase) Register(ctx context.Context, name, email, password string, client ClientInfo) (AuthResult, error) {
 	name = strings.TrimSpace(name)
 	email = strings.ToLower(strings.TrimSpace(email))
-	if name == "" || email == "" || len(password) < 8 {
+	if name == "" || email == "" || len(password) < minPasswordLength {
 		return AuthResult{}, ErrInvalidInput
 	}
+	if address, err := mail.ParseAddress(email); err != nil || address.Address != email {
+		return AuthResult{}, invalidField("email")
+	}
 
 	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
@@ -41,34 +73,217 @@ func (u AuthUsecase) Register(ctx context.Context, name, email, password string)
 	if err != nil {
 		return AuthResult{}, err
 	}
-
-	token, err := u.Tokens.Sign(user.ID, user.Email)
-	if err != nil {
-		return AuthResult{}, err
+	// The account exists by now; without the email the user can ask for
+	// another one.
+	if err := u.Verification.send(ctx, user); err != nil {
+		log.Printf("email verification for %s: %v", user.Email, err)
 	}
 
-	return AuthResult{Token: token, User: user}, nil
+	return u.startSession(ctx, user, client)
 }
 
-func (u AuthUsecase) Login(ctx context.Context, email, password string) (AuthResult, error) {
+func (u AuthUsecase) Login(ctx context.Context, email, password string, client ClientInfo) (AuthResult, error) {
 	email = strings.ToLower(strings.TrimSpace(email))
 	if email == "" || password == "" {
 		return AuthResult{}, ErrInvalidInput
 	}
 
+	if err := u.Guard.check(ctx, email, client); err != nil {
+		return AuthResult{}, err
+	}
+
 	user, err := u.Users.FindByEmail(ctx, email)
+	if errors.Is(err, ErrNotFound) {
+		// Unknown emails count as failures too and get the same answer as a
+		// wrong password.
+		if err := u.Guard.fail(ctx, email, nil, client); err != nil {
+			return AuthResult{}, err
+		}
+		return AuthResult{}, ErrUnauthorized
+	}
 	if err != nil {
 		return AuthResult{}, err
 	}
 
 	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		if err := u.Guard.fail(ctx, email, &user, client); err != nil {
+			return AuthResult{}, err
+		}
+		return AuthResult{}, ErrUnauthorized
+	}
+
+	mfa, err := u.TwoFactor.enabled(ctx, user.ID)
+	if err != nil {
+		return AuthResult{}, err
+	}
+	if mfa {
+		// The failures are forgotten only once the code is right too, or
+		// every new ticket would buy another round of guesses.
+		ticket, err := u.TwoFactor.issueTicket(ctx, user.ID)
+		if err != nil {
+			return AuthResult{}, err
+		}
+		return AuthResult{MFATicket: ticket}, nil
+	}
+	if err := u.Guard.succeed(ctx, email); err != nil {
+		return AuthResult{}, err
+	}
+
+	return u.startSession(ctx, user, client)
+}
+
+// LoginMFA finishes a login that returned an MFA ticket, given a code from
+// the authenticator app or a recovery code. A ticket is valid for a few
+// minutes and a few wrong codes. Wrong codes also count as failed logins of
+// the account.
+func (u AuthUsecase) LoginMFA(ctx context.Context, ticket, code string, client ClientInfo) (AuthResult, error) {
+	if strings.TrimSpace(ticket) == "" || strings.TrimSpace(code) == "" {
+		return AuthResult{}, ErrInvalidInput
+	}
+	userID, err := u.TwoFactor.ticketUser(ctx, ticket)
+	if err != nil {
+		return AuthResult{}, err
+	}
+	user, err := u.Users.FindByID(ctx, userID)
+	if err != nil {
+		return AuthResult{}, err
+	}
+
+	if err := u.Guard.check(ctx, user.Email, client); err != nil {
+		return AuthResult{}, err
+	}
+	if err := u.TwoFactor.redeemTicket(ctx, ticket, userID, code); err != nil {
+		if errors.Is(err, ErrUnauthorized) {
+			if err := u.Guard.fail(ctx, user.Email, &user, client); err != nil {
+				return AuthResult{}, err
+			}
+		}
+		return AuthResult{}, err
+	}
+	if err := u.Guard.succeed(ctx, user.Email); err != nil {
+		return AuthResult{}, err
+	}
+	return u.startSession(ctx, user, client)
+}
+
+// Refresh exchanges a refresh token for a new access and refresh token. A
+// token can be exchanged once: presenting it again means it leaked, so the
+// whole session is revoked.
+func (u AuthUsecase) Refresh(ctx context.Context, refreshToken string, client ClientInfo) (AuthResult, error) {
+	refreshToken = strings.TrimSpace(refreshToken)
+	if refreshToken == "" {
+		return AuthResult{}, ErrUnauthorized
+	}
+	current, err := u.Sessions.FindByToken(ctx, hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return AuthResult{}, ErrUnauthorized
+		}
+		return AuthResult{}, err
+	}
+	if current.RevokedAt != nil || !current.ExpiresAt.After(time.Now()) {
 		return AuthResult{}, ErrUnauthorized
 	}
+	if current.RotatedAt != nil {
+		return AuthResult{}, u.revokeReused(ctx, current)
+	}
+
+	token, err := randomToken(32)
+	if err != nil {
+		return AuthResult{}, err
+	}
+	next, err := u.Sessions.Rotate(ctx, current.ID, domain.Session{
+		TokenHash: hashToken(token),
+		UserAgent: client.UserAgent,
+		IPAddress: client.IPAddress,
+		ExpiresAt: time.Now().Add(u.RefreshTTL),
+	})
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return AuthResult{}, u.revokeReused(ctx, current)
+		}
+		return AuthResult{}, err
+	}
+
+	user, err := u.Users.FindByID(ctx, next.UserID)
+	if err != nil {
+		return AuthResult{}, err
+	}
+	access, err := u.Tokens.Sign(user.ID, user.Email, next.FamilyID)
+	if err != nil {
+		return AuthResult{}, err
+	}
+	return AuthResult{Token: access, RefreshToken: token, User: user}, nil
+}
+
+// Logout revokes the session of the refresh token. Unknown and already
+// revoked tokens are ignored.
+func (u AuthUsecase) Logout(ctx context.Context, refreshToken string) error {
+	refreshToken = strings.TrimSpace(refreshToken)
+	if refreshToken == "" {
+		return ErrInvalidInput
+	}
+	session, err := u.Sessions.FindByToken(ctx, hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if err := u.Sessions.RevokeFamily(ctx, session.UserID, session.FamilyID); err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// ListSessions returns the signed-in devices of the user, most recently
+// refreshed first. Their IDs are family IDs.
+func (u AuthUsecase) ListSessions(ctx context.Context, userID string) ([]domain.Session, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, ErrUnauthorized
+	}
+	return u.Sessions.ListActive(ctx, userID)
+}
+
+func (u AuthUsecase) RevokeSession(ctx context.Context, userID, id string) error {
+	if strings.TrimSpace(userID) == "" {
+		return ErrUnauthorized
+	}
+	if strings.TrimSpace(id) == "" {
+		return ErrInvalidInput
+	}
+	return u.Sessions.RevokeFamily(ctx, userID, id)
+}
 
-	token, err := u.Tokens.Sign(user.ID, user.Email)
+func (u AuthUsecase) startSession(ctx context.Context, user domain.User, client ClientInfo) (AuthResult, error) {
+	token, err := randomToken(32)
+	if err != nil {
+		return AuthResult{}, err
+	}
+	session, err := u.Sessions.Create(ctx, domain.Session{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		UserAgent: client.UserAgent,
+		IPAddress: client.IPAddress,
+		ExpiresAt: time.Now().Add(u.RefreshTTL),
+	})
+	if err != nil {
+		return AuthResult{}, err
+	}
+
+	access, err := u.Tokens.Sign(user.ID, user.Email, session.FamilyID)
 	if err != nil {
 		return AuthResult{}, err
 	}
 
-	return AuthResult{Token: token, User: user}, nil
+	return AuthResult{Token: access, RefreshToken: token, User: user}, nil
+}
+
+// revokeReused revokes the family of a refresh token presented after it was
+// replaced and reports the attempt as unauthorized.
+func (u AuthUsecase) revokeReused(ctx context.Context, session domain.Session) error {
+	if err := u.Sessions.RevokeFamily(ctx, session.UserID, session.FamilyID); err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	return ErrUnauthorized
 }
@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"strings"
+
+	"subscribe_tracker/backend/internal/domain"
+)
+
+const maxBillingInterval = 365
+
+// customBilling is the preset name of cycles that have none.
+const customBilling = "custom"
+
+// billingPresets keeps the named cycles accepted by the original API so that
+// clients sending "monthly" or "yearly" keep working.
+var billingPresets = []struct {
+	Name  string
+	Cycle domain.BillingCycle
+}{
+	{"weekly", domain.BillingCycle{Unit: domain.BillingWeek, Interval: 1}},
+	{"monthly", domain.BillingCycle{Unit: domain.BillingMonth, Interval: 1}},
+	{"quarterly", domain.BillingCycle{Unit: domain.BillingMonth, Interval: 3}},
+	{"semiannual", domain.BillingCycle{Unit: domain.BillingMonth, Interval: 6}},
+	{"yearly", domain.BillingCycle{Unit: domain.BillingYear, Interval: 1}},
+}
+
+// BillingPresetName returns the preset name of the cycle or "custom".
+func BillingPresetName(cycle domain.BillingCycle) string {
+	for _, preset := range billingPresets {
+		if preset.Cycle == cycle {
+			return preset.Name
+		}
+	}
+	return customBilling
+}
+
+func parseBillingCycle(preset, unit string, interval int) (domain.BillingCycle, error) {
+	unit = strings.ToLower(strings.TrimSpace(unit))
+	if unit == "" {
+		preset = strings.ToLower(strings.TrimSpace(preset))
+		preset = strings.NewReplacer("-", "", "_", "").Replace(preset)
+		for _, item := range billingPresets {
+			if item.Name == preset {
+				return item.Cycle, nil
+			}
+		}
+		return domain.BillingCycle{}, ErrInvalidInput
+	}
+
+	cycle := domain.BillingCycle{Unit: domain.BillingUnit(unit), Interval: interval}
+	if cycle.Interval == 0 {
+		cycle.Interval = 1
+	}
+	switch cycle.Unit {
+	case domain.BillingDay, domain.BillingWeek, domain.BillingMonth, domain.BillingYear:
+	default:
+		return domain.BillingCycle{}, ErrInvalidInput
+	}
+	if cycle.Interval < 1 || cycle.Interval > maxBillingInterval {
+		return domain.BillingCycle{}, ErrInvalidInput
+	}
+	return cycle, nil
+}
@@ -0,0 +1,206 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"subscribe_tracker/backend/internal/domain"
+)
+
+const feedTokenBytes = 32
+
+type CalendarUsecase struct {
+	Feeds         CalendarRepository
+	Users         UserRepository
+	Subscriptions SubscriptionRepository
+}
+
+func NewCalendarUsecase(feeds CalendarRepository, users UserRepository, subscriptions SubscriptionRepository) CalendarUsecase {
+	return CalendarUsecase{
+		Feeds:         feeds,
+		Users:         users,
+		Subscriptions: subscriptions,
+	}
+}
+
+// CalendarEvent is a run of charges of one subscription at the same price.
+// A subscription whose price changes in the future is split into several
+// events, each ending the day before the next one starts.
+type CalendarEvent struct {
+	// UID stays the same while the run's price is in effect, so calendar
+	// clients update the event as its charges pass instead of adding a new
+	// one.
+	UID          string
+	Subscription domain.Subscription
+	Start        time.Time
+	// Until is the last day of the run; nil means the charges go on.
+	Until    *time.Time
+	Rule     string
+	Amount   int64
+	Currency string
+	LeadDays int
+}
+
+// Feed returns when the user's current feed token was created, or
+// ErrNotFound if there is none.
+func (u CalendarUsecase) Feed(ctx context.Context, userID string) (time.Time, error) {
+	if strings.TrimSpace(userID) == "" {
+		return time.Time{}, ErrUnauthorized
+	}
+	return u.Feeds.FindFeed(ctx, userID)
+}
+
+// CreateFeed issues a new feed token, revoking the previous one. The token is
+// only returned here; just its hash is stored.
+func (u CalendarUsecase) CreateFeed(ctx context.Context, userID string) (string, time.Time, error) {
+	if strings.TrimSpace(userID) == "" {
+		return "", time.Time{}, ErrUnauthorized
+	}
+	token, err := randomToken(feedTokenBytes)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	createdAt, err := u.Feeds.SetFeed(ctx, userID, hashToken(token))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, createdAt, nil
+}
+
+func (u CalendarUsecase) RevokeFeed(ctx context.Context, userID string) error {
+	if strings.TrimSpace(userID) == "" {
+		return ErrUnauthorized
+	}
+	return u.Feeds.DeleteFeed(ctx, userID)
+}
+
+// Events returns the upcoming charges of the user the feed token belongs to,
+// or ErrNotFound for unknown and revoked tokens.
+func (u CalendarUsecase) Events(ctx context.Context, token string) (domain.User, []CalendarEvent, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return domain.User{}, nil, ErrNotFound
+	}
+	userID, err := u.Feeds.FindUserIDByFeed(ctx, hashToken(token))
+	if err != nil {
+		return domain.User{}, nil, err
+	}
+	user, err := u.Users.FindByID(ctx, userID)
+	if err != nil {
+		return domain.User{}, nil, err
+	}
+	subs, err := u.Subscriptions.ListByUserID(ctx, userID)
+	if err != nil {
+		return domain.User{}, nil, err
+	}
+
+	now := today()
+	var events []CalendarEvent
+	for _, sub := range subs {
+		leadDays := user.ReminderLeadDays
+		if sub.ReminderLeadDays != nil {
+			leadDays = *sub.ReminderLeadDays
+		}
+		events = append(events, calendarEvents(sub, now, leadDays)...)
+	}
+	return user, events, nil
+}
+
+// calendarEvents splits the charges of sub from now on into runs at the same
+// price. The runs of paused and cancelled subscriptions end before the
+// effective date.
+func calendarEvents(sub domain.Subscription, now time.Time, leadDays int) []CalendarEvent {
+	first := upcomingCharges(sub, now, 1)
+	if len(first) == 0 {
+		return nil
+	}
+	start := first[0]
+	anchor := chargeAnchor(sub)
+
+	var end *time.Time
+	if sub.Status == domain.StatusPaused || sub.Status == domain.StatusCancelled {
+		last := truncateDay(sub.StatusEffectiveDate).AddDate(0, 0, -1)
+		end = &last
+	}
+
+	var changes []time.Time
+	for _, change := range sub.Prices {
+		date := NextChargeDate(sub.Billing, anchor, change.EffectiveFrom)
+		if date.After(start) && (end == nil || !date.After(*end)) {
+			changes = append(changes, date)
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Before(changes[j]) })
+	starts := []time.Time{start}
+	for _, date := range changes {
+		if date.After(starts[len(starts)-1]) {
+			starts = append(starts, date)
+		}
+	}
+
+	var events []CalendarEvent
+	for i, from := range starts {
+		until := end
+		if i+1 < len(starts) {
+			last := starts[i+1].AddDate(0, 0, -1)
+			until = &last
+		}
+		amount, currency := chargePrice(sub, from)
+		uid := sub.ID
+		if price, ok := priceAt(sub, from); ok {
+			uid = sub.ID + "-" + price.ID
+		}
+		events = append(events, CalendarEvent{
+			UID:          uid,
+			Subscription: sub,
+			Start:        from,
+			Until:        until,
+			Rule:         recurrenceRule(sub.Billing, anchor, until),
+			Amount:       amount,
+			Currency:     currency,
+			LeadDays:     leadDays,
+		})
+	}
+	return events
+}
+
+// recurrenceRule returns the RFC 5545 RRULE of a billing cycle anchored at
+// anchor. Charges clamped to the end of shorter months are expressed as the
+// last of the days from the 28th up to the anchor day.
+func recurrenceRule(cycle domain.BillingCycle, anchor time.Time, until *time.Time) string {
+	var freq string
+	switch cycle.Unit {
+	case domain.BillingDay:
+		freq = "DAILY"
+	case domain.BillingWeek:
+		freq = "WEEKLY"
+	case domain.BillingMonth:
+		freq = "MONTHLY"
+	case domain.BillingYear:
+		freq = "YEARLY"
+	}
+	rule := "FREQ=" + freq + ";INTERVAL=" + strconv.Itoa(cycle.Interval)
+
+	day := anchor.Day()
+	switch {
+	case cycle.Unit == domain.BillingMonth && day > 28:
+		rule += ";BYMONTHDAY=" + monthDays(day) + ";BYSETPOS=-1"
+	case cycle.Unit == domain.BillingYear && anchor.Month() == time.February && day == 29:
+		rule += ";BYMONTH=2;BYMONTHDAY=" + monthDays(day) + ";BYSETPOS=-1"
+	}
+	if until != nil {
+		rule += ";UNTIL=" + until.Format("20060102")
+	}
+	return rule
+}
+
+func monthDays(last int) string {
+	days := make([]string, 0, last-27)
+	for day := 28; day <= last; day++ {
+		days = append(days, strconv.Itoa(day))
+	}
+	return strings.Join(days, ",")
+}
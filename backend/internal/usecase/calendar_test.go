@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"subscribe_tracker/backend/internal/domain"
+)
+
+func TestCalendarEventUIDsStayStable(t *testing.T) {
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2026, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	sub := domain.Subscription{
+		ID:                  "sub-1",
+		Billing:             domain.BillingCycle{Unit: domain.BillingMonth, Interval: 1},
+		ChargeDate:          date(1, 10),
+		Price:               999,
+		Currency:            "EUR",
+		Status:              domain.StatusActive,
+		StatusEffectiveDate: date(1, 10),
+		Prices: []domain.PriceChange{
+			{ID: "price-1", Price: 999, Currency: "EUR", EffectiveFrom: date(1, 10)},
+			{ID: "price-2", Price: 1199, Currency: "EUR", EffectiveFrom: date(4, 1)},
+		},
+	}
+
+	uids := func(now time.Time) []string {
+		var result []string
+		for _, event := range calendarEvents(sub, now, 3) {
+			result = append(result, event.UID)
+		}
+		return result
+	}
+
+	before := uids(date(2, 1))
+	if len(before) != 2 || before[0] != "sub-1-price-1" || before[1] != "sub-1-price-2" {
+		t.Fatalf("UIDs on Feb 1 = %v", before)
+	}
+	// A charge at the old price has passed; both runs keep their UIDs.
+	if after := uids(date(3, 1)); len(after) != 2 || after[0] != before[0] || after[1] != before[1] {
+		t.Errorf("UIDs on Mar 1 = %v, want %v", after, before)
+	}
+	// Once the old price has run out, the remaining run keeps its UID.
+	if after := uids(date(5, 1)); len(after) != 1 || after[0] != before[1] {
+		t.Errorf("UIDs on May 1 = %v, want [%s]", after, before[1])
+	}
+}
@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+
+	"subscribe_tracker/backend/internal/domain"
+)
+
+const maxLabelLength = 64
+
+type CategoryUsecase struct {
+	Categories CategoryRepository
+}
+
+func NewCategoryUsecase(categories CategoryRepository) CategoryUsecase {
+	return CategoryUsecase{Categories: categories}
+}
+
+func (u CategoryUsecase) List(ctx context.Context, userID string) ([]domain.Category, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, ErrUnauthorized
+	}
+	return u.Categories.ListByUserID(ctx, userID)
+}
+
+func (u CategoryUsecase) Create(ctx context.Context, userID, name string) (domain.Category, error) {
+	name, err := labelName(userID, name)
+	if err != nil {
+		return domain.Category{}, err
+	}
+	return u.Categories.Create(ctx, domain.Category{UserID: userID, Name: name})
+}
+
+func (u CategoryUsecase) Rename(ctx context.Context, userID, id, name string) (domain.Category, error) {
+	name, err := labelName(userID, name)
+	if err != nil {
+		return domain.Category{}, err
+	}
+	if strings.TrimSpace(id) == "" {
+		return domain.Category{}, ErrInvalidInput
+	}
+	return u.Categories.Update(ctx, domain.Category{ID: id, UserID: userID, Name: name})
+}
+
+// Delete removes the category; its subscriptions become uncategorized.
+func (u CategoryUsecase) Delete(ctx context.Context, userID, id string) error {
+	if strings.TrimSpace(userID) == "" {
+		return ErrUnauthorized
+	}
+	if strings.TrimSpace(id) == "" {
+		return ErrInvalidInput
+	}
+	return u.Categories.Delete(ctx, userID, id)
+}
+
+func labelName(userID, name string) (string, error) {
+	if strings.TrimSpace(userID) == "" {
+		return "", ErrUnauthorized
+	}
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" || len([]rune(name)) > maxLabelLength {
+		return "", ErrInvalidInput
+	}
+	return name, nil
+}
@@ -0,0 +1,177 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"subscribe_tracker/backend/internal/domain"
+)
+
+// maxGeneratedCharges bounds a single generation request, e.g. a daily
+// subscription anchored years ago.
+const maxGeneratedCharges = 1000
+
+type ChargeUsecase struct {
+	Charges       ChargeRepository
+	Subscriptions SubscriptionRepository
+}
+
+func NewChargeUsecase(charges ChargeRepository, subscriptions SubscriptionRepository) ChargeUsecase {
+	return ChargeUsecase{
+		Charges:       charges,
+		Subscriptions: subscriptions,
+	}
+}
+
+type ChargeInput struct {
+	ChargeDate string
+	Amount     string
+	Currency   string
+	Status     string
+	Note       string
+}
+
+func (u ChargeUsecase) List(ctx context.Context, userID, subscriptionID string) ([]domain.Charge, error) {
+	if _, err := u.subscription(ctx, userID, subscriptionID); err != nil {
+		return nil, err
+	}
+	return u.Charges.ListBySubscription(ctx, userID, subscriptionID)
+}
+
+func (u ChargeUsecase) Record(ctx context.Context, userID, subscriptionID string, input ChargeInput) (domain.Charge, error) {
+	sub, err := u.subscription(ctx, userID, subscriptionID)
+	if err != nil {
+		return domain.Charge{}, err
+	}
+	charge, err := toCharge(sub, input)
+	if err != nil {
+		return domain.Charge{}, err
+	}
+	return u.Charges.Create(ctx, charge)
+}
+
+// Correct overwrites a recorded charge, e.g. to confirm an expected charge as
+// paid or to mark a payment as refunded.
+func (u ChargeUsecase) Correct(ctx context.Context, userID, subscriptionID, id string, input ChargeInput) (domain.Charge, error) {
+	if strings.TrimSpace(id) == "" {
+		return domain.Charge{}, ErrInvalidInput
+	}
+	sub, err := u.subscription(ctx, userID, subscriptionID)
+	if err != nil {
+		return domain.Charge{}, err
+	}
+	charge, err := toCharge(sub, input)
+	if err != nil {
+		return domain.Charge{}, err
+	}
+	charge.ID = id
+	return u.Charges.Update(ctx, charge)
+}
+
+// GenerateExpected fills the ledger with expected charges from the billing
+// schedule up to and including until, starting from the first charge. Dates
+// on which the subscription was paused or cancelled get no charge, and dates
+// that already have one are skipped, so the call is safe to repeat.
+func (u ChargeUsecase) GenerateExpected(ctx context.Context, userID, subscriptionID, until string) ([]domain.Charge, error) {
+	sub, err := u.subscription(ctx, userID, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	end := today()
+	if value := strings.TrimSpace(until); value != "" {
+		if end, err = time.Parse("2006-01-02", value); err != nil {
+			return nil, ErrInvalidInput
+		}
+	}
+
+	history, err := u.Subscriptions.ListStatusHistory(ctx, userID, sub.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(history) == 0 {
+		// Subscriptions older than the status history only know their
+		// current status.
+		history = []domain.StatusChange{{Status: sub.Status, EffectiveDate: sub.StatusEffectiveDate}}
+	}
+
+	var charges []domain.Charge
+	anchor := chargeAnchor(sub)
+	for _, date := range NextChargeDates(sub.Billing, anchor, anchor, maxGeneratedCharges) {
+		if date.After(end) {
+			break
+		}
+		if statusOn(history, date) != domain.StatusActive {
+			continue
+		}
+		amount, currency := chargePrice(sub, date)
+		charges = append(charges, domain.Charge{
+			UserID:         userID,
+			SubscriptionID: sub.ID,
+			ChargeDate:     date,
+			Amount:         amount,
+			Currency:       currency,
+			Status:         domain.ChargeExpected,
+		})
+	}
+	if len(charges) == 0 {
+		return nil, nil
+	}
+	return u.Charges.CreateExpected(ctx, charges)
+}
+
+func (u ChargeUsecase) subscription(ctx context.Context, userID, subscriptionID string) (domain.Subscription, error) {
+	if strings.TrimSpace(userID) == "" {
+		return domain.Subscription{}, ErrUnauthorized
+	}
+	if strings.TrimSpace(subscriptionID) == "" {
+		return domain.Subscription{}, ErrInvalidInput
+	}
+	return u.Subscriptions.FindByID(ctx, userID, subscriptionID)
+}
+
+func toCharge(sub domain.Subscription, input ChargeInput) (domain.Charge, error) {
+	chargeDate, err := time.Parse("2006-01-02", strings.TrimSpace(input.ChargeDate))
+	if err != nil {
+		return domain.Charge{}, ErrInvalidInput
+	}
+
+	status := domain.ChargeStatus(strings.ToLower(strings.TrimSpace(input.Status)))
+	if status == "" {
+		status = domain.ChargePaid
+	}
+	switch status {
+	case domain.ChargePaid, domain.ChargeFailed, domain.ChargeRefunded:
+	default:
+		return domain.Charge{}, ErrInvalidInput
+	}
+
+	expectedAmount, expectedCurrency := chargePrice(sub, chargeDate)
+	currency := expectedCurrency
+	if strings.TrimSpace(input.Currency) != "" {
+		var ok bool
+		if currency, ok = NormalizeCurrency(input.Currency); !ok {
+			return domain.Charge{}, ErrInvalidInput
+		}
+	}
+
+	amount := expectedAmount
+	if strings.TrimSpace(input.Amount) != "" {
+		if amount, err = ParseAmount(input.Amount, currency); err != nil {
+			return domain.Charge{}, err
+		}
+	} else if currency != expectedCurrency {
+		return domain.Charge{}, ErrInvalidInput
+	}
+
+	return domain.Charge{
+		UserID:         sub.UserID,
+		SubscriptionID: sub.ID,
+		ChargeDate:     chargeDate,
+		Amount:         amount,
+		Currency:       currency,
+		Status:         status,
+		Note:           strings.TrimSpace(input.Note),
+	}, nil
+}
@@ -0,0 +1,123 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"subscribe_tracker/backend/internal/domain"
+)
+
+type fakeChargeRepository struct {
+	ChargeRepository
+	created []domain.Charge
+}
+
+func (r *fakeChargeRepository) CreateExpected(_ context.Context, charges []domain.Charge) ([]domain.Charge, error) {
+	r.created = append(r.created, charges...)
+	return charges, nil
+}
+
+// fakeStatusHistory serves one subscription and its status history.
+type fakeStatusHistory struct {
+	SubscriptionRepository
+	sub     domain.Subscription
+	history []domain.StatusChange
+}
+
+func (r fakeStatusHistory) FindByID(context.Context, string, string) (domain.Subscription, error) {
+	return r.sub, nil
+}
+
+func (r fakeStatusHistory) ListStatusHistory(context.Context, string, string) ([]domain.StatusChange, error) {
+	return r.history, nil
+}
+
+func TestGenerateExpected(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	sub := func(status domain.SubscriptionStatus, effective time.Time) domain.Subscription {
+		return domain.Subscription{
+			ID:                  "sub-1",
+			UserID:              "user-1",
+			Billing:             domain.BillingCycle{Unit: domain.BillingMonth, Interval: 1},
+			ChargeDate:          date(2025, 1, 15),
+			Price:               999,
+			Currency:            "EUR",
+			Status:              status,
+			StatusEffectiveDate: effective,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		sub     domain.Subscription
+		history []domain.StatusChange
+		want    []time.Time
+	}{
+		{
+			name: "always active",
+			sub:  sub(domain.StatusActive, date(2025, 1, 15)),
+			history: []domain.StatusChange{
+				{Status: domain.StatusActive, EffectiveDate: date(2025, 1, 15)},
+			},
+			want: []time.Time{date(2025, 1, 15), date(2025, 2, 15), date(2025, 3, 15), date(2025, 4, 15), date(2025, 5, 15), date(2025, 6, 15)},
+		},
+		{
+			name: "paused and resumed",
+			sub:  sub(domain.StatusActive, date(2025, 5, 1)),
+			history: []domain.StatusChange{
+				{Status: domain.StatusActive, EffectiveDate: date(2025, 1, 15)},
+				{Status: domain.StatusPaused, EffectiveDate: date(2025, 2, 20)},
+				{Status: domain.StatusActive, EffectiveDate: date(2025, 5, 1)},
+			},
+			want: []time.Time{date(2025, 1, 15), date(2025, 2, 15), date(2025, 5, 15), date(2025, 6, 15)},
+		},
+		{
+			name: "paused on a charge date",
+			sub:  sub(domain.StatusActive, date(2025, 4, 15)),
+			history: []domain.StatusChange{
+				{Status: domain.StatusActive, EffectiveDate: date(2025, 1, 15)},
+				{Status: domain.StatusPaused, EffectiveDate: date(2025, 3, 15)},
+				{Status: domain.StatusActive, EffectiveDate: date(2025, 4, 15)},
+			},
+			want: []time.Time{date(2025, 1, 15), date(2025, 2, 15), date(2025, 4, 15), date(2025, 5, 15), date(2025, 6, 15)},
+		},
+		{
+			name: "cancelled",
+			sub:  sub(domain.StatusCancelled, date(2025, 3, 1)),
+			history: []domain.StatusChange{
+				{Status: domain.StatusActive, EffectiveDate: date(2025, 1, 15)},
+				{Status: domain.StatusCancelled, EffectiveDate: date(2025, 3, 1)},
+			},
+			want: []time.Time{date(2025, 1, 15), date(2025, 2, 15)},
+		},
+		{
+			name: "no history",
+			sub:  sub(domain.StatusPaused, date(2025, 4, 1)),
+			want: []time.Time{date(2025, 1, 15), date(2025, 2, 15), date(2025, 3, 15)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			charges := &fakeChargeRepository{}
+			uc := NewChargeUsecase(charges, fakeStatusHistory{sub: tt.sub, history: tt.history})
+
+			if _, err := uc.GenerateExpected(context.Background(), "user-1", "sub-1", "2025-06-30"); err != nil {
+				t.Fatal(err)
+			}
+			if len(charges.created) != len(tt.want) {
+				t.Fatalf("generated %d charges, want %d: %v", len(charges.created), len(tt.want), charges.created)
+			}
+			for i, charge := range charges.created {
+				if !charge.ChargeDate.Equal(tt.want[i]) {
+					t.Errorf("charge %d on %s, want %s", i, charge.ChargeDate.Format("2006-01-02"), tt.want[i].Format("2006-01-02"))
+				}
+				if charge.Status != domain.ChargeExpected || charge.Amount != 999 {
+					t.Errorf("charge %d = %+v", i, charge)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,382 @@
+package usecase
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"subscribe_tracker/backend/internal/domain"
+)
+
+const (
+	maxImportRows = 1000
+	// tagSeparator joins tag names within a CSV cell.
+	tagSeparator = ";"
+)
+
+// exportColumns are the columns of exported files. Imports accept the same
+// headers, ignoring the derived next_charge_* ones.
+var exportColumns = []string{
+	"service_name", "bank_name", "card_last4",
+	"billing_cycle", "billing_unit", "billing_interval",
+	"charge_date", "price", "currency", "trial_end_date", "post_trial_price",
+	"status", "status_effective_date", "category", "tags",
+	"next_charge_date", "next_charge_amount", "next_charge_currency",
+}
+
+// importFields are the fields a CSV column can be mapped to.
+var importFields = map[string]bool{
+	"service_name": true, "payment_method_id": true, "bank_name": true, "card_last4": true,
+	"billing_cycle": true, "billing_unit": true, "billing_interval": true,
+	"charge_date": true, "price": true, "currency": true, "trial_end_date": true, "post_trial_price": true,
+	"status": true, "status_effective_date": true, "category": true, "tags": true,
+}
+
+// CSVUsecase exports subscriptions to CSV and imports them back.
+type CSVUsecase struct {
+	Subscriptions SubscriptionUsecase
+	Categories    CategoryRepository
+	Tags          TagRepository
+}
+
+func NewCSVUsecase(subscriptions SubscriptionUsecase, categories CategoryRepository, tags TagRepository) CSVUsecase {
+	return CSVUsecase{
+		Subscriptions: subscriptions,
+		Categories:    categories,
+		Tags:          tags,
+	}
+}
+
+// ImportOptions configure Import. Mapping maps fields to the CSV headers they
+// are read from; unmapped fields are read from the header of the same name.
+type ImportOptions struct {
+	Mapping map[string]string
+	DryRun  bool
+}
+
+// ImportRowError describes why a row cannot be imported. Row is the line
+// number in the file, the header being line 1.
+type ImportRowError struct {
+	Row     int
+	Field   string
+	Message string
+}
+
+type ImportResult struct {
+	Rows    int
+	DryRun  bool
+	Created []domain.Subscription
+	Errors  []ImportRowError
+}
+
+func (u CSVUsecase) Export(ctx context.Context, userID string, w io.Writer) error {
+	items, err := u.Subscriptions.List(ctx, userID, SubscriptionFilter{})
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(exportColumns); err != nil {
+		return err
+	}
+	for _, item := range items {
+		record := []string{
+			item.ServiceName, item.BankName, item.CardLast4,
+			BillingPresetName(item.Billing), string(item.Billing.Unit), strconv.Itoa(item.Billing.Interval),
+			item.ChargeDate.Format("2006-01-02"), FormatAmount(item.Price, item.Currency), item.Currency, "", "",
+			string(item.Status), item.StatusEffectiveDate.Format("2006-01-02"), "", "",
+			"", "", "",
+		}
+		if item.TrialEndDate != nil {
+			record[9] = item.TrialEndDate.Format("2006-01-02")
+			record[10] = FormatAmount(item.PostTrialPrice, item.Currency)
+		}
+		if item.Category != nil {
+			record[13] = item.Category.Name
+		}
+		names := make([]string, 0, len(item.Tags))
+		for _, tag := range item.Tags {
+			names = append(names, tag.Name)
+		}
+		record[14] = strings.Join(names, tagSeparator)
+		if !item.NextChargeDate.IsZero() {
+			record[15] = item.NextChargeDate.Format("2006-01-02")
+			record[16] = FormatAmount(item.NextChargeAmount, item.NextChargeCurrency)
+			record[17] = item.NextChargeCurrency
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// Import creates a subscription for every row of a CSV file, either all of
+// them or none. Rows that fail validation are reported in the result and
+// nothing is stored; with DryRun nothing is stored either way. Both comma and
+// semicolon separated files are accepted. Categories and tags are matched by
+// name or ID against existing ones.
+func (u CSVUsecase) Import(ctx context.Context, userID string, r io.Reader, options ImportOptions) (ImportResult, error) {
+	if strings.TrimSpace(userID) == "" {
+		return ImportResult{}, ErrUnauthorized
+	}
+
+	reader, err := csvReader(r)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	header, err := reader.Read()
+	if err != nil {
+		return ImportResult{}, invalidField("file")
+	}
+	columns, err := importColumns(header, options.Mapping)
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	categories, err := u.Categories.ListByUserID(ctx, userID)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	tags, err := u.Tags.ListByUserID(ctx, userID)
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	result := ImportResult{DryRun: options.DryRun}
+	var subs []domain.Subscription
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				result.Errors = append(result.Errors, ImportRowError{Row: parseErr.Line, Field: "file", Message: parseErr.Err.Error()})
+				break
+			}
+			return ImportResult{}, err
+		}
+		if blankRecord(record) {
+			continue
+		}
+		result.Rows++
+		if result.Rows > maxImportRows {
+			return ImportResult{}, invalidField("file")
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := importRow{columns: columns, record: record}
+		sub, field, err := u.importSubscription(userID, row, categories, tags)
+		if err != nil {
+			result.Errors = append(result.Errors, ImportRowError{Row: line, Field: field, Message: importMessage(err)})
+			continue
+		}
+		subs = append(subs, sub)
+	}
+
+	if len(result.Errors) > 0 || options.DryRun {
+		return result, nil
+	}
+	if err := u.Subscriptions.checkCanCreate(ctx, userID); err != nil {
+		return ImportResult{}, err
+	}
+	created, err := u.Subscriptions.Subscriptions.CreateMany(ctx, subs)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	now := today()
+	for i := range created {
+		created[i] = withNextCharge(created[i], now)
+		publish(ctx, u.Subscriptions.Events, userID, domain.EventSubscriptionCreated, subscriptionEvent(created[i]))
+	}
+	result.Created = created
+	return result, nil
+}
+
+// importSubscription builds a subscription from a row. On failure it returns
+// the field at fault.
+func (u CSVUsecase) importSubscription(userID string, row importRow, categories []domain.Category, tags []domain.Tag) (domain.Subscription, string, error) {
+	input := SubscriptionInput{
+		ServiceName:     row.get("service_name"),
+		PaymentMethodID: row.get("payment_method_id"),
+		BankName:        row.get("bank_name"),
+		CardLast4:       row.get("card_last4"),
+		Billing:         row.get("billing_cycle"),
+		BillingUnit:     row.get("billing_unit"),
+		ChargeDate:      row.get("charge_date"),
+		Price:           row.get("price"),
+		Currency:        row.get("currency"),
+		TrialEndDate:    row.get("trial_end_date"),
+		PostTrialPrice:  row.get("post_trial_price"),
+	}
+	// Exported files name the cycle "custom" when it has no preset; only
+	// billing_unit and billing_interval describe it then.
+	if strings.EqualFold(input.Billing, customBilling) {
+		if input.BillingUnit == "" {
+			return domain.Subscription{}, "billing_unit", ErrInvalidInput
+		}
+		input.Billing = ""
+	}
+	if value := row.get("billing_interval"); value != "" {
+		interval, err := strconv.Atoi(value)
+		if err != nil {
+			return domain.Subscription{}, "billing_interval", ErrInvalidInput
+		}
+		input.Interval = interval
+	}
+	if value := row.get("category"); value != "" {
+		found := false
+		for _, category := range categories {
+			if labelMatches(category.ID, category.Name, value) {
+				id := category.ID
+				input.CategoryID = &id
+				found = true
+				break
+			}
+		}
+		if !found {
+			return domain.Subscription{}, "category", ErrNotFound
+		}
+	}
+	for _, name := range strings.Split(row.get("tags"), tagSeparator) {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+		found := false
+		for _, tag := range tags {
+			if labelMatches(tag.ID, tag.Name, name) {
+				input.TagIDs = append(input.TagIDs, tag.ID)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return domain.Subscription{}, "tags", ErrNotFound
+		}
+	}
+
+	sub, err := u.Subscriptions.newSubscription(userID, input)
+	if err != nil {
+		var fieldErr FieldError
+		if errors.As(err, &fieldErr) {
+			return domain.Subscription{}, fieldErr.Field, err
+		}
+		return domain.Subscription{}, "", err
+	}
+
+	if value := row.get("status"); value != "" {
+		status := domain.SubscriptionStatus(strings.ToLower(value))
+		if !validStatus(status) {
+			return domain.Subscription{}, "status", ErrInvalidInput
+		}
+		if status != domain.StatusActive {
+			sub.Status = status
+			sub.StatusEffectiveDate = today()
+		}
+	}
+	if value := row.get("status_effective_date"); value != "" && sub.Status != domain.StatusActive {
+		effective, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return domain.Subscription{}, "status_effective_date", ErrInvalidInput
+		}
+		sub.StatusEffectiveDate = effective
+	}
+	return sub, "", nil
+}
+
+type importRow struct {
+	columns map[string]int
+	record  []string
+}
+
+func (r importRow) get(field string) string {
+	i, ok := r.columns[field]
+	if !ok || i >= len(r.record) {
+		return ""
+	}
+	return strings.TrimSpace(r.record[i])
+}
+
+// importColumns maps fields to their column index in header.
+func importColumns(header []string, mapping map[string]string) (map[string]int, error) {
+	index := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := index[name]; !ok {
+			index[name] = i
+		}
+	}
+
+	columns := map[string]int{}
+	for field := range importFields {
+		if i, ok := index[field]; ok {
+			columns[field] = i
+		}
+	}
+	for field, name := range mapping {
+		if !importFields[field] {
+			return nil, invalidField("mapping")
+		}
+		i, ok := index[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return nil, invalidField("mapping")
+		}
+		columns[field] = i
+	}
+	if _, ok := columns["service_name"]; !ok {
+		return nil, invalidField("mapping")
+	}
+	return columns, nil
+}
+
+// csvReader strips a UTF-8 byte order mark and picks the separator the
+// header line uses the most.
+func csvReader(r io.Reader) (*csv.Reader, error) {
+	buffered := bufio.NewReader(r)
+	if bom, err := buffered.Peek(3); err == nil && bytes.Equal(bom, []byte("\xef\xbb\xbf")) {
+		_, _ = buffered.Discard(3)
+	}
+	first, err := buffered.Peek(buffered.Size())
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, err
+	}
+	if i := bytes.IndexByte(first, '\n'); i >= 0 {
+		first = first[:i]
+	}
+
+	reader := csv.NewReader(buffered)
+	if bytes.Count(first, []byte(";")) > bytes.Count(first, []byte(",")) {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	return reader, nil
+}
+
+func blankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func importMessage(err error) string {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return "not found"
+	case errors.Is(err, ErrInvalidInput):
+		return "invalid value"
+	default:
+		return err.Error()
+	}
+}
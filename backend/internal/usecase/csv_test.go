@@ -0,0 +1,297 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"subscribe_tracker/backend/internal/domain"
+)
+
+type fakeCategories struct {
+	CategoryRepository
+}
+
+func (fakeCategories) ListByUserID(context.Context, string) ([]domain.Category, error) {
+	return []domain.Category{{ID: "cat-1", UserID: "user-1", Name: "Video"}}, nil
+}
+
+type fakeTags struct {
+	TagRepository
+}
+
+func (fakeTags) ListByUserID(context.Context, string) ([]domain.Tag, error) {
+	return []domain.Tag{
+		{ID: "tag-1", UserID: "user-1", Name: "family"},
+		{ID: "tag-2", UserID: "user-1", Name: "work"},
+	}, nil
+}
+
+func newTestCSVUsecase(repo *fakeSubscriptionRepository) CSVUsecase {
+	return NewCSVUsecase(SubscriptionUsecase{Subscriptions: repo}, fakeCategories{}, fakeTags{})
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	trialEnd := date(2025, 2, 1)
+	subs := []domain.Subscription{
+		{
+			ServiceName: "Netflix", BankName: "Monzo", CardLast4: "4242",
+			Billing:    domain.BillingCycle{Unit: domain.BillingMonth, Interval: 1},
+			ChargeDate: date(2025, 1, 10), Price: 999, Currency: "EUR",
+			Status: domain.StatusActive, StatusEffectiveDate: date(2025, 1, 10),
+			Category: &domain.Category{ID: "cat-1", Name: "Video"},
+			Tags:     []domain.Tag{{ID: "tag-1", Name: "family"}, {ID: "tag-2", Name: "work"}},
+		},
+		{
+			ServiceName: "Gym", BankName: "Revolut", CardLast4: "0001",
+			Billing:    domain.BillingCycle{Unit: domain.BillingDay, Interval: 10},
+			ChargeDate: date(2025, 3, 1), Price: 2500, Currency: "USD",
+			Status: domain.StatusActive, StatusEffectiveDate: date(2025, 3, 1),
+		},
+		{
+			ServiceName: "Spotify", BankName: "Monzo", CardLast4: "4242",
+			Billing:    domain.BillingCycle{Unit: domain.BillingMonth, Interval: 3},
+			ChargeDate: date(2025, 1, 5), Price: 1500, Currency: "EUR",
+			Status: domain.StatusPaused, StatusEffectiveDate: date(2025, 6, 1),
+		},
+		{
+			ServiceName: "Duolingo", BankName: "Monzo", CardLast4: "4242",
+			Billing:    domain.BillingCycle{Unit: domain.BillingYear, Interval: 1},
+			ChargeDate: date(2025, 1, 1), Price: 0, Currency: "JPY",
+			TrialEndDate: &trialEnd, PostTrialPrice: 8400,
+			Status: domain.StatusActive, StatusEffectiveDate: date(2025, 1, 1),
+		},
+	}
+
+	var file bytes.Buffer
+	exporter := newTestCSVUsecase(&fakeSubscriptionRepository{created: subs})
+	if err := exporter.Export(context.Background(), "user-1", &file); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(file.String(), "Gym,Revolut,0001,custom,day,10,") {
+		t.Fatalf("export does not name the custom cycle:\n%s", file.String())
+	}
+
+	repo := &fakeSubscriptionRepository{}
+	result, err := newTestCSVUsecase(repo).Import(context.Background(), "user-1", &file, ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Errors) > 0 || result.Rows != len(subs) || len(result.Created) != len(subs) {
+		t.Fatalf("result = %+v", result)
+	}
+
+	imported := map[string]domain.Subscription{}
+	for _, sub := range repo.created {
+		imported[sub.ServiceName] = sub
+	}
+	for _, want := range subs {
+		got, ok := imported[want.ServiceName]
+		if !ok {
+			t.Errorf("%s was not imported", want.ServiceName)
+			continue
+		}
+		if got.BankName != want.BankName || got.CardLast4 != want.CardLast4 ||
+			got.Billing != want.Billing || !got.ChargeDate.Equal(want.ChargeDate) ||
+			got.Price != want.Price || got.Currency != want.Currency || got.Status != want.Status ||
+			got.PostTrialPrice != want.PostTrialPrice || !reflect.DeepEqual(got.TrialEndDate, want.TrialEndDate) {
+			t.Errorf("%s imported as %+v", want.ServiceName, got)
+		}
+		if want.Status != domain.StatusActive && !got.StatusEffectiveDate.Equal(want.StatusEffectiveDate) {
+			t.Errorf("%s effective %s, want %s", want.ServiceName, got.StatusEffectiveDate, want.StatusEffectiveDate)
+		}
+		if (got.Category == nil) != (want.Category == nil) || (got.Category != nil && got.Category.ID != want.Category.ID) {
+			t.Errorf("%s category = %+v, want %+v", want.ServiceName, got.Category, want.Category)
+		}
+		if len(got.Tags) != len(want.Tags) {
+			t.Errorf("%s tags = %+v, want %+v", want.ServiceName, got.Tags, want.Tags)
+		}
+		for i := range got.Tags {
+			if i < len(want.Tags) && got.Tags[i].ID != want.Tags[i].ID {
+				t.Errorf("%s tags = %+v, want %+v", want.ServiceName, got.Tags, want.Tags)
+			}
+		}
+	}
+}
+
+func TestCSVImport(t *testing.T) {
+	const header = "service_name,bank_name,card_last4,billing_cycle,charge_date,price,currency\n"
+
+	tests := []struct {
+		name    string
+		file    string
+		options ImportOptions
+		// wantNames are the subscriptions created, in order.
+		wantNames  []string
+		wantRows   int
+		wantErrors []ImportRowError
+		wantErr    string
+	}{
+		{
+			name:      "comma separated",
+			file:      header + "Netflix,Monzo,4242,monthly,2025-01-10,9.99,EUR\n",
+			wantNames: []string{"Netflix"},
+			wantRows:  1,
+		},
+		{
+			name:      "semicolon separated with byte order mark",
+			file:      "\xef\xbb\xbfservice_name;bank_name;card_last4;billing_cycle;charge_date;price;currency\nNetflix;Monzo;4242;monthly;2025-01-10;9,99;EUR\n",
+			wantNames: []string{"Netflix"},
+			wantRows:  1,
+		},
+		{
+			name:      "semicolons within comma separated tags",
+			file:      "service_name,bank_name,card_last4,billing_cycle,charge_date,tags\nNetflix,Monzo,4242,monthly,2025-01-10,family;work\n",
+			wantNames: []string{"Netflix"},
+			wantRows:  1,
+		},
+		{
+			name:      "headers in any case and order, blank lines skipped",
+			file:      "Currency, Price ,SERVICE_NAME,charge_date,billing_cycle,card_last4,bank_name\nEUR,9.99,Netflix,2025-01-10,monthly,4242,Monzo\n\n,,,,,,\nUSD,5,Hulu,2025-01-11,yearly,4242,Monzo\n",
+			wantNames: []string{"Netflix", "Hulu"},
+			wantRows:  2,
+		},
+		{
+			name: "mapped headers",
+			file: "Name,Bank,Card,Cycle,Date,Amount\nNetflix,Monzo,4242,monthly,2025-01-10,9.99\n",
+			options: ImportOptions{Mapping: map[string]string{
+				"service_name": "Name", "bank_name": "bank", "card_last4": "Card",
+				"billing_cycle": "Cycle", "charge_date": "Date", "price": "Amount",
+			}},
+			wantNames: []string{"Netflix"},
+			wantRows:  1,
+		},
+		{
+			name:    "mapping to an unknown field",
+			file:    header,
+			options: ImportOptions{Mapping: map[string]string{"colour": "service_name"}},
+			wantErr: "mapping",
+		},
+		{
+			name:    "mapping to a missing header",
+			file:    header,
+			options: ImportOptions{Mapping: map[string]string{"price": "Amount"}},
+			wantErr: "mapping",
+		},
+		{
+			name:    "no service name column",
+			file:    "bank_name,card_last4\nMonzo,4242\n",
+			wantErr: "mapping",
+		},
+		{
+			name:    "empty file",
+			file:    "",
+			wantErr: "file",
+		},
+		{
+			name: "row errors name the line",
+			file: header +
+				"Netflix,Monzo,4242,monthly,2025-01-10,9.99,EUR\n" +
+				"\"Spotify\nFamily\",Monzo,4242,monthly,2025-13-01,9.99,EUR\n" +
+				"Hulu,Monzo,42a2,monthly,2025-01-10,9.99,EUR\n",
+			wantRows: 3,
+			wantErrors: []ImportRowError{
+				{Row: 3, Field: "charge_date", Message: "invalid value"},
+				{Row: 5, Field: "card_last4", Message: "invalid value"},
+			},
+		},
+		{
+			name:       "unknown category",
+			file:       "service_name,bank_name,card_last4,billing_cycle,charge_date,category\nNetflix,Monzo,4242,monthly,2025-01-10,Music\n",
+			wantRows:   1,
+			wantErrors: []ImportRowError{{Row: 2, Field: "category", Message: "not found"}},
+		},
+		{
+			name:       "custom cycle without a unit",
+			file:       header + "Gym,Monzo,4242,custom,2025-01-10,25,EUR\n",
+			wantRows:   1,
+			wantErrors: []ImportRowError{{Row: 2, Field: "billing_unit", Message: "invalid value"}},
+		},
+		{
+			name:     "dry run",
+			file:     header + "Netflix,Monzo,4242,monthly,2025-01-10,9.99,EUR\n",
+			options:  ImportOptions{DryRun: true},
+			wantRows: 1,
+		},
+		{
+			name:      "row limit",
+			file:      header + strings.Repeat("Netflix,Monzo,4242,monthly,2025-01-10,9.99,EUR\n", maxImportRows),
+			wantNames: repeat("Netflix", maxImportRows),
+			wantRows:  maxImportRows,
+		},
+		{
+			name:    "over the row limit",
+			file:    header + strings.Repeat("Netflix,Monzo,4242,monthly,2025-01-10,9.99,EUR\n", maxImportRows+1),
+			wantErr: "file",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeSubscriptionRepository{}
+			result, err := newTestCSVUsecase(repo).Import(context.Background(), "user-1", strings.NewReader(tt.file), tt.options)
+			if tt.wantErr != "" {
+				var fieldErr FieldError
+				if !errors.As(err, &fieldErr) || fieldErr.Field != tt.wantErr {
+					t.Fatalf("err = %v, want invalid %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Rows != tt.wantRows || result.DryRun != tt.options.DryRun {
+				t.Errorf("rows = %d, dry run = %t", result.Rows, result.DryRun)
+			}
+			if !reflect.DeepEqual(result.Errors, tt.wantErrors) {
+				t.Errorf("errors = %+v, want %+v", result.Errors, tt.wantErrors)
+			}
+			var names []string
+			for _, sub := range repo.created {
+				names = append(names, sub.ServiceName)
+			}
+			if !reflect.DeepEqual(names, tt.wantNames) {
+				t.Errorf("created %v, want %v", names, tt.wantNames)
+			}
+			if len(result.Created) != len(tt.wantNames) {
+				t.Errorf("result lists %d created, want %d", len(result.Created), len(tt.wantNames))
+			}
+		})
+	}
+}
+
+func repeat(value string, n int) []string {
+	values := make([]string, n)
+	for i := range values {
+		values[i] = value
+	}
+	return values
+}
+
+func TestCSVImportStatus(t *testing.T) {
+	file := "service_name,bank_name,card_last4,billing_cycle,charge_date,status,status_effective_date\n" +
+		"Spotify,Monzo,4242,monthly,2025-01-05,Paused,2025-06-01\n" +
+		"Netflix,Monzo,4242,monthly,2025-01-10,active,2025-06-01\n"
+
+	repo := &fakeSubscriptionRepository{}
+	if _, err := newTestCSVUsecase(repo).Import(context.Background(), "user-1", strings.NewReader(file), ImportOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.created) != 2 {
+		t.Fatalf("created %d subscriptions", len(repo.created))
+	}
+	paused, active := repo.created[0], repo.created[1]
+	if paused.Status != domain.StatusPaused || paused.StatusEffectiveDate.Format("2006-01-02") != "2025-06-01" {
+		t.Errorf("paused = %s from %s", paused.Status, paused.StatusEffectiveDate)
+	}
+	// An active row starts at its first charge whatever the file says.
+	if active.Status != domain.StatusActive || !active.StatusEffectiveDate.Equal(active.ChargeDate) {
+		t.Errorf("active = %s from %s", active.Status, active.StatusEffectiveDate)
+	}
+}
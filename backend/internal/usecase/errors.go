@@ -1,10 +1,55 @@
 package usecase
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 var (
 	ErrInvalidInput = errors.New("invalid input")
 	ErrUnauthorized = errors.New("unauthorized")
 	ErrNotFound     = errors.New("not found")
 	ErrEmailExists  = errors.New("email exists")
+	ErrNameExists   = errors.New("name exists")
+	ErrInUse        = errors.New("in use")
+
+	ErrInvalidTransition = errors.New("invalid status transition")
+	// ErrEmailNotVerified is returned when the policy requires a verified
+	// email for the action.
+	ErrEmailNotVerified = errors.New("email not verified")
+	ErrTwoFactorEnabled = errors.New("two-factor authentication enabled")
+	ErrTooManyAttempts  = errors.New("too many attempts")
+	// ErrNoRecipient is returned by a Notifier that has no way to reach the
+	// user, e.g. no linked chat.
+	ErrNoRecipient = errors.New("no recipient")
 )
+
+// FieldError is an ErrInvalidInput that names the offending input field.
+type FieldError struct {
+	Field string
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + ErrInvalidInput.Error()
+}
+
+func (e FieldError) Unwrap() error {
+	return ErrInvalidInput
+}
+
+func invalidField(field string) error {
+	return FieldError{Field: field}
+}
+
+// RetryError is an ErrTooManyAttempts that says when to try again.
+type RetryError struct {
+	RetryAfter time.Duration
+}
+
+func (e RetryError) Error() string {
+	return ErrTooManyAttempts.Error() + ", retry after " + e.RetryAfter.String()
+}
+
+func (e RetryError) Unwrap() error {
+	return ErrTooManyAttempts
+}
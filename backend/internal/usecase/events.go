@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"subscribe_tracker/backend/internal/domain"
+)
+
+// SubscriptionEvent is the data of subscription.* events.
+type SubscriptionEvent struct {
+	ID                  string   `json:"id"`
+	ServiceName         string   `json:"service_name"`
+	PaymentMethodID     string   `json:"payment_method_id"`
+	BankName            string   `json:"bank_name"`
+	CardLast4           string   `json:"card_last4"`
+	BillingUnit         string   `json:"billing_unit"`
+	BillingInterval     int      `json:"billing_interval"`
+	ChargeDate          string   `json:"charge_date"`
+	Price               string   `json:"price"`
+	PriceMinor          int64    `json:"price_minor"`
+	Currency            string   `json:"currency"`
+	Status              string   `json:"status"`
+	StatusEffectiveDate string   `json:"status_effective_date"`
+	NextChargeDate      string   `json:"next_charge_date,omitempty"`
+	NextChargeAmount    string   `json:"next_charge_amount,omitempty"`
+	Category            string   `json:"category,omitempty"`
+	Tags                []string `json:"tags"`
+}
+
+// ChargeEvent is the data of charge.upcoming events.
+type ChargeEvent struct {
+	SubscriptionID string `json:"subscription_id"`
+	ServiceName    string `json:"service_name"`
+	ChargeDate     string `json:"charge_date"`
+	Amount         string `json:"amount"`
+	AmountMinor    int64  `json:"amount_minor"`
+	Currency       string `json:"currency"`
+}
+
+func subscriptionEvent(sub domain.Subscription) SubscriptionEvent {
+	event := SubscriptionEvent{
+		ID:                  sub.ID,
+		ServiceName:         sub.ServiceName,
+		PaymentMethodID:     sub.PaymentMethodID,
+		BankName:            sub.BankName,
+		CardLast4:           sub.CardLast4,
+		BillingUnit:         string(sub.Billing.Unit),
+		BillingInterval:     sub.Billing.Interval,
+		ChargeDate:          sub.ChargeDate.Format("2006-01-02"),
+		Price:               FormatAmount(sub.Price, sub.Currency),
+		PriceMinor:          sub.Price,
+		Currency:            sub.Currency,
+		Status:              string(sub.Status),
+		StatusEffectiveDate: sub.StatusEffectiveDate.Format("2006-01-02"),
+		Tags:                make([]string, 0, len(sub.Tags)),
+	}
+	if !sub.NextChargeDate.IsZero() {
+		event.NextChargeDate = sub.NextChargeDate.Format("2006-01-02")
+		event.NextChargeAmount = FormatAmount(sub.NextChargeAmount, sub.NextChargeCurrency)
+	}
+	if sub.Category != nil {
+		event.Category = sub.Category.Name
+	}
+	for _, tag := range sub.Tags {
+		event.Tags = append(event.Tags, tag.Name)
+	}
+	return event
+}
+
+func chargeEvent(reminder domain.Reminder) ChargeEvent {
+	return ChargeEvent{
+		SubscriptionID: reminder.SubscriptionID,
+		ServiceName:    reminder.ServiceName,
+		ChargeDate:     reminder.ChargeDate.Format("2006-01-02"),
+		Amount:         FormatAmount(reminder.Amount, reminder.Currency),
+		AmountMinor:    reminder.Amount,
+		Currency:       reminder.Currency,
+	}
+}
+
+// Publishers hands every event to all of its publishers. All of them are
+// tried; the errors are joined.
+type Publishers []EventPublisher
+
+func (p Publishers) Publish(ctx context.Context, userID, event string, data any) error {
+	var errs []error
+	for _, publisher := range p {
+		if err := publisher.Publish(ctx, userID, event, data); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// publish hands the event to events, if any. A failure is only logged: the
+// change it describes has already been committed.
+func publish(ctx context.Context, events EventPublisher, userID, event string, data any) {
+	if events == nil {
+		return
+	}
+	if err := events.Publish(ctx, userID, event, data); err != nil {
+		log.Printf("publish %s: %v", event, err)
+	}
+}
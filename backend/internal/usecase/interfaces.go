@@ -2,6 +2,8 @@ package usecase
 
 import (
 	"context"
+	"net/http"
+	"time"
 
 	"subscribe_tracker/backend/internal/domain"
 )
@@ -9,16 +11,251 @@ import (
 type UserRepository interface {
 	Create(ctx context.Context, name, email, passwordHash string) (domain.User, error)
 	FindByEmail(ctx context.Context, email string) (domain.User, error)
+	FindByID(ctx context.Context, id string) (domain.User, error)
+	List(ctx context.Context) ([]domain.User, error)
+	UpdateNotificationSettings(ctx context.Context, id string, leadDays int, locale string) (domain.User, error)
+	UpdateBaseCurrency(ctx context.Context, id, currency string) (domain.User, error)
+	UpdatePassword(ctx context.Context, id, passwordHash string) error
+	MarkEmailVerified(ctx context.Context, id string) (domain.User, error)
 }
 
 type SubscriptionRepository interface {
 	ListByUserID(ctx context.Context, userID string) ([]domain.Subscription, error)
+	FindByID(ctx context.Context, userID, id string) (domain.Subscription, error)
 	Create(ctx context.Context, sub domain.Subscription) (domain.Subscription, error)
+	// CreateMany creates all subscriptions in a single transaction; nothing
+	// is stored if any of them fails.
+	CreateMany(ctx context.Context, subs []domain.Subscription) ([]domain.Subscription, error)
 	Update(ctx context.Context, sub domain.Subscription) (domain.Subscription, error)
+	// UpdateStatus moves the subscription from the status from to sub.Status
+	// and records the change in its history. It returns ErrInvalidTransition
+	// when the stored status is no longer from.
+	UpdateStatus(ctx context.Context, sub domain.Subscription, from domain.SubscriptionStatus) (domain.Subscription, error)
+	// ListStatusHistory returns the recorded status changes of the
+	// subscription in the order they took effect.
+	ListStatusHistory(ctx context.Context, userID, id string) ([]domain.StatusChange, error)
+	// UpdateReminderLeadDays sets the lead time override; nil falls back to
+	// the lead time of the user.
+	UpdateReminderLeadDays(ctx context.Context, userID, id string, days *int) (domain.Subscription, error)
 	Delete(ctx context.Context, userID, id string) error
 }
 
+type PaymentMethodRepository interface {
+	ListByUserID(ctx context.Context, userID string) ([]domain.PaymentMethod, error)
+	Create(ctx context.Context, method domain.PaymentMethod) (domain.PaymentMethod, error)
+	Update(ctx context.Context, method domain.PaymentMethod) (domain.PaymentMethod, error)
+	// Delete returns ErrInUse while subscriptions are charged to the method.
+	Delete(ctx context.Context, userID, id string) error
+	// Reassign moves every subscription of the user from one method to
+	// another in a single transaction and returns the IDs of those moved.
+	Reassign(ctx context.Context, userID, fromID, toID string) ([]string, error)
+}
+
+type CategoryRepository interface {
+	ListByUserID(ctx context.Context, userID string) ([]domain.Category, error)
+	Create(ctx context.Context, category domain.Category) (domain.Category, error)
+	Update(ctx context.Context, category domain.Category) (domain.Category, error)
+	Delete(ctx context.Context, userID, id string) error
+}
+
+type TagRepository interface {
+	ListByUserID(ctx context.Context, userID string) ([]domain.Tag, error)
+	Create(ctx context.Context, tag domain.Tag) (domain.Tag, error)
+	Update(ctx context.Context, tag domain.Tag) (domain.Tag, error)
+	Delete(ctx context.Context, userID, id string) error
+}
+
+type ChargeRepository interface {
+	ListBySubscription(ctx context.Context, userID, subscriptionID string) ([]domain.Charge, error)
+	Create(ctx context.Context, charge domain.Charge) (domain.Charge, error)
+	Update(ctx context.Context, charge domain.Charge) (domain.Charge, error)
+	// CreateExpected inserts the charges whose date is not yet in the ledger
+	// of their subscription and returns the inserted ones. Concurrent calls
+	// do not insert the same expected charge twice.
+	CreateExpected(ctx context.Context, charges []domain.Charge) ([]domain.Charge, error)
+}
+
+type ReminderRepository interface {
+	ListByUserID(ctx context.Context, userID string) ([]domain.Reminder, error)
+	// Sync makes reminders the pending reminders of the user: pending ones
+	// missing from it are dropped, the rest are inserted or updated. Sent and
+	// failed reminders are kept as they are.
+	Sync(ctx context.Context, userID string, reminders []domain.Reminder) error
+	// ClaimDue locks up to limit due pending reminders, skipping ones locked
+	// by other workers, and leases them for the given duration so they are
+	// picked up again if the claimer dies before reporting back.
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]domain.Reminder, error)
+	MarkSent(ctx context.Context, id string) error
+	// MarkFailed schedules another attempt at retryAt, or gives up on the
+	// reminder when retryAt is nil.
+	MarkFailed(ctx context.Context, id string, retryAt *time.Time, reason string) error
+}
+
+// Notifier delivers a due reminder to its user, e.g. by email.
+type Notifier interface {
+	Notify(ctx context.Context, user domain.User, reminder domain.Reminder) error
+}
+
+// AccountSender delivers emails about the account itself. link is the page
+// of the frontend the user should open.
+type AccountSender interface {
+	SendPasswordReset(ctx context.Context, user domain.User, link string, expiresAt time.Time) error
+	SendEmailVerification(ctx context.Context, user domain.User, link string, expiresAt time.Time) error
+}
+
+// StatementParser reads a bank statement file, detecting its format.
+type StatementParser interface {
+	Parse(data []byte) (domain.Statement, error)
+}
+
+type WebhookRepository interface {
+	ListByUserID(ctx context.Context, userID string) ([]domain.Webhook, error)
+	Create(ctx context.Context, webhook domain.Webhook) (domain.Webhook, error)
+	Update(ctx context.Context, webhook domain.Webhook) (domain.Webhook, error)
+	Delete(ctx context.Context, userID, id string) error
+	// Enqueue adds a pending delivery of the event for every active webhook
+	// of the user subscribed to it.
+	Enqueue(ctx context.Context, userID, event string, payload []byte) error
+	ListDeliveries(ctx context.Context, userID, webhookID string) ([]domain.WebhookDelivery, error)
+	// Redeliver queues a copy of a past delivery and returns it.
+	Redeliver(ctx context.Context, userID, webhookID, deliveryID string) (domain.WebhookDelivery, error)
+	// ClaimDue locks and leases up to limit due pending deliveries the same
+	// way ReminderRepository.ClaimDue does. Deliveries of disabled webhooks
+	// are skipped; they stay pending until the webhook is enabled again.
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]domain.WebhookDelivery, error)
+	MarkDelivered(ctx context.Context, id string, responseStatus int) error
+	// MarkFailed schedules another attempt at retryAt, or gives up on the
+	// delivery when retryAt is nil. responseStatus is zero when no response
+	// was received.
+	MarkFailed(ctx context.Context, id string, responseStatus int, retryAt *time.Time, reason string) error
+}
+
+type TelegramRepository interface {
+	CreateLinkCode(ctx context.Context, userID, codeHash string, expiresAt time.Time) error
+	// ConsumeLinkCode marks an unused, unexpired code as used and links the
+	// chat to its user, unlinking the chat from anyone else. It returns
+	// ErrNotFound for unknown, used or expired codes.
+	ConsumeLinkCode(ctx context.Context, codeHash string, chatID int64) (string, error)
+	FindUserIDByChat(ctx context.Context, chatID int64) (string, error)
+	FindChatByUserID(ctx context.Context, userID string) (int64, error)
+	Unlink(ctx context.Context, userID string) error
+}
+
+type CalendarRepository interface {
+	FindFeed(ctx context.Context, userID string) (time.Time, error)
+	// SetFeed replaces the user's feed token and returns when it was created.
+	SetFeed(ctx context.Context, userID, tokenHash string) (time.Time, error)
+	FindUserIDByFeed(ctx context.Context, tokenHash string) (string, error)
+	DeleteFeed(ctx context.Context, userID string) error
+}
+
+type RateRepository interface {
+	// Save inserts the rates, replacing stored ones for the same pair and
+	// date.
+	Save(ctx context.Context, rates []domain.Rate) error
+	// Latest returns the most recent rate of every stored pair.
+	Latest(ctx context.Context) ([]domain.Rate, error)
+}
+
+// RateProvider fetches current exchange rates from one source.
+type RateProvider interface {
+	Rates(ctx context.Context) ([]domain.Rate, error)
+}
+
+// EventPublisher is told about changes other systems may want to follow.
+// data is encoded as JSON.
+type EventPublisher interface {
+	Publish(ctx context.Context, userID, event string, data any) error
+}
+
+// HTTPClient sends outgoing requests; *http.Client implements it.
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+type SessionRepository interface {
+	// Create stores the first token of a new family.
+	Create(ctx context.Context, session domain.Session) (domain.Session, error)
+	FindByToken(ctx context.Context, tokenHash string) (domain.Session, error)
+	// Rotate marks the token with the given id as replaced and stores next in
+	// its family. It returns ErrNotFound when the token is no longer active,
+	// e.g. because a concurrent refresh replaced it first.
+	Rotate(ctx context.Context, id string, next domain.Session) (domain.Session, error)
+	// ListActive returns the current token of every live family of the user.
+	ListActive(ctx context.Context, userID string) ([]domain.Session, error)
+	RevokeFamily(ctx context.Context, userID, familyID string) error
+	RevokeAll(ctx context.Context, userID string) error
+}
+
+type UserTokenRepository interface {
+	// Create stores a token for purpose, replacing unused tokens the user
+	// has for it.
+	Create(ctx context.Context, userID, purpose, tokenHash string, expiresAt time.Time) error
+	// Consume marks an unused, unexpired token as used and returns its user.
+	// It returns ErrNotFound for unknown, used or expired tokens.
+	Consume(ctx context.Context, purpose, tokenHash string) (string, error)
+	// Find returns the user of an unused, unexpired token without using it.
+	Find(ctx context.Context, purpose, tokenHash string) (string, error)
+	// RecordFailure counts a failed attempt at the token and uses it up once
+	// maxAttempts attempts failed.
+	RecordFailure(ctx context.Context, purpose, tokenHash string, maxAttempts int) error
+}
+
+type TwoFactorRepository interface {
+	Find(ctx context.Context, userID string) (domain.TwoFactor, error)
+	// SetPending stores a new unconfirmed secret, replacing an unconfirmed
+	// one. It returns ErrTwoFactorEnabled when a confirmed secret exists.
+	SetPending(ctx context.Context, userID, secret string) error
+	// Confirm enables the pending secret with the step of its first code and
+	// stores the recovery codes.
+	Confirm(ctx context.Context, userID string, step int64, codeHashes []string) error
+	// UseStep records a code of step as used. It returns ErrNotFound when a
+	// code of the same or a later step was used already.
+	UseStep(ctx context.Context, userID string, step int64) error
+	UseRecoveryCode(ctx context.Context, userID, codeHash string) error
+	ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error
+	Delete(ctx context.Context, userID string) error
+}
+
+// LoginAttemptStore tracks failed logins. Every API replica has to use the
+// same store for the limits to hold across them.
+type LoginAttemptStore interface {
+	// Find returns the attempts of the subject; unknown subjects have none.
+	Find(ctx context.Context, scope domain.LoginScope, subject string) (domain.LoginAttempts, error)
+	// RecordFailure counts a failed login, starting the count over when the
+	// last failure is older than window, and returns the updated attempts.
+	RecordFailure(ctx context.Context, scope domain.LoginScope, subject string, window time.Duration) (domain.LoginAttempts, error)
+	// Lock locks the subject of lockout out until lockout.LockedUntil, clears
+	// its failures and keeps lockout as an audit record.
+	Lock(ctx context.Context, lockout domain.LoginLockout) error
+	// Reset forgets the failures of the subject.
+	Reset(ctx context.Context, scope domain.LoginScope, subject string) error
+}
+
+type PersonalTokenRepository interface {
+	ListByUserID(ctx context.Context, userID string) ([]domain.PersonalToken, error)
+	FindByID(ctx context.Context, userID, id string) (domain.PersonalToken, error)
+	// FindByHash looks a token up by its hash, whoever it belongs to.
+	FindByHash(ctx context.Context, tokenHash string) (domain.PersonalToken, error)
+	// Create and Update return ErrNameExists when the user has another token
+	// with the name.
+	Create(ctx context.Context, token domain.PersonalToken) (domain.PersonalToken, error)
+	// Update changes the name and scopes of the token.
+	Update(ctx context.Context, token domain.PersonalToken) (domain.PersonalToken, error)
+	Delete(ctx context.Context, userID, id string) error
+	MarkUsed(ctx context.Context, id string) error
+}
+
+// AccessToken is what a valid access token was issued for.
+type AccessToken struct {
+	UserID    string
+	SessionID string
+	// Scopes limit what a personal access token may do; they are nil for
+	// tokens of a signed-in session, which may do anything.
+	Scopes []string
+}
+
 type TokenManager interface {
-	Sign(userID, email string) (string, error)
-	Parse(token string) (string, error)
+	Sign(userID, email, sessionID string) (string, error)
+	Parse(token string) (AccessToken, error)
 }
@@ -0,0 +1,22 @@
+package usecase
+
+import "strings"
+
+// DefaultLocale is the language of notifications unless the user picks
+// another one.
+const DefaultLocale = "ru"
+
+var supportedLocales = map[string]bool{
+	"ru": true,
+	"en": true,
+}
+
+// NormalizeLocale lowercases a language code such as "EN" or "en-US" to a
+// supported locale and reports whether it is supported.
+func NormalizeLocale(value string) (string, bool) {
+	locale := strings.ToLower(strings.TrimSpace(value))
+	if i := strings.IndexAny(locale, "-_"); i > 0 {
+		locale = locale[:i]
+	}
+	return locale, supportedLocales[locale]
+}
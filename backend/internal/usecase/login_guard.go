@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"subscribe_tracker/backend/internal/domain"
+)
+
+// LoginLimits configures LoginGuard.
+type LoginLimits struct {
+	// AccountFailures and IPFailures are how many failed logins within
+	// Window lock an account or a client address out for Lockout. Zero
+	// turns the limit off.
+	AccountFailures int
+	IPFailures      int
+	Window          time.Duration
+	Lockout         time.Duration
+	// FreeFailures failed logins in a row are let through right away; after
+	// that the next attempt has to wait Delay, doubling with every further
+	// failure up to MaxDelay.
+	FreeFailures int
+	Delay        time.Duration
+	MaxDelay     time.Duration
+}
+
+// LoginGuard slows down and locks out repeated failed logins, both for the
+// email that was tried and for the address they come from. A guard without
+// a store lets every attempt through.
+type LoginGuard struct {
+	Attempts LoginAttemptStore
+	Limits   LoginLimits
+}
+
+func NewLoginGuard(attempts LoginAttemptStore, limits LoginLimits) LoginGuard {
+	return LoginGuard{Attempts: attempts, Limits: limits}
+}
+
+// check returns a RetryError while the email or the client is locked out or
+// has to wait after its last failure.
+func (g LoginGuard) check(ctx context.Context, email string, client ClientInfo) error {
+	if g.Attempts == nil {
+		return nil
+	}
+	now := time.Now()
+	var wait time.Duration
+	for _, subject := range g.subjects(email, client) {
+		attempts, err := g.Attempts.Find(ctx, subject.scope, subject.value)
+		if err != nil {
+			return err
+		}
+		if attempts.LockedUntil != nil && attempts.LockedUntil.After(now) {
+			wait = max(wait, attempts.LockedUntil.Sub(now))
+			continue
+		}
+		if now.Sub(attempts.LastFailedAt) >= g.Limits.Window {
+			continue
+		}
+		if until := attempts.LastFailedAt.Add(g.delay(attempts.Failures)); until.After(now) {
+			wait = max(wait, until.Sub(now))
+		}
+	}
+	if wait > 0 {
+		return RetryError{RetryAfter: wait}
+	}
+	return nil
+}
+
+// fail records a failed login and locks out whatever reached its limit.
+// user is nil when no account has the email.
+func (g LoginGuard) fail(ctx context.Context, email string, user *domain.User, client ClientInfo) error {
+	if g.Attempts == nil {
+		return nil
+	}
+	for _, subject := range g.subjects(email, client) {
+		attempts, err := g.Attempts.RecordFailure(ctx, subject.scope, subject.value, g.Limits.Window)
+		if err != nil {
+			return err
+		}
+		if attempts.Failures < subject.limit {
+			continue
+		}
+
+		lockout := domain.LoginLockout{
+			Scope:       subject.scope,
+			Subject:     subject.value,
+			Failures:    attempts.Failures,
+			IPAddress:   client.IPAddress,
+			UserAgent:   client.UserAgent,
+			LockedUntil: time.Now().Add(g.Limits.Lockout),
+		}
+		if user != nil && subject.scope == domain.LoginScopeAccount {
+			lockout.UserID = &user.ID
+		}
+		if err := g.Attempts.Lock(ctx, lockout); err != nil {
+			return err
+		}
+		log.Printf("login: %s %s locked out after %d failures from %s", lockout.Scope, lockout.Subject, lockout.Failures, client.IPAddress)
+	}
+	return nil
+}
+
+// succeed forgets the failures of the email. Those of the client address are
+// kept, so a single known password does not let it guess others.
+func (g LoginGuard) succeed(ctx context.Context, email string) error {
+	if g.Attempts == nil || g.Limits.AccountFailures <= 0 {
+		return nil
+	}
+	return g.Attempts.Reset(ctx, domain.LoginScopeAccount, email)
+}
+
+// delay is how long to wait after the given number of failures in a row.
+// Failures are forgotten after Window, so no delay is longer than that.
+func (g LoginGuard) delay(failures int) time.Duration {
+	if g.Limits.Delay <= 0 || failures <= g.Limits.FreeFailures {
+		return 0
+	}
+	limit := g.Limits.MaxDelay
+	if limit <= 0 || limit > g.Limits.Window {
+		limit = g.Limits.Window
+	}
+	delay := g.Limits.Delay
+	for i := g.Limits.FreeFailures + 1; i < failures && delay < limit; i++ {
+		delay *= 2
+	}
+	return min(delay, limit)
+}
+
+type loginSubject struct {
+	scope domain.LoginScope
+	value string
+	limit int
+}
+
+// subjects lists what the login is counted against; a scope without a limit
+// is skipped.
+func (g LoginGuard) subjects(email string, client ClientInfo) []loginSubject {
+	var subjects []loginSubject
+	if g.Limits.AccountFailures > 0 && email != "" {
+		subjects = append(subjects, loginSubject{domain.LoginScopeAccount, email, g.Limits.AccountFailures})
+	}
+	if g.Limits.IPFailures > 0 && client.IPAddress != "" {
+		subjects = append(subjects, loginSubject{domain.LoginScopeIP, client.IPAddress, g.Limits.IPFailures})
+	}
+	return subjects
+}
@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"strconv"
+	"strings"
+)
+
+const DefaultCurrency = "RUB"
+
+// currencyExponents maps ISO 4217 codes to the number of digits after the
+// decimal separator used by their minor unit.
+var currencyExponents = map[string]int{
+	"AED": 2, "AMD": 2, "ARS": 2, "AUD": 2, "AZN": 2,
+	"BDT": 2, "BGN": 2, "BHD": 3, "BRL": 2, "BYN": 2,
+	"CAD": 2, "CHF": 2, "CLP": 0, "CNY": 2, "COP": 2, "CZK": 2,
+	"DKK": 2, "EGP": 2, "EUR": 2, "GBP": 2, "GEL": 2,
+	"HKD": 2, "HUF": 2, "IDR": 2, "ILS": 2, "INR": 2, "IQD": 3, "ISK": 0,
+	"JOD": 3, "JPY": 0, "KES": 2, "KGS": 2, "KRW": 0, "KWD": 3, "KZT": 2,
+	"LKR": 2, "LYD": 3, "MDL": 2, "MNT": 2, "MXN": 2, "MYR": 2,
+	"NGN": 2, "NOK": 2, "NZD": 2, "OMR": 3,
+	"PEN": 2, "PHP": 2, "PKR": 2, "PLN": 2, "PYG": 0, "QAR": 2,
+	"RON": 2, "RSD": 2, "RUB": 2, "SAR": 2, "SEK": 2, "SGD": 2,
+	"THB": 2, "TJS": 2, "TMT": 2, "TND": 3, "TRY": 2, "TWD": 2,
+	"UAH": 2, "UGX": 0, "USD": 2, "UZS": 2, "VND": 0,
+	"XAF": 0, "XOF": 0, "ZAR": 2,
+}
+
+// NormalizeCurrency upper-cases the code and reports whether it is a
+// supported ISO 4217 currency.
+func NormalizeCurrency(code string) (string, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	_, ok := currencyExponents[code]
+	return code, ok
+}
+
+// ParseAmount converts a decimal string such as "9.99" into minor units of the
+// given currency, rejecting values with more fractional digits than the
+// currency allows.
+func ParseAmount(value, currency string) (int64, error) {
+	exponent, ok := currencyExponents[currency]
+	if !ok {
+		return 0, ErrInvalidInput
+	}
+
+	value = strings.ReplaceAll(strings.TrimSpace(value), ",", ".")
+	if value == "" {
+		return 0, ErrInvalidInput
+	}
+
+	whole, fraction, hasFraction := strings.Cut(value, ".")
+	if whole == "" || !isDigits(whole) || (hasFraction && !isDigits(fraction)) {
+		return 0, ErrInvalidInput
+	}
+	if hasFraction && (fraction == "" || len(fraction) > exponent) {
+		return 0, ErrInvalidInput
+	}
+	fraction += strings.Repeat("0", exponent-len(fraction))
+
+	minor, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidInput
+	}
+	return minor, nil
+}
+
+// FormatAmount renders minor units as a decimal string using the currency's
+// number of decimal places.
+func FormatAmount(minor int64, currency string) string {
+	exponent := currencyExponents[currency]
+	sign := ""
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+	digits := strconv.FormatInt(minor, 10)
+	if exponent == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return value != ""
+}
@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	purposePasswordReset = "password_reset"
+	passwordResetTTL     = time.Hour
+	minPasswordLength    = 8
+)
+
+// PasswordUsecase lets users who forgot their password set a new one through
+// a link sent to their email.
+type PasswordUsecase struct {
+	Users    UserRepository
+	Tokens   UserTokenRepository
+	Sessions SessionRepository
+	Sender   AccountSender
+	// AppURL is the address of the frontend links point at.
+	AppURL string
+}
+
+func NewPasswordUsecase(users UserRepository, tokens UserTokenRepository, sessions SessionRepository, sender AccountSender, appURL string) PasswordUsecase {
+	return PasswordUsecase{
+		Users:    users,
+		Tokens:   tokens,
+		Sessions: sessions,
+		Sender:   sender,
+		AppURL:   appURL,
+	}
+}
+
+// Forgot mails a reset link to the owner of email. It succeeds whether or
+// not such an account exists, and the email is sent in the background, so
+// neither the result nor the response time tells if it does.
+func (u PasswordUsecase) Forgot(ctx context.Context, email string) error {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return ErrInvalidInput
+	}
+	user, err := u.Users.FindByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	token, err := randomToken(32)
+	if err != nil {
+		return err
+	}
+	expiresAt := time.Now().Add(passwordResetTTL)
+	if err := u.Tokens.Create(ctx, user.ID, purposePasswordReset, hashToken(token), expiresAt); err != nil {
+		return err
+	}
+
+	link := accountLink(u.AppURL, "/reset-password", token)
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		if err := u.Sender.SendPasswordReset(ctx, user, link, expiresAt); err != nil {
+			log.Printf("password reset for %s: %v", user.Email, err)
+		}
+	}()
+	return nil
+}
+
+// Reset sets a new password using a token from Forgot and signs the user out
+// of every session. Tokens work once.
+func (u PasswordUsecase) Reset(ctx context.Context, token, password string) error {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return ErrUnauthorized
+	}
+	if len(password) < minPasswordLength {
+		return invalidField("password")
+	}
+	userID, err := u.Tokens.Consume(ctx, purposePasswordReset, hashToken(token))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return ErrUnauthorized
+		}
+		return err
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	if err := u.Users.UpdatePassword(ctx, userID, string(passwordHash)); err != nil {
+		return err
+	}
+	return u.Sessions.RevokeAll(ctx, userID)
+}
+
+// accountLink returns the page of the frontend at appURL with token in its
+// query.
+func accountLink(appURL, path, token string) string {
+	return strings.TrimRight(appURL, "/") + path + "?" + url.Values{"token": {token}}.Encode()
+}
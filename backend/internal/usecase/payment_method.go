@@ -0,0 +1,129 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"subscribe_tracker/backend/internal/domain"
+)
+
+type PaymentMethodUsecase struct {
+	PaymentMethods PaymentMethodRepository
+	// Subscriptions and Events announce the subscriptions Reassign moved
+	// like any other update.
+	Subscriptions SubscriptionRepository
+	Events        EventPublisher
+}
+
+func NewPaymentMethodUsecase(paymentMethods PaymentMethodRepository, subscriptions SubscriptionRepository, events EventPublisher) PaymentMethodUsecase {
+	return PaymentMethodUsecase{
+		PaymentMethods: paymentMethods,
+		Subscriptions:  subscriptions,
+		Events:         events,
+	}
+}
+
+// PaymentMethodInput leaves the expiry unknown when both ExpMonth and ExpYear
+// are zero.
+type PaymentMethodInput struct {
+	BankName  string
+	CardLast4 string
+	Brand     string
+	ExpMonth  int
+	ExpYear   int
+	Nickname  string
+}
+
+func (u PaymentMethodUsecase) List(ctx context.Context, userID string) ([]domain.PaymentMethod, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, ErrUnauthorized
+	}
+	return u.PaymentMethods.ListByUserID(ctx, userID)
+}
+
+func (u PaymentMethodUsecase) Create(ctx context.Context, userID string, input PaymentMethodInput) (domain.PaymentMethod, error) {
+	method, err := toPaymentMethod(userID, input)
+	if err != nil {
+		return domain.PaymentMethod{}, err
+	}
+	return u.PaymentMethods.Create(ctx, method)
+}
+
+func (u PaymentMethodUsecase) Update(ctx context.Context, userID, id string, input PaymentMethodInput) (domain.PaymentMethod, error) {
+	method, err := toPaymentMethod(userID, input)
+	if err != nil {
+		return domain.PaymentMethod{}, err
+	}
+	if strings.TrimSpace(id) == "" {
+		return domain.PaymentMethod{}, ErrInvalidInput
+	}
+	method.ID = id
+	return u.PaymentMethods.Update(ctx, method)
+}
+
+// Delete removes a payment method no subscription is charged to anymore.
+func (u PaymentMethodUsecase) Delete(ctx context.Context, userID, id string) error {
+	if strings.TrimSpace(userID) == "" {
+		return ErrUnauthorized
+	}
+	if strings.TrimSpace(id) == "" {
+		return ErrInvalidInput
+	}
+	return u.PaymentMethods.Delete(ctx, userID, id)
+}
+
+// Reassign moves all subscriptions charged to one payment method to another,
+// e.g. after a card was reissued, and returns how many were moved.
+func (u PaymentMethodUsecase) Reassign(ctx context.Context, userID, fromID, toID string) (int64, error) {
+	if strings.TrimSpace(userID) == "" {
+		return 0, ErrUnauthorized
+	}
+	fromID, toID = strings.TrimSpace(fromID), strings.TrimSpace(toID)
+	if fromID == "" || toID == "" || fromID == toID {
+		return 0, ErrInvalidInput
+	}
+	ids, err := u.PaymentMethods.Reassign(ctx, userID, fromID, toID)
+	if err != nil {
+		return 0, err
+	}
+
+	// The move is committed by now; a subscription that cannot be loaded
+	// only misses its event.
+	for _, id := range ids {
+		sub, err := u.Subscriptions.FindByID(ctx, userID, id)
+		if err != nil {
+			log.Printf("reassign payment method: load subscription %s: %v", id, err)
+			continue
+		}
+		publish(ctx, u.Events, userID, domain.EventSubscriptionUpdated, subscriptionEvent(withNextCharge(sub, today())))
+	}
+	return int64(len(ids)), nil
+}
+
+func toPaymentMethod(userID string, input PaymentMethodInput) (domain.PaymentMethod, error) {
+	if strings.TrimSpace(userID) == "" {
+		return domain.PaymentMethod{}, ErrUnauthorized
+	}
+
+	bankName := strings.TrimSpace(input.BankName)
+	cardLast4 := strings.TrimSpace(input.CardLast4)
+	if bankName == "" || len(cardLast4) != 4 || !isDigits(cardLast4) {
+		return domain.PaymentMethod{}, ErrInvalidInput
+	}
+
+	hasExpiry := input.ExpMonth != 0 || input.ExpYear != 0
+	if hasExpiry && (input.ExpMonth < 1 || input.ExpMonth > 12 || input.ExpYear < 2000 || input.ExpYear > 2099) {
+		return domain.PaymentMethod{}, ErrInvalidInput
+	}
+
+	return domain.PaymentMethod{
+		UserID:    userID,
+		BankName:  bankName,
+		CardLast4: cardLast4,
+		Brand:     strings.TrimSpace(input.Brand),
+		ExpMonth:  input.ExpMonth,
+		ExpYear:   input.ExpYear,
+		Nickname:  strings.TrimSpace(input.Nickname),
+	}, nil
+}
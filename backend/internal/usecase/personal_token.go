@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"subscribe_tracker/backend/internal/domain"
+)
+
+const (
+	// PersonalTokenPrefix starts every personal access token, which tells
+	// them apart from JWTs.
+	PersonalTokenPrefix = "stp_"
+	// personalTokenShown is how much of a token is kept to recognise it by.
+	personalTokenShown   = len(PersonalTokenPrefix) + 6
+	maxPersonalTokenName = 64
+	maxPersonalTokenDays = 365
+)
+
+var personalTokenScopes = map[string]bool{
+	domain.ScopeReadSubscriptions:  true,
+	domain.ScopeWriteSubscriptions: true,
+	domain.ScopeReadAnalytics:      true,
+}
+
+type PersonalTokenUsecase struct {
+	Tokens PersonalTokenRepository
+}
+
+func NewPersonalTokenUsecase(tokens PersonalTokenRepository) PersonalTokenUsecase {
+	return PersonalTokenUsecase{Tokens: tokens}
+}
+
+// PersonalTokenInput describes a token to create or update. ExpiresInDays
+// is only used on create; nil means the token does not expire.
+type PersonalTokenInput struct {
+	Name          string
+	Scopes        []string
+	ExpiresInDays *int
+}
+
+func (u PersonalTokenUsecase) List(ctx context.Context, userID string) ([]domain.PersonalToken, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, ErrUnauthorized
+	}
+	return u.Tokens.ListByUserID(ctx, userID)
+}
+
+func (u PersonalTokenUsecase) Get(ctx context.Context, userID, id string) (domain.PersonalToken, error) {
+	if strings.TrimSpace(userID) == "" {
+		return domain.PersonalToken{}, ErrUnauthorized
+	}
+	if strings.TrimSpace(id) == "" {
+		return domain.PersonalToken{}, ErrInvalidInput
+	}
+	return u.Tokens.FindByID(ctx, userID, id)
+}
+
+// Create stores a new token and returns it together with its value, which
+// cannot be retrieved later.
+func (u PersonalTokenUsecase) Create(ctx context.Context, userID string, input PersonalTokenInput) (domain.PersonalToken, string, error) {
+	token, err := toPersonalToken(userID, input)
+	if err != nil {
+		return domain.PersonalToken{}, "", err
+	}
+	if input.ExpiresInDays != nil {
+		days := *input.ExpiresInDays
+		if days < 1 || days > maxPersonalTokenDays {
+			return domain.PersonalToken{}, "", invalidField("expires_in_days")
+		}
+		expiresAt := time.Now().Add(time.Duration(days) * 24 * time.Hour)
+		token.ExpiresAt = &expiresAt
+	}
+
+	secret, err := randomToken(32)
+	if err != nil {
+		return domain.PersonalToken{}, "", err
+	}
+	value := PersonalTokenPrefix + secret
+	token.TokenHash = hashToken(value)
+	token.Prefix = value[:personalTokenShown]
+
+	created, err := u.Tokens.Create(ctx, token)
+	if err != nil {
+		return domain.PersonalToken{}, "", err
+	}
+	return created, value, nil
+}
+
+// Update renames the token and replaces its scopes; its value and expiry
+// stay the same.
+func (u PersonalTokenUsecase) Update(ctx context.Context, userID, id string, input PersonalTokenInput) (domain.PersonalToken, error) {
+	token, err := toPersonalToken(userID, input)
+	if err != nil {
+		return domain.PersonalToken{}, err
+	}
+	if strings.TrimSpace(id) == "" {
+		return domain.PersonalToken{}, ErrInvalidInput
+	}
+	token.ID = id
+	return u.Tokens.Update(ctx, token)
+}
+
+func (u PersonalTokenUsecase) Delete(ctx context.Context, userID, id string) error {
+	if strings.TrimSpace(userID) == "" {
+		return ErrUnauthorized
+	}
+	if strings.TrimSpace(id) == "" {
+		return ErrInvalidInput
+	}
+	return u.Tokens.Delete(ctx, userID, id)
+}
+
+// Authenticate returns what a personal access token grants. Unknown and
+// expired tokens are ErrUnauthorized.
+func (u PersonalTokenUsecase) Authenticate(ctx context.Context, value string) (AccessToken, error) {
+	if !strings.HasPrefix(value, PersonalTokenPrefix) {
+		return AccessToken{}, ErrUnauthorized
+	}
+	token, err := u.Tokens.FindByHash(ctx, hashToken(value))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return AccessToken{}, ErrUnauthorized
+		}
+		return AccessToken{}, err
+	}
+	if token.ExpiresAt != nil && !token.ExpiresAt.After(time.Now()) {
+		return AccessToken{}, ErrUnauthorized
+	}
+	// Last use is informational; a failed write does not block the request.
+	if err := u.Tokens.MarkUsed(ctx, token.ID); err != nil {
+		log.Printf("personal token %s: mark used: %v", token.ID, err)
+	}
+	// Nil scopes would grant everything, so a token always gets a slice.
+	scopes := append([]string{}, token.Scopes...)
+	return AccessToken{UserID: token.UserID, Scopes: scopes}, nil
+}
+
+// HasScope reports whether the access token may act within scope. Session
+// tokens have no scopes and may do anything.
+func (t AccessToken) HasScope(scope string) bool {
+	return t.Scopes == nil || slices.Contains(t.Scopes, scope)
+}
+
+func toPersonalToken(userID string, input PersonalTokenInput) (domain.PersonalToken, error) {
+	if strings.TrimSpace(userID) == "" {
+		return domain.PersonalToken{}, ErrUnauthorized
+	}
+	name := strings.TrimSpace(input.Name)
+	if name == "" || len([]rune(name)) > maxPersonalTokenName {
+		return domain.PersonalToken{}, invalidField("name")
+	}
+
+	scopes := []string{}
+	for _, scope := range input.Scopes {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if !personalTokenScopes[scope] {
+			return domain.PersonalToken{}, invalidField("scopes")
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	if len(scopes) == 0 {
+		return domain.PersonalToken{}, invalidField("scopes")
+	}
+	return domain.PersonalToken{UserID: userID, Name: name, Scopes: scopes}, nil
+}
@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"subscribe_tracker/backend/internal/domain"
+)
+
+type RateUsecase struct {
+	Rates     RateRepository
+	Providers []RateProvider
+}
+
+func NewRateUsecase(rates RateRepository, providers ...RateProvider) RateUsecase {
+	return RateUsecase{
+		Rates:     rates,
+		Providers: providers,
+	}
+}
+
+// List returns the latest stored rate of every pair.
+func (u RateUsecase) List(ctx context.Context) ([]domain.Rate, error) {
+	return u.Rates.Latest(ctx)
+}
+
+// Refresh fetches rates from every provider and stores the ones between
+// supported currencies. A failing provider does not keep the others from
+// being stored.
+func (u RateUsecase) Refresh(ctx context.Context) error {
+	var errs []error
+	for _, provider := range u.Providers {
+		rates, err := provider.Rates(ctx)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		var supported []domain.Rate
+		for _, rate := range rates {
+			base, okBase := NormalizeCurrency(rate.Base)
+			quote, okQuote := NormalizeCurrency(rate.Quote)
+			if !okBase || !okQuote || base == quote || rate.Rate <= 0 || math.IsInf(rate.Rate, 0) {
+				continue
+			}
+			rate.Base, rate.Quote, rate.Date = base, quote, truncateDay(rate.Date)
+			supported = append(supported, rate)
+		}
+		if err := u.Rates.Save(ctx, supported); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// AppliedRate is the rate a currency was converted into the base currency
+// at: one unit of Currency costs Rate units of the base.
+type AppliedRate struct {
+	Currency string
+	Rate     float64
+	Date     time.Time
+}
+
+// converter converts amounts into base. Currencies without a direct rate are
+// converted through one intermediate currency, so rates stored against a
+// single provider base cover every pair.
+type converter struct {
+	base  string
+	rates map[[2]string]domain.Rate
+	// known lists the currencies rates exist for, in a fixed order.
+	known []string
+}
+
+func newConverter(base string, rates []domain.Rate) converter {
+	c := converter{base: base, rates: map[[2]string]domain.Rate{}}
+	seen := map[string]bool{}
+	for _, rate := range rates {
+		c.rates[[2]string{rate.Base, rate.Quote}] = rate
+		for _, currency := range []string{rate.Base, rate.Quote} {
+			if !seen[currency] {
+				seen[currency] = true
+				c.known = append(c.known, currency)
+			}
+		}
+	}
+	sort.Strings(c.known)
+	return c
+}
+
+// rate returns the price of one unit of currency in the base currency and
+// the date of the oldest rate it was derived from.
+func (c converter) rate(currency string) (AppliedRate, bool) {
+	if currency == c.base {
+		return AppliedRate{Currency: currency, Rate: 1}, true
+	}
+	if rate, date, ok := c.pair(currency, c.base); ok {
+		return AppliedRate{Currency: currency, Rate: rate, Date: date}, true
+	}
+
+	var best AppliedRate
+	found := false
+	for _, pivot := range c.known {
+		first, firstDate, ok := c.pair(currency, pivot)
+		if !ok {
+			continue
+		}
+		second, secondDate, ok := c.pair(pivot, c.base)
+		if !ok {
+			continue
+		}
+		date := firstDate
+		if secondDate.Before(date) {
+			date = secondDate
+		}
+		// Prefer the path with the freshest rates.
+		if !found || date.After(best.Date) {
+			best = AppliedRate{Currency: currency, Rate: first * second, Date: date}
+			found = true
+		}
+	}
+	return best, found
+}
+
+func (c converter) pair(from, to string) (float64, time.Time, bool) {
+	if from == to {
+		return 0, time.Time{}, false
+	}
+	if rate, ok := c.rates[[2]string{from, to}]; ok {
+		return rate.Rate, rate.Date, true
+	}
+	if rate, ok := c.rates[[2]string{to, from}]; ok {
+		return 1 / rate.Rate, rate.Date, true
+	}
+	return 0, time.Time{}, false
+}
+
+// convertAmount converts minor units of currency into minor units of the
+// base currency at rate.
+func convertAmount(amount int64, currency string, rate AppliedRate, base string) int64 {
+	major := float64(amount) / math.Pow10(currencyExponents[currency])
+	return int64(math.Round(major * rate.Rate * math.Pow10(currencyExponents[base])))
+}
+
+func normalizeBaseCurrency(value string) (string, error) {
+	currency, ok := NormalizeCurrency(value)
+	if !ok || strings.TrimSpace(value) == "" {
+		return "", invalidField("base_currency")
+	}
+	return currency, nil
+}
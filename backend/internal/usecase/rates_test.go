@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"subscribe_tracker/backend/internal/domain"
+)
+
+func TestConverterRate(t *testing.T) {
+	older := time.Date(2026, 1, 30, 0, 0, 0, 0, time.UTC)
+	newer := time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+	rates := []domain.Rate{
+		{Base: "USD", Quote: "EUR", Rate: 0.9, Date: newer},
+		{Base: "USD", Quote: "RUB", Rate: 90, Date: older},
+		{Base: "CHF", Quote: "JPY", Rate: 170, Date: newer},
+	}
+
+	tests := []struct {
+		name     string
+		base     string
+		currency string
+		want     float64
+		wantDate time.Time
+		wantOK   bool
+	}{
+		{name: "same currency", base: "EUR", currency: "EUR", want: 1, wantOK: true},
+		{name: "direct", base: "EUR", currency: "USD", want: 0.9, wantDate: newer, wantOK: true},
+		{name: "inverse", base: "USD", currency: "EUR", want: 1 / 0.9, wantDate: newer, wantOK: true},
+		// RUB -> USD -> EUR is dated by the older of its two rates.
+		{name: "intermediate", base: "EUR", currency: "RUB", want: 0.9 / 90, wantDate: older, wantOK: true},
+		{name: "no path", base: "EUR", currency: "JPY"},
+		{name: "unknown", base: "EUR", currency: "KZT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := newConverter(tt.base, rates).rate(tt.currency)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if math.Abs(got.Rate-tt.want) > 1e-12 || !got.Date.Equal(tt.wantDate) || got.Currency != tt.currency {
+				t.Errorf("rate = %+v, want %v on %s", got, tt.want, tt.wantDate.Format("2006-01-02"))
+			}
+		})
+	}
+}
+
+func TestConverterPrefersFreshestPath(t *testing.T) {
+	older := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+	converter := newConverter("EUR", []domain.Rate{
+		{Base: "GBP", Quote: "EUR", Rate: 1.2, Date: older},
+		{Base: "GBP", Quote: "KZT", Rate: 600, Date: older},
+		{Base: "USD", Quote: "EUR", Rate: 0.9, Date: newer},
+		{Base: "USD", Quote: "KZT", Rate: 500, Date: newer},
+	})
+
+	got, ok := converter.rate("KZT")
+	if !ok || math.Abs(got.Rate-0.9/500) > 1e-12 || !got.Date.Equal(newer) {
+		t.Errorf("rate = %+v, %v, want the path through USD", got, ok)
+	}
+}
+
+func TestConvertAmount(t *testing.T) {
+	tests := []struct {
+		amount   int64
+		currency string
+		rate     float64
+		base     string
+		want     int64
+	}{
+		{amount: 29900, currency: "RUB", rate: 0.01, base: "EUR", want: 299},
+		{amount: 1000, currency: "JPY", rate: 0.0061, base: "EUR", want: 610},
+		{amount: 999, currency: "USD", rate: 150.4, base: "JPY", want: 1502},
+		{amount: 1250, currency: "KWD", rate: 3.25, base: "USD", want: 406},
+	}
+	for _, tt := range tests {
+		got := convertAmount(tt.amount, tt.currency, AppliedRate{Currency: tt.currency, Rate: tt.rate}, tt.base)
+		if got != tt.want {
+			t.Errorf("convertAmount(%d %s at %v into %s) = %d, want %d", tt.amount, tt.currency, tt.rate, tt.base, got, tt.want)
+		}
+	}
+}
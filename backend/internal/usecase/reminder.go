@@ -0,0 +1,243 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"subscribe_tracker/backend/internal/domain"
+)
+
+const (
+	maxReminderLeadDays = 90
+	// maxReminderAttempts bounds delivery retries; the delay doubles after
+	// each failed attempt starting from reminderRetryDelay.
+	maxReminderAttempts = 5
+	reminderRetryDelay  = time.Minute
+	reminderLease       = 5 * time.Minute
+)
+
+type ReminderUsecase struct {
+	Reminders     ReminderRepository
+	Subscriptions SubscriptionRepository
+	Users         UserRepository
+	Notifier      Notifier
+	Events        EventPublisher
+}
+
+func NewReminderUsecase(
+	reminders ReminderRepository,
+	subscriptions SubscriptionRepository,
+	users UserRepository,
+	notifier Notifier,
+	events EventPublisher,
+) ReminderUsecase {
+	return ReminderUsecase{
+		Reminders:     reminders,
+		Subscriptions: subscriptions,
+		Users:         users,
+		Notifier:      notifier,
+		Events:        events,
+	}
+}
+
+func (u ReminderUsecase) List(ctx context.Context, userID string) ([]domain.Reminder, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, ErrUnauthorized
+	}
+	return u.Reminders.ListByUserID(ctx, userID)
+}
+
+// NotificationSettings are the per-user defaults of reminders.
+type NotificationSettings struct {
+	LeadDays int
+	Locale   string
+}
+
+// NotificationSettingsInput leaves fields that are nil unchanged.
+type NotificationSettingsInput struct {
+	LeadDays *int
+	Locale   *string
+}
+
+func (u ReminderUsecase) Settings(ctx context.Context, userID string) (NotificationSettings, error) {
+	if strings.TrimSpace(userID) == "" {
+		return NotificationSettings{}, ErrUnauthorized
+	}
+	user, err := u.Users.FindByID(ctx, userID)
+	if err != nil {
+		return NotificationSettings{}, err
+	}
+	return NotificationSettings{LeadDays: user.ReminderLeadDays, Locale: user.Locale}, nil
+}
+
+func (u ReminderUsecase) UpdateSettings(ctx context.Context, userID string, input NotificationSettingsInput) (NotificationSettings, error) {
+	settings, err := u.Settings(ctx, userID)
+	if err != nil {
+		return NotificationSettings{}, err
+	}
+	if input.LeadDays != nil {
+		if *input.LeadDays < 0 || *input.LeadDays > maxReminderLeadDays {
+			return NotificationSettings{}, ErrInvalidInput
+		}
+		settings.LeadDays = *input.LeadDays
+	}
+	if input.Locale != nil {
+		locale, ok := NormalizeLocale(*input.Locale)
+		if !ok {
+			return NotificationSettings{}, ErrInvalidInput
+		}
+		settings.Locale = locale
+	}
+
+	user, err := u.Users.UpdateNotificationSettings(ctx, userID, settings.LeadDays, settings.Locale)
+	if err != nil {
+		return NotificationSettings{}, err
+	}
+	u.reschedule(ctx, user.ID)
+	return NotificationSettings{LeadDays: user.ReminderLeadDays, Locale: user.Locale}, nil
+}
+
+// SetSubscriptionLeadDays overrides the lead time for one subscription; nil
+// restores the default of the user.
+func (u ReminderUsecase) SetSubscriptionLeadDays(ctx context.Context, userID, id string, days *int) (domain.Subscription, error) {
+	if strings.TrimSpace(userID) == "" {
+		return domain.Subscription{}, ErrUnauthorized
+	}
+	if strings.TrimSpace(id) == "" || (days != nil && (*days < 0 || *days > maxReminderLeadDays)) {
+		return domain.Subscription{}, ErrInvalidInput
+	}
+	sub, err := u.Subscriptions.UpdateReminderLeadDays(ctx, userID, id, days)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+	u.reschedule(ctx, userID)
+	return withNextCharge(sub, today()), nil
+}
+
+// Publish reschedules the reminders of the user when one of their
+// subscriptions changed, so ReminderUsecase can be told about changes like
+// any other EventPublisher.
+func (u ReminderUsecase) Publish(ctx context.Context, userID, event string, _ any) error {
+	if !strings.HasPrefix(event, "subscription.") {
+		return nil
+	}
+	return u.ScheduleUser(ctx, userID)
+}
+
+// Schedule reschedules the reminders of every user, which moves them on to
+// the next charge once a charge has passed. A failure for one user is
+// logged and the others are still scheduled.
+func (u ReminderUsecase) Schedule(ctx context.Context) error {
+	users, err := u.Users.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, user := range users {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := u.schedule(ctx, user); err != nil {
+			log.Printf("reminders: schedule user %s: %v", user.ID, err)
+		}
+	}
+	return nil
+}
+
+// ScheduleUser queues a reminder for the next charge of every active
+// subscription of the user and drops pending reminders that no longer match
+// the schedule, e.g. after a subscription was paused or its charge date
+// moved.
+func (u ReminderUsecase) ScheduleUser(ctx context.Context, userID string) error {
+	if strings.TrimSpace(userID) == "" {
+		return ErrUnauthorized
+	}
+	user, err := u.Users.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return u.schedule(ctx, user)
+}
+
+func (u ReminderUsecase) schedule(ctx context.Context, user domain.User) error {
+	subs, err := u.Subscriptions.ListByUserID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	now := today()
+	var reminders []domain.Reminder
+	for _, sub := range subs {
+		sub = withNextCharge(sub, now)
+		if sub.Status != domain.StatusActive || sub.NextChargeDate.IsZero() {
+			continue
+		}
+		leadDays := user.ReminderLeadDays
+		if sub.ReminderLeadDays != nil {
+			leadDays = *sub.ReminderLeadDays
+		}
+		reminders = append(reminders, domain.Reminder{
+			UserID:         user.ID,
+			SubscriptionID: sub.ID,
+			ServiceName:    sub.ServiceName,
+			ChargeDate:     sub.NextChargeDate,
+			RemindAt:       sub.NextChargeDate.AddDate(0, 0, -leadDays),
+			Amount:         sub.NextChargeAmount,
+			Currency:       sub.NextChargeCurrency,
+			Status:         domain.ReminderPending,
+		})
+	}
+	return u.Reminders.Sync(ctx, user.ID, reminders)
+}
+
+// reschedule follows a committed change, so a failure is only logged; the
+// next sweep of Schedule catches up.
+func (u ReminderUsecase) reschedule(ctx context.Context, userID string) {
+	if err := u.ScheduleUser(ctx, userID); err != nil {
+		log.Printf("reminders: schedule user %s: %v", userID, err)
+	}
+}
+
+// Dispatch claims up to limit due reminders and sends them through the
+// notifier. It returns how many were claimed; failed deliveries are retried
+// with exponential backoff unless no notifier can reach the user. A
+// charge.upcoming event is published on the first
+// attempt of every reminder.
+func (u ReminderUsecase) Dispatch(ctx context.Context, limit int) (int, error) {
+	reminders, err := u.Reminders.ClaimDue(ctx, limit, reminderLease)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, reminder := range reminders {
+		if reminder.Attempts == 1 {
+			publish(ctx, u.Events, reminder.UserID, domain.EventChargeUpcoming, chargeEvent(reminder))
+		}
+		if err := u.deliver(ctx, reminder); err != nil {
+			// Retrying cannot help a user no notifier can reach.
+			var retryAt *time.Time
+			if reminder.Attempts < maxReminderAttempts && !errors.Is(err, ErrNoRecipient) {
+				next := time.Now().Add(reminderRetryDelay << (reminder.Attempts - 1))
+				retryAt = &next
+			}
+			if markErr := u.Reminders.MarkFailed(ctx, reminder.ID, retryAt, err.Error()); markErr != nil {
+				return len(reminders), markErr
+			}
+			continue
+		}
+		if err := u.Reminders.MarkSent(ctx, reminder.ID); err != nil {
+			return len(reminders), err
+		}
+	}
+	return len(reminders), nil
+}
+
+func (u ReminderUsecase) deliver(ctx context.Context, reminder domain.Reminder) error {
+	user, err := u.Users.FindByID(ctx, reminder.UserID)
+	if err != nil {
+		return err
+	}
+	return u.Notifier.Notify(ctx, user, reminder)
+}
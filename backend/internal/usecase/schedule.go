@@ -0,0 +1,173 @@
+package usecase
+
+import (
+	"time"
+
+	"subscribe_tracker/backend/internal/domain"
+)
+
+// NextChargeDates returns the first n charge dates on or after from for a
+// subscription anchored at anchor. Every date is computed from the anchor
+// rather than from the previous charge, so a monthly subscription started on
+// the 31st is charged on the last day of shorter months and returns to the
+// 31st afterwards; a yearly charge on February 29 falls on February 28
+// outside leap years.
+func NextChargeDates(cycle domain.BillingCycle, anchor, from time.Time, n int) []time.Time {
+	if cycle.Interval < 1 || n <= 0 {
+		return nil
+	}
+
+	anchor = truncateDay(anchor)
+	from = truncateDay(from)
+
+	var step func(k int) time.Time
+	k := 0
+	switch cycle.Unit {
+	case domain.BillingDay, domain.BillingWeek:
+		days := cycle.Interval
+		if cycle.Unit == domain.BillingWeek {
+			days *= 7
+		}
+		step = func(k int) time.Time { return anchor.AddDate(0, 0, k*days) }
+		if from.After(anchor) {
+			k = daysBetween(anchor, from) / days
+		}
+	case domain.BillingMonth, domain.BillingYear:
+		months := cycle.Interval
+		if cycle.Unit == domain.BillingYear {
+			months *= 12
+		}
+		step = func(k int) time.Time { return addMonthsClamped(anchor, k*months) }
+		if from.After(anchor) {
+			elapsed := (from.Year()-anchor.Year())*12 + int(from.Month()-anchor.Month())
+			k = elapsed/months - 1
+		}
+	default:
+		return nil
+	}
+	if k < 0 {
+		k = 0
+	}
+
+	dates := make([]time.Time, 0, n)
+	for ; len(dates) < n; k++ {
+		date := step(k)
+		if date.Before(from) {
+			continue
+		}
+		dates = append(dates, date)
+	}
+	return dates
+}
+
+// NextChargeDate returns the first charge date on or after from.
+func NextChargeDate(cycle domain.BillingCycle, anchor, from time.Time) time.Time {
+	dates := NextChargeDates(cycle, anchor, from, 1)
+	if len(dates) == 0 {
+		return truncateDay(anchor)
+	}
+	return dates[0]
+}
+
+// upcomingCharges returns up to n charge dates of sub on or after from. Trials
+// and the subscription status are taken into account, so a paused or
+// cancelled subscription may have fewer than n or no charges left.
+func upcomingCharges(sub domain.Subscription, from time.Time, n int) []time.Time {
+	from = truncateDay(from)
+	effective := truncateDay(sub.StatusEffectiveDate)
+
+	switch sub.Status {
+	case domain.StatusPaused, domain.StatusCancelled:
+		var dates []time.Time
+		for _, date := range NextChargeDates(sub.Billing, chargeAnchor(sub), from, n) {
+			if !date.Before(effective) {
+				break
+			}
+			dates = append(dates, date)
+		}
+		return dates
+	default:
+		if effective.After(from) {
+			from = effective
+		}
+		return NextChargeDates(sub.Billing, chargeAnchor(sub), from, n)
+	}
+}
+
+// statusOn returns the status in effect on date given the status history of
+// a subscription; it is active before the first change.
+func statusOn(history []domain.StatusChange, date time.Time) domain.SubscriptionStatus {
+	status := domain.StatusActive
+	for _, change := range history {
+		if truncateDay(change.EffectiveDate).After(date) {
+			break
+		}
+		status = change.Status
+	}
+	return status
+}
+
+// chargeAnchor returns the date the billing schedule of sub starts from. A
+// trial's end date is treated as the first paid charge.
+func chargeAnchor(sub domain.Subscription) time.Time {
+	if sub.TrialEndDate != nil {
+		return *sub.TrialEndDate
+	}
+	return sub.ChargeDate
+}
+
+// chargePrice returns the amount and currency charged for sub on date: the
+// latest price history entry effective on that date.
+func chargePrice(sub domain.Subscription, date time.Time) (int64, string) {
+	if price, ok := priceAt(sub, date); ok {
+		return price.Price, price.Currency
+	}
+	if sub.TrialEndDate != nil && !date.Before(truncateDay(*sub.TrialEndDate)) {
+		return sub.PostTrialPrice, sub.Currency
+	}
+	return sub.Price, sub.Currency
+}
+
+// priceAt returns the price history entry of sub in effect on date, or the
+// first one before that. It reports false when sub has no history.
+func priceAt(sub domain.Subscription, date time.Time) (domain.PriceChange, bool) {
+	if len(sub.Prices) == 0 {
+		return domain.PriceChange{}, false
+	}
+	price := sub.Prices[0]
+	for _, change := range sub.Prices[1:] {
+		if truncateDay(change.EffectiveFrom).After(date) {
+			break
+		}
+		price = change
+	}
+	return price, true
+}
+
+func addMonthsClamped(date time.Time, months int) time.Time {
+	year, month := date.Year(), date.Month()+time.Month(months)
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	day := date.Day()
+	if last := daysInMonth(first.Year(), first.Month()); day > last {
+		day = last
+	}
+	return time.Date(first.Year(), first.Month(), day, 0, 0, 0, 0, time.UTC)
+}
+
+// daysBetween returns the number of days from a to b, both at midnight UTC.
+// It does not go through time.Duration, which only spans about 292 years.
+func daysBetween(a, b time.Time) int {
+	return int((b.Unix() - a.Unix()) / (24 * 60 * 60))
+}
+
+func daysInMonth(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func today() time.Time {
+	return truncateDay(time.Now().UTC())
+}
@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"subscribe_tracker/backend/internal/domain"
+)
+
+func TestNextChargeDates(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name   string
+		cycle  domain.BillingCycle
+		anchor time.Time
+		from   time.Time
+		n      int
+		want   []time.Time
+	}{
+		{
+			name:   "monthly from the 31st in a common year",
+			cycle:  domain.BillingCycle{Unit: domain.BillingMonth, Interval: 1},
+			anchor: date(2025, 1, 31),
+			from:   date(2025, 1, 31),
+			n:      4,
+			want:   []time.Time{date(2025, 1, 31), date(2025, 2, 28), date(2025, 3, 31), date(2025, 4, 30)},
+		},
+		{
+			name:   "monthly from the 31st in a leap year",
+			cycle:  domain.BillingCycle{Unit: domain.BillingMonth, Interval: 1},
+			anchor: date(2024, 1, 31),
+			from:   date(2024, 2, 1),
+			n:      2,
+			want:   []time.Time{date(2024, 2, 29), date(2024, 3, 31)},
+		},
+		{
+			name:   "yearly on February 29",
+			cycle:  domain.BillingCycle{Unit: domain.BillingYear, Interval: 1},
+			anchor: date(2024, 2, 29),
+			from:   date(2024, 3, 1),
+			n:      4,
+			want:   []time.Time{date(2025, 2, 28), date(2026, 2, 28), date(2027, 2, 28), date(2028, 2, 29)},
+		},
+		{
+			name:   "quarterly",
+			cycle:  domain.BillingCycle{Unit: domain.BillingMonth, Interval: 3},
+			anchor: date(2025, 11, 30),
+			from:   date(2026, 1, 1),
+			n:      3,
+			want:   []time.Time{date(2026, 2, 28), date(2026, 5, 30), date(2026, 8, 30)},
+		},
+		{
+			name:   "semiannual",
+			cycle:  domain.BillingCycle{Unit: domain.BillingMonth, Interval: 6},
+			anchor: date(2023, 8, 31),
+			from:   date(2026, 3, 1),
+			n:      2,
+			want:   []time.Time{date(2026, 8, 31), date(2027, 2, 28)},
+		},
+		{
+			name:   "every 10 days",
+			cycle:  domain.BillingCycle{Unit: domain.BillingDay, Interval: 10},
+			anchor: date(2026, 2, 25),
+			from:   date(2026, 3, 6),
+			n:      2,
+			want:   []time.Time{date(2026, 3, 7), date(2026, 3, 17)},
+		},
+		{
+			name:   "every 2 weeks",
+			cycle:  domain.BillingCycle{Unit: domain.BillingWeek, Interval: 2},
+			anchor: date(2026, 1, 5),
+			from:   date(2026, 1, 20),
+			n:      2,
+			want:   []time.Time{date(2026, 2, 2), date(2026, 2, 16)},
+		},
+		{
+			name:   "from before the anchor",
+			cycle:  domain.BillingCycle{Unit: domain.BillingMonth, Interval: 1},
+			anchor: date(2026, 5, 15),
+			from:   date(2026, 1, 1),
+			n:      2,
+			want:   []time.Time{date(2026, 5, 15), date(2026, 6, 15)},
+		},
+		{
+			name:   "weekly from before the anchor",
+			cycle:  domain.BillingCycle{Unit: domain.BillingWeek, Interval: 1},
+			anchor: date(2026, 5, 15),
+			from:   date(2026, 1, 1),
+			n:      1,
+			want:   []time.Time{date(2026, 5, 15)},
+		},
+		{
+			name:   "time of day is ignored",
+			cycle:  domain.BillingCycle{Unit: domain.BillingDay, Interval: 1},
+			anchor: time.Date(2026, 3, 1, 23, 0, 0, 0, time.UTC),
+			from:   time.Date(2026, 3, 3, 1, 0, 0, 0, time.UTC),
+			n:      1,
+			want:   []time.Time{date(2026, 3, 3)},
+		},
+		{
+			// Further apart than a time.Duration can hold.
+			name:   "daily from an ancient anchor",
+			cycle:  domain.BillingCycle{Unit: domain.BillingDay, Interval: 7},
+			anchor: date(1, 1, 1),
+			from:   date(2026, 10, 16),
+			n:      1,
+			want:   []time.Time{date(2026, 10, 19)},
+		},
+		{
+			name:   "invalid interval",
+			cycle:  domain.BillingCycle{Unit: domain.BillingMonth, Interval: 0},
+			anchor: date(2026, 1, 1),
+			from:   date(2026, 1, 1),
+			n:      1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NextChargeDates(tt.cycle, tt.anchor, tt.from, tt.n)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d dates %v, want %v", len(got), got, tt.want)
+			}
+			for i := range got {
+				if !got[i].Equal(tt.want[i]) {
+					t.Errorf("date %d = %s, want %s", i, got[i].Format("2006-01-02"), tt.want[i].Format("2006-01-02"))
+				}
+			}
+		})
+	}
+}
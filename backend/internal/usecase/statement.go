@@ -0,0 +1,347 @@
+package usecase
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"subscribe_tracker/backend/internal/domain"
+)
+
+const (
+	// minWeeklyCharges, minMonthlyCharges and minYearlyCharges are how many
+	// charges make a cadence; a yearly one rarely shows up more than twice
+	// in a statement.
+	minWeeklyCharges  = 4
+	minMonthlyCharges = 3
+	minYearlyCharges  = 2
+	// merchantWords is how many words of a description identify a merchant.
+	merchantWords = 2
+)
+
+type StatementUsecase struct {
+	Parser        StatementParser
+	Subscriptions SubscriptionUsecase
+}
+
+func NewStatementUsecase(parser StatementParser, subscriptions SubscriptionUsecase) StatementUsecase {
+	return StatementUsecase{
+		Parser:        parser,
+		Subscriptions: subscriptions,
+	}
+}
+
+// SubscriptionCandidate is a recurring charge found in a statement. It is not
+// stored; the user reviews it and confirms it as a SubscriptionInput.
+type SubscriptionCandidate struct {
+	ServiceName string
+	// Merchant is the normalized description the charges were grouped by.
+	Merchant  string
+	BankName  string
+	CardLast4 string
+	Billing   domain.BillingCycle
+	// ChargeDate is the date of the latest charge, Price and Currency its
+	// amount.
+	ChargeDate     time.Time
+	NextChargeDate time.Time
+	Price          int64
+	Currency       string
+	Charges        []time.Time
+	// SubscriptionID is set when the user already tracks a subscription with
+	// the same merchant.
+	SubscriptionID string
+}
+
+// StatementOptions fill in what the statement itself does not say.
+type StatementOptions struct {
+	BankName  string
+	CardLast4 string
+}
+
+// Detect parses a statement and returns the recurring charges in it, most
+// recent first. Bank name and card last4 come from the statement, the
+// transaction descriptions, or options as a fallback.
+func (u StatementUsecase) Detect(ctx context.Context, userID string, data []byte, options StatementOptions) ([]SubscriptionCandidate, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, ErrUnauthorized
+	}
+	cardLast4 := strings.TrimSpace(options.CardLast4)
+	if cardLast4 != "" && (len(cardLast4) != 4 || !isDigits(cardLast4)) {
+		return nil, invalidField("card_last4")
+	}
+
+	statement, err := u.Parser.Parse(data)
+	if err != nil {
+		return nil, invalidField("file")
+	}
+	if statement.BankName == "" {
+		statement.BankName = strings.TrimSpace(options.BankName)
+	}
+	if statement.CardLast4 == "" {
+		statement.CardLast4 = cardLast4
+	}
+
+	existing, err := u.Subscriptions.List(ctx, userID, SubscriptionFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := DetectRecurring(statement)
+	for i := range candidates {
+		for _, sub := range existing {
+			if normalizeMerchant(sub.ServiceName) == candidates[i].Merchant {
+				candidates[i].SubscriptionID = sub.ID
+				break
+			}
+		}
+	}
+	return candidates, nil
+}
+
+// Confirm creates the subscriptions the user accepted in a single
+// transaction, so a typo or a failing insert does not leave half of them
+// behind.
+func (u StatementUsecase) Confirm(ctx context.Context, userID string, inputs []SubscriptionInput) ([]domain.Subscription, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, ErrUnauthorized
+	}
+	if len(inputs) == 0 {
+		return nil, ErrInvalidInput
+	}
+	subs := make([]domain.Subscription, 0, len(inputs))
+	for _, input := range inputs {
+		sub, err := u.Subscriptions.newSubscription(userID, input)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	if err := u.Subscriptions.checkCanCreate(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	created, err := u.Subscriptions.Subscriptions.CreateMany(ctx, subs)
+	if err != nil {
+		return nil, err
+	}
+	now := today()
+	for i := range created {
+		created[i] = withNextCharge(created[i], now)
+		publish(ctx, u.Subscriptions.Events, userID, domain.EventSubscriptionCreated, subscriptionEvent(created[i]))
+	}
+	return created, nil
+}
+
+// DetectRecurring groups the debits of a statement by merchant and currency
+// and keeps the groups charged weekly, monthly or yearly. A group still
+// counts when one charge is off by a few days or was missed.
+func DetectRecurring(statement domain.Statement) []SubscriptionCandidate {
+	type charge struct {
+		date      time.Time
+		amount    int64
+		cardLast4 string
+	}
+	type group struct {
+		merchant string
+		currency string
+		charges  []charge
+	}
+
+	var latest time.Time
+	groups := map[string]*group{}
+	var keys []string
+	for _, txn := range statement.Transactions {
+		if !strings.HasPrefix(txn.Amount, "-") {
+			continue
+		}
+		currency, ok := NormalizeCurrency(txn.Currency)
+		if !ok {
+			continue
+		}
+		amount, err := ParseAmount(trimFraction(strings.TrimPrefix(txn.Amount, "-")), currency)
+		if err != nil || amount == 0 {
+			continue
+		}
+		merchant := normalizeMerchant(txn.Description)
+		if merchant == "" {
+			continue
+		}
+
+		key := merchant + "|" + currency
+		item, ok := groups[key]
+		if !ok {
+			item = &group{merchant: merchant, currency: currency}
+			groups[key] = item
+			keys = append(keys, key)
+		}
+		item.charges = append(item.charges, charge{date: truncateDay(txn.Date), amount: amount, cardLast4: descriptionCard(txn.Description)})
+		if txn.Date.After(latest) {
+			latest = truncateDay(txn.Date)
+		}
+	}
+
+	var candidates []SubscriptionCandidate
+	for _, key := range keys {
+		item := groups[key]
+		sort.SliceStable(item.charges, func(i, j int) bool { return item.charges[i].date.Before(item.charges[j].date) })
+
+		var dates []time.Time
+		for _, c := range item.charges {
+			if len(dates) == 0 || !c.date.Equal(dates[len(dates)-1]) {
+				dates = append(dates, c.date)
+			}
+		}
+		cycle, ok := detectCadence(dates)
+		if !ok {
+			continue
+		}
+		last := item.charges[len(item.charges)-1]
+		next := NextChargeDate(cycle, last.date, last.date.AddDate(0, 0, 1))
+		// A charge missing for a whole cycle before the statement ends means
+		// the subscription was most likely cancelled.
+		if next.AddDate(0, 0, cadenceTolerance(cycle)).Before(latest) {
+			continue
+		}
+
+		cardLast4 := statement.CardLast4
+		if last.cardLast4 != "" {
+			cardLast4 = last.cardLast4
+		}
+		candidates = append(candidates, SubscriptionCandidate{
+			ServiceName:    merchantTitle(item.merchant),
+			Merchant:       item.merchant,
+			BankName:       statement.BankName,
+			CardLast4:      cardLast4,
+			Billing:        cycle,
+			ChargeDate:     last.date,
+			NextChargeDate: next,
+			Price:          last.amount,
+			Currency:       item.currency,
+			Charges:        dates,
+		})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].ChargeDate.After(candidates[j].ChargeDate)
+	})
+	return candidates
+}
+
+// detectCadence reports whether dates, sorted and distinct, are about a week,
+// a month or a year apart. Gaps of two cycles, i.e. one missing charge, are
+// allowed.
+func detectCadence(dates []time.Time) (domain.BillingCycle, bool) {
+	cadences := []struct {
+		cycle domain.BillingCycle
+		days  int
+		min   int
+	}{
+		{domain.BillingCycle{Unit: domain.BillingWeek, Interval: 1}, 7, minWeeklyCharges},
+		{domain.BillingCycle{Unit: domain.BillingMonth, Interval: 1}, 30, minMonthlyCharges},
+		{domain.BillingCycle{Unit: domain.BillingYear, Interval: 1}, 365, minYearlyCharges},
+	}
+	for _, cadence := range cadences {
+		if len(dates) < cadence.min {
+			continue
+		}
+		tolerance := cadenceTolerance(cadence.cycle)
+		matches := true
+		for i := 1; i < len(dates); i++ {
+			gap := daysBetween(dates[i-1], dates[i])
+			if !near(gap, cadence.days, tolerance) && !near(gap, 2*cadence.days, 2*tolerance) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return cadence.cycle, true
+		}
+	}
+	return domain.BillingCycle{}, false
+}
+
+func cadenceTolerance(cycle domain.BillingCycle) int {
+	switch cycle.Unit {
+	case domain.BillingWeek:
+		return 1
+	case domain.BillingYear:
+		return 15
+	default:
+		return 5
+	}
+}
+
+func near(value, target, tolerance int) bool {
+	return value >= target-tolerance && value <= target+tolerance
+}
+
+var (
+	// cardPattern finds masked card numbers such as "*1234", "XXXX1234" or
+	// "CARD 1234" in descriptions.
+	cardPattern = regexp.MustCompile(`(?i)(?:\*+|x{4,}|card\s*(?:no\.?|#)?\s*\**)\s?(\d{4})\b`)
+	// merchantNoise are words in card descriptions that do not identify the
+	// merchant.
+	merchantNoise = map[string]bool{
+		"POS": true, "CARD": true, "PURCHASE": true, "PAYMENT": true, "DEBIT": true, "RECURRING": true,
+		"VISA": true, "MASTERCARD": true, "MIR": true, "MAESTRO": true,
+		"WWW": true, "COM": true, "NET": true, "ORG": true, "IO": true, "RU": true, "TV": true,
+		"INC": true, "LLC": true, "LTD": true, "GMBH": true, "OOO": true, "ООО": true,
+		"ОПЛАТА": true, "ПОКУПКА": true, "КАРТА": true, "СПИСАНИЕ": true,
+	}
+)
+
+// normalizeMerchant reduces a transaction description to the first words
+// naming the merchant: card numbers, amounts, references, domains and
+// locations after them are dropped.
+func normalizeMerchant(description string) string {
+	description = cardPattern.ReplaceAllString(description, " ")
+	fields := strings.FieldsFunc(strings.ToUpper(description), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var words []string
+	for _, field := range fields {
+		if len([]rune(field)) < 3 && len(words) > 0 {
+			continue
+		}
+		if merchantNoise[field] || strings.IndexFunc(field, unicode.IsDigit) >= 0 || (len(words) > 0 && words[len(words)-1] == field) {
+			continue
+		}
+		words = append(words, field)
+		if len(words) == merchantWords {
+			break
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+func descriptionCard(description string) string {
+	if match := cardPattern.FindStringSubmatch(description); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// merchantTitle turns "NETFLIX" into "Netflix".
+func merchantTitle(merchant string) string {
+	words := strings.Fields(strings.ToLower(merchant))
+	for i, word := range words {
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
+	}
+	return strings.Join(words, " ")
+}
+
+// trimFraction drops trailing zeros after the decimal point, which statements
+// often pad amounts with.
+func trimFraction(value string) string {
+	if !strings.Contains(value, ".") {
+		return value
+	}
+	value = strings.TrimRight(value, "0")
+	return strings.TrimSuffix(value, ".")
+}
@@ -0,0 +1,259 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"subscribe_tracker/backend/internal/domain"
+)
+
+func debit(date time.Time, amount, description string) domain.StatementTransaction {
+	return domain.StatementTransaction{Date: date, Amount: amount, Currency: "EUR", Description: description}
+}
+
+func TestDetectRecurring(t *testing.T) {
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2026, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name      string
+		txns      []domain.StatementTransaction
+		wantCycle domain.BillingCycle
+		wantPrice int64
+		wantNext  time.Time
+		wantNone  bool
+	}{
+		{
+			name: "monthly",
+			txns: []domain.StatementTransaction{
+				debit(date(1, 5), "-15.49", "NETFLIX.COM 866-579-7172"),
+				debit(date(2, 5), "-15.49", "NETFLIX.COM 866-579-7172"),
+				debit(date(3, 5), "-15.49", "NETFLIX.COM 866-579-7172"),
+			},
+			wantCycle: domain.BillingCycle{Unit: domain.BillingMonth, Interval: 1},
+			wantPrice: 1549,
+			wantNext:  date(4, 5),
+		},
+		{
+			name: "monthly with a late and a missed charge",
+			txns: []domain.StatementTransaction{
+				debit(date(1, 31), "-9.99", "SPOTIFY P1234 STOCKHOLM"),
+				debit(date(3, 3), "-9.99", "SPOTIFY P5678 STOCKHOLM"),
+				debit(date(5, 1), "-9.99", "SPOTIFY P9012 STOCKHOLM"),
+			},
+			wantCycle: domain.BillingCycle{Unit: domain.BillingMonth, Interval: 1},
+			wantPrice: 999,
+			wantNext:  date(6, 1),
+		},
+		{
+			// Metered plans and currency conversion change the amount a
+			// little every time; the latest one is proposed.
+			name: "monthly with amount jitter",
+			txns: []domain.StatementTransaction{
+				debit(date(1, 10), "-20.13", "OPENAI *CHATGPT SUBSCR"),
+				debit(date(2, 10), "-19.87", "OPENAI *CHATGPT SUBSCR"),
+				debit(date(3, 10), "-20.41", "OPENAI *CHATGPT SUBSCR"),
+			},
+			wantCycle: domain.BillingCycle{Unit: domain.BillingMonth, Interval: 1},
+			wantPrice: 2041,
+			wantNext:  date(4, 10),
+		},
+		{
+			name: "weekly",
+			txns: []domain.StatementTransaction{
+				debit(date(3, 2), "-4.50", "HELLO FRESH BOX"),
+				debit(date(3, 9), "-4.50", "HELLO FRESH BOX"),
+				debit(date(3, 17), "-4.50", "HELLO FRESH BOX"),
+				debit(date(3, 23), "-4.50", "HELLO FRESH BOX"),
+				debit(date(4, 6), "-4.50", "HELLO FRESH BOX"),
+			},
+			wantCycle: domain.BillingCycle{Unit: domain.BillingWeek, Interval: 1},
+			wantPrice: 450,
+			wantNext:  date(4, 13),
+		},
+		{
+			name: "too few charges",
+			txns: []domain.StatementTransaction{
+				debit(date(1, 5), "-15.49", "NETFLIX.COM"),
+				debit(date(2, 5), "-15.49", "NETFLIX.COM"),
+			},
+			wantNone: true,
+		},
+		{
+			name: "irregular",
+			txns: []domain.StatementTransaction{
+				debit(date(1, 5), "-42.00", "REWE MARKT"),
+				debit(date(1, 19), "-17.30", "REWE MARKT"),
+				debit(date(2, 27), "-63.10", "REWE MARKT"),
+				debit(date(3, 2), "-8.99", "REWE MARKT"),
+			},
+			wantNone: true,
+		},
+		{
+			name: "credits are ignored",
+			txns: []domain.StatementTransaction{
+				debit(date(1, 25), "2500.00", "ACME SALARY"),
+				debit(date(2, 25), "2500.00", "ACME SALARY"),
+				debit(date(3, 25), "2500.00", "ACME SALARY"),
+			},
+			wantNone: true,
+		},
+		{
+			name: "stopped before the statement ends",
+			txns: []domain.StatementTransaction{
+				debit(date(1, 5), "-15.49", "NETFLIX.COM"),
+				debit(date(2, 5), "-15.49", "NETFLIX.COM"),
+				debit(date(3, 5), "-15.49", "NETFLIX.COM"),
+				debit(date(6, 20), "-3.00", "BAKERY"),
+			},
+			wantNone: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candidates := DetectRecurring(domain.Statement{Transactions: tt.txns})
+			if tt.wantNone {
+				if len(candidates) != 0 {
+					t.Fatalf("got candidates %+v, want none", candidates)
+				}
+				return
+			}
+			if len(candidates) != 1 {
+				t.Fatalf("got %d candidates %+v, want 1", len(candidates), candidates)
+			}
+			got := candidates[0]
+			if got.Billing != tt.wantCycle {
+				t.Errorf("billing = %+v, want %+v", got.Billing, tt.wantCycle)
+			}
+			if got.Price != tt.wantPrice || got.Currency != "EUR" {
+				t.Errorf("price = %d %s, want %d EUR", got.Price, got.Currency, tt.wantPrice)
+			}
+			if !got.NextChargeDate.Equal(tt.wantNext) {
+				t.Errorf("next charge = %s, want %s", got.NextChargeDate.Format("2006-01-02"), tt.wantNext.Format("2006-01-02"))
+			}
+		})
+	}
+}
+
+func TestDetectRecurringGroupsByMerchant(t *testing.T) {
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2026, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	var txns []domain.StatementTransaction
+	for month := time.January; month <= time.March; month++ {
+		txns = append(txns,
+			debit(date(month, 3), "-9.99", "CARD *4242 SPOTIFY AB"),
+			debit(date(month, 12), "-17.99", "Netflix.com Amsterdam"),
+			debit(date(month, 20), "-3.50", "BAKERY "+month.String()),
+		)
+	}
+
+	candidates := DetectRecurring(domain.Statement{CardLast4: "1111", Transactions: txns})
+	if len(candidates) != 2 {
+		t.Fatalf("got %d candidates %+v, want 2", len(candidates), candidates)
+	}
+	// Most recent first.
+	if candidates[0].ServiceName != "Netflix Amsterdam" || candidates[0].CardLast4 != "1111" {
+		t.Errorf("first = %q card %q", candidates[0].ServiceName, candidates[0].CardLast4)
+	}
+	if candidates[1].ServiceName != "Spotify" || candidates[1].CardLast4 != "4242" {
+		t.Errorf("second = %q card %q", candidates[1].ServiceName, candidates[1].CardLast4)
+	}
+}
+
+// fakeSubscriptionRepository stores what CreateMany is given, or fails with
+// err without storing anything, and lists what it stored.
+type fakeSubscriptionRepository struct {
+	SubscriptionRepository
+	err     error
+	created []domain.Subscription
+}
+
+func (r *fakeSubscriptionRepository) CreateMany(_ context.Context, subs []domain.Subscription) ([]domain.Subscription, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	for _, sub := range subs {
+		sub.ID = fmt.Sprintf("sub-%d", len(r.created)+1)
+		r.created = append(r.created, sub)
+	}
+	return r.created[len(r.created)-len(subs):], nil
+}
+
+func (r *fakeSubscriptionRepository) ListByUserID(context.Context, string) ([]domain.Subscription, error) {
+	return r.created, nil
+}
+
+// recordingPublisher records the events it is handed.
+type recordingPublisher struct {
+	events []string
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, _, event string, _ any) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+func TestConfirm(t *testing.T) {
+	input := func(name string) SubscriptionInput {
+		return SubscriptionInput{
+			ServiceName: name,
+			BankName:    "Monzo",
+			CardLast4:   "4242",
+			Billing:     "monthly",
+			ChargeDate:  "2026-01-10",
+			Price:       "9.99",
+			Currency:    "EUR",
+		}
+	}
+
+	t.Run("creates all", func(t *testing.T) {
+		repo := &fakeSubscriptionRepository{}
+		events := &recordingPublisher{}
+		uc := StatementUsecase{Subscriptions: SubscriptionUsecase{Subscriptions: repo, Events: events}}
+
+		created, err := uc.Confirm(context.Background(), "user-1", []SubscriptionInput{input("Netflix"), input("Spotify")})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(created) != 2 || created[0].ServiceName != "Netflix" || created[1].ServiceName != "Spotify" {
+			t.Fatalf("created = %+v", created)
+		}
+		if len(events.events) != 2 || events.events[0] != domain.EventSubscriptionCreated {
+			t.Errorf("events = %v", events.events)
+		}
+	})
+
+	t.Run("invalid input creates none", func(t *testing.T) {
+		repo := &fakeSubscriptionRepository{}
+		uc := StatementUsecase{Subscriptions: SubscriptionUsecase{Subscriptions: repo}}
+
+		bad := input("Spotify")
+		bad.ChargeDate = "2026-13-01"
+		_, err := uc.Confirm(context.Background(), "user-1", []SubscriptionInput{input("Netflix"), bad})
+		var fieldErr FieldError
+		if !errors.As(err, &fieldErr) || fieldErr.Field != "charge_date" {
+			t.Fatalf("err = %v, want invalid charge_date", err)
+		}
+		if len(repo.created) != 0 {
+			t.Errorf("created %d subscriptions", len(repo.created))
+		}
+	})
+
+	t.Run("failing insert publishes nothing", func(t *testing.T) {
+		repo := &fakeSubscriptionRepository{err: ErrNotFound}
+		events := &recordingPublisher{}
+		uc := StatementUsecase{Subscriptions: SubscriptionUsecase{Subscriptions: repo, Events: events}}
+
+		if _, err := uc.Confirm(context.Background(), "user-1", []SubscriptionInput{input("Netflix"), input("Spotify")}); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("err = %v, want ErrNotFound", err)
+		}
+		if len(events.events) != 0 {
+			t.Errorf("events = %v", events.events)
+		}
+	})
+}
@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"sort"
 	"strings"
 	"time"
 
@@ -10,52 +11,375 @@ import (
 
 type SubscriptionUsecase struct {
 	Subscriptions SubscriptionRepository
+	Events        EventPublisher
+	Users         UserRepository
+	// RequireVerifiedEmail refuses to create subscriptions for users who
+	// have not verified their email, so reminders never go to a mistyped
+	// address.
+	RequireVerifiedEmail bool
 }
 
-func NewSubscriptionUsecase(subscriptions SubscriptionRepository) SubscriptionUsecase {
-	return SubscriptionUsecase{Subscriptions: subscriptions}
+func NewSubscriptionUsecase(subscriptions SubscriptionRepository, events EventPublisher, users UserRepository, requireVerifiedEmail bool) SubscriptionUsecase {
+	return SubscriptionUsecase{
+		Subscriptions:        subscriptions,
+		Events:               events,
+		Users:                users,
+		RequireVerifiedEmail: requireVerifiedEmail,
+	}
 }
 
 type SubscriptionInput struct {
 	ServiceName string
-	BankName    string
-	CardLast4   string
+
+	// PaymentMethodID takes precedence; without it the subscription is
+	// charged to the user's payment method with BankName and CardLast4,
+	// which is created when missing.
+	PaymentMethodID string
+	BankName        string
+	CardLast4       string
+
 	Billing     string
+	BillingUnit string
+	Interval    int
 	ChargeDate  string
+	// Price and Currency left empty keep the stored values on update.
+	Price    string
+	Currency string
+
+	// On update an empty TrialEndDate keeps the current trial and
+	// ClearTrial removes it.
+	TrialEndDate   string
+	PostTrialPrice string
+	ClearTrial     bool
+
+	// PriceEffectiveFrom is the date Price applies from on update. Without
+	// it the price and currency must stay as stored.
+	PriceEffectiveFrom string
+
+	// CategoryID and TagIDs left nil keep the current values on update; an
+	// empty category or tag list clears them.
+	CategoryID *string
+	TagIDs     []string
+}
+
+// SubscriptionFilter narrows List down. Category and Tag match either the ID
+// or the case-insensitive name.
+type SubscriptionFilter struct {
+	Status   domain.SubscriptionStatus
+	Category string
+	Tag      string
+}
+
+// statusTransitions lists the statuses each status may move to.
+var statusTransitions = map[domain.SubscriptionStatus][]domain.SubscriptionStatus{
+	domain.StatusActive: {domain.StatusPaused, domain.StatusCancelled},
+	domain.StatusPaused: {domain.StatusActive},
 }
 
-func (u SubscriptionUsecase) List(ctx context.Context, userID string) ([]domain.Subscription, error) {
+func (u SubscriptionUsecase) List(ctx context.Context, userID string, filter SubscriptionFilter) ([]domain.Subscription, error) {
 	if strings.TrimSpace(userID) == "" {
 		return nil, ErrUnauthorized
 	}
-	return u.Subscriptions.ListByUserID(ctx, userID)
+	if filter.Status != "" && !validStatus(filter.Status) {
+		return nil, ErrInvalidInput
+	}
+	items, err := u.Subscriptions.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := today()
+	results := make([]domain.Subscription, 0, len(items))
+	for _, item := range items {
+		if !filter.matches(item) {
+			continue
+		}
+		results = append(results, withNextCharge(item, now))
+	}
+	// Subscriptions without upcoming charges go last.
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i].NextChargeDate, results[j].NextChargeDate
+		if a.IsZero() || b.IsZero() {
+			return !a.IsZero() && b.IsZero()
+		}
+		return a.Before(b)
+	})
+	return results, nil
 }
 
 func (u SubscriptionUsecase) Create(ctx context.Context, userID string, input SubscriptionInput) (domain.Subscription, error) {
+	sub, err := u.newSubscription(userID, input)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+	if err := u.checkCanCreate(ctx, userID); err != nil {
+		return domain.Subscription{}, err
+	}
+	created, err := u.Subscriptions.Create(ctx, sub)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+	created = withNextCharge(created, today())
+	publish(ctx, u.Events, userID, domain.EventSubscriptionCreated, subscriptionEvent(created))
+	return created, nil
+}
+
+// checkCanCreate returns ErrEmailNotVerified when the policy requires a
+// verified email the user does not have yet.
+func (u SubscriptionUsecase) checkCanCreate(ctx context.Context, userID string) error {
+	if !u.RequireVerifiedEmail {
+		return nil
+	}
+	user, err := u.Users.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt == nil {
+		return ErrEmailNotVerified
+	}
+	return nil
+}
+
+// newSubscription validates input for a new active subscription and seeds
+// its price history.
+func (u SubscriptionUsecase) newSubscription(userID string, input SubscriptionInput) (domain.Subscription, error) {
 	sub, err := u.toDomain(userID, input)
 	if err != nil {
 		return domain.Subscription{}, err
 	}
-	return u.Subscriptions.Create(ctx, sub)
+	sub.Status = domain.StatusActive
+	sub.StatusEffectiveDate = today()
+	if sub.ChargeDate.Before(sub.StatusEffectiveDate) {
+		sub.StatusEffectiveDate = sub.ChargeDate
+	}
+	sub.Prices = []domain.PriceChange{{Price: sub.Price, Currency: sub.Currency, EffectiveFrom: sub.ChargeDate}}
+	if sub.TrialEndDate != nil {
+		sub.Prices = append(sub.Prices, domain.PriceChange{
+			Price:         sub.PostTrialPrice,
+			Currency:      sub.Currency,
+			EffectiveFrom: *sub.TrialEndDate,
+		})
+	}
+	return sub, nil
 }
 
 func (u SubscriptionUsecase) Update(ctx context.Context, userID, id string, input SubscriptionInput) (domain.Subscription, error) {
 	if strings.TrimSpace(id) == "" {
 		return domain.Subscription{}, ErrInvalidInput
 	}
+	if strings.TrimSpace(userID) == "" {
+		return domain.Subscription{}, ErrUnauthorized
+	}
+	existing, err := u.Subscriptions.FindByID(ctx, userID, id)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+
+	priceChanged := strings.TrimSpace(input.PriceEffectiveFrom) != ""
+	if priceChanged && strings.TrimSpace(input.Price) == "" {
+		return domain.Subscription{}, invalidField("price")
+	}
+
+	// A price or currency left out keeps the stored one; a new currency
+	// needs a price in it.
+	if strings.TrimSpace(input.Currency) == "" {
+		input.Currency = existing.Currency
+	}
+	if strings.TrimSpace(input.Price) == "" {
+		currency, ok := NormalizeCurrency(input.Currency)
+		if !ok {
+			return domain.Subscription{}, invalidField("currency")
+		}
+		if currency != existing.Currency {
+			return domain.Subscription{}, invalidField("price")
+		}
+		input.Price = FormatAmount(existing.Price, existing.Currency)
+	}
 	sub, err := u.toDomain(userID, input)
 	if err != nil {
 		return domain.Subscription{}, err
 	}
+
+	// Clients send back the price they loaded, which is the stored one and
+	// not necessarily the price that applies today, so a price only changes
+	// together with the date it applies from.
+	var effectiveFrom time.Time
+	if priceChanged {
+		if effectiveFrom, err = time.Parse("2006-01-02", strings.TrimSpace(input.PriceEffectiveFrom)); err != nil {
+			return domain.Subscription{}, invalidField("price_effective_from")
+		}
+	} else if sub.Price != existing.Price || sub.Currency != existing.Currency {
+		return domain.Subscription{}, invalidField("price_effective_from")
+	}
+
+	if strings.TrimSpace(input.TrialEndDate) == "" && !input.ClearTrial {
+		sub.TrialEndDate = existing.TrialEndDate
+		sub.PostTrialPrice = existing.PostTrialPrice
+		// A kept trial still has to end on or after the charge date, which
+		// the request may have moved.
+		if sub.TrialEndDate != nil && sub.TrialEndDate.Before(sub.ChargeDate) {
+			return domain.Subscription{}, invalidField("trial_end_date")
+		}
+	}
+	if input.CategoryID == nil {
+		sub.Category = existing.Category
+	}
+	if input.TagIDs == nil {
+		sub.Tags = existing.Tags
+	}
+
+	// Only changed prices are appended, so the stored history keeps the
+	// amounts of earlier charges intact. A trial set by the request that
+	// ends on the effective date switches to its post-trial price that day.
 	sub.ID = id
-	return u.Subscriptions.Update(ctx, sub)
+	sub.Prices = nil
+	if existing.TrialEndDate != nil && (sub.TrialEndDate == nil || !truncateDay(*sub.TrialEndDate).Equal(truncateDay(*existing.TrialEndDate))) {
+		// The repository drops the post-trial price of a trial that is
+		// cleared or moved, so changes are compared with the history
+		// without it.
+		existing = withoutTrialPrice(existing)
+	}
+	newTrial := strings.TrimSpace(input.TrialEndDate) != ""
+	if priceChanged && !(newTrial && sub.TrialEndDate.Equal(effectiveFrom)) {
+		if amount, currency := chargePrice(existing, effectiveFrom); amount != sub.Price || currency != sub.Currency {
+			sub.Prices = append(sub.Prices, domain.PriceChange{Price: sub.Price, Currency: sub.Currency, EffectiveFrom: effectiveFrom})
+		}
+	}
+	if newTrial {
+		if amount, currency := chargePrice(existing, *sub.TrialEndDate); amount != sub.PostTrialPrice || currency != sub.Currency {
+			sub.Prices = append(sub.Prices, domain.PriceChange{Price: sub.PostTrialPrice, Currency: sub.Currency, EffectiveFrom: *sub.TrialEndDate})
+		}
+	}
+	updated, err := u.Subscriptions.Update(ctx, sub)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+	updated = withNextCharge(updated, today())
+	publish(ctx, u.Events, userID, domain.EventSubscriptionUpdated, subscriptionEvent(updated))
+	return updated, nil
+}
+
+// Prices returns the price history of the subscription, oldest first.
+func (u SubscriptionUsecase) Prices(ctx context.Context, userID, id string) ([]domain.PriceChange, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, ErrUnauthorized
+	}
+	if strings.TrimSpace(id) == "" {
+		return nil, ErrInvalidInput
+	}
+	sub, err := u.Subscriptions.FindByID(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return sub.Prices, nil
 }
 
 func (u SubscriptionUsecase) Delete(ctx context.Context, userID, id string) error {
 	if strings.TrimSpace(id) == "" {
 		return ErrInvalidInput
 	}
-	return u.Subscriptions.Delete(ctx, userID, id)
+	sub, err := u.Subscriptions.FindByID(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if err := u.Subscriptions.Delete(ctx, userID, id); err != nil {
+		return err
+	}
+	publish(ctx, u.Events, userID, domain.EventSubscriptionDeleted, subscriptionEvent(withNextCharge(sub, today())))
+	return nil
+}
+
+// Pause stops charging the subscription from the effective date.
+func (u SubscriptionUsecase) Pause(ctx context.Context, userID, id, effectiveDate string) (domain.Subscription, error) {
+	return u.transition(ctx, userID, id, domain.StatusPaused, effectiveDate)
+}
+
+// Resume charges a paused subscription again from the effective date.
+func (u SubscriptionUsecase) Resume(ctx context.Context, userID, id, effectiveDate string) (domain.Subscription, error) {
+	return u.transition(ctx, userID, id, domain.StatusActive, effectiveDate)
+}
+
+// Cancel ends an active subscription on the effective date while keeping it
+// and its history around.
+func (u SubscriptionUsecase) Cancel(ctx context.Context, userID, id, effectiveDate string) (domain.Subscription, error) {
+	return u.transition(ctx, userID, id, domain.StatusCancelled, effectiveDate)
+}
+
+func (u SubscriptionUsecase) transition(ctx context.Context, userID, id string, to domain.SubscriptionStatus, effectiveDate string) (domain.Subscription, error) {
+	if strings.TrimSpace(userID) == "" {
+		return domain.Subscription{}, ErrUnauthorized
+	}
+	if strings.TrimSpace(id) == "" {
+		return domain.Subscription{}, ErrInvalidInput
+	}
+
+	effective := today()
+	if value := strings.TrimSpace(effectiveDate); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return domain.Subscription{}, ErrInvalidInput
+		}
+		effective = parsed
+	}
+
+	sub, err := u.Subscriptions.FindByID(ctx, userID, id)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+	if !canTransition(sub.Status, to) || effective.Before(truncateDay(sub.StatusEffectiveDate)) {
+		return domain.Subscription{}, ErrInvalidTransition
+	}
+
+	from := sub.Status
+	sub.Status = to
+	sub.StatusEffectiveDate = effective
+	updated, err := u.Subscriptions.UpdateStatus(ctx, sub, from)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+	updated = withNextCharge(updated, today())
+	publish(ctx, u.Events, userID, domain.EventSubscriptionUpdated, subscriptionEvent(updated))
+	return updated, nil
+}
+
+func (f SubscriptionFilter) matches(sub domain.Subscription) bool {
+	if f.Status != "" && sub.Status != f.Status {
+		return false
+	}
+	if f.Category != "" && (sub.Category == nil || !labelMatches(sub.Category.ID, sub.Category.Name, f.Category)) {
+		return false
+	}
+	if f.Tag != "" {
+		for _, tag := range sub.Tags {
+			if labelMatches(tag.ID, tag.Name, f.Tag) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func labelMatches(id, name, value string) bool {
+	value = strings.TrimSpace(value)
+	return id == value || strings.EqualFold(name, value)
+}
+
+func canTransition(from, to domain.SubscriptionStatus) bool {
+	for _, allowed := range statusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+func validStatus(status domain.SubscriptionStatus) bool {
+	switch status {
+	case domain.StatusActive, domain.StatusPaused, domain.StatusCancelled:
+		return true
+	}
+	return false
 }
 
 func (u SubscriptionUsecase) toDomain(userID string, input SubscriptionInput) (domain.Subscription, error) {
@@ -64,28 +388,146 @@ func (u SubscriptionUsecase) toDomain(userID string, input SubscriptionInput) (d
 	}
 
 	serviceName := strings.TrimSpace(input.ServiceName)
+	paymentMethodID := strings.TrimSpace(input.PaymentMethodID)
 	bankName := strings.TrimSpace(input.BankName)
 	cardLast4 := strings.TrimSpace(input.CardLast4)
-	billing := strings.ToLower(strings.TrimSpace(input.Billing))
-	if serviceName == "" || bankName == "" || len(cardLast4) != 4 {
-		return domain.Subscription{}, ErrInvalidInput
+	if serviceName == "" {
+		return domain.Subscription{}, invalidField("service_name")
+	}
+	if paymentMethodID == "" {
+		if bankName == "" {
+			return domain.Subscription{}, invalidField("bank_name")
+		}
+		// The repository creates a payment method from these, so they are
+		// held to the same rules as one created directly.
+		if len(cardLast4) != 4 || !isDigits(cardLast4) {
+			return domain.Subscription{}, invalidField("card_last4")
+		}
 	}
 
-	if billing != "monthly" && billing != "yearly" {
-		return domain.Subscription{}, ErrInvalidInput
+	billing, err := parseBillingCycle(input.Billing, input.BillingUnit, input.Interval)
+	if err != nil {
+		return domain.Subscription{}, invalidField("billing")
 	}
 
 	chargeDate, err := time.Parse("2006-01-02", strings.TrimSpace(input.ChargeDate))
 	if err != nil {
-		return domain.Subscription{}, ErrInvalidInput
+		return domain.Subscription{}, invalidField("charge_date")
 	}
 
-	return domain.Subscription{
+	currency := DefaultCurrency
+	if strings.TrimSpace(input.Currency) != "" {
+		var ok bool
+		if currency, ok = NormalizeCurrency(input.Currency); !ok {
+			return domain.Subscription{}, invalidField("currency")
+		}
+	}
+
+	var price int64
+	if strings.TrimSpace(input.Price) != "" {
+		if price, err = ParseAmount(input.Price, currency); err != nil {
+			return domain.Subscription{}, invalidField("price")
+		}
+	}
+
+	sub := domain.Subscription{
 		UserID:      userID,
 		ServiceName: serviceName,
-		BankName:    bankName,
-		CardLast4:   cardLast4,
 		Billing:     billing,
 		ChargeDate:  chargeDate,
-	}, nil
+		Price:       price,
+		Currency:    currency,
+
+		PaymentMethodID: paymentMethodID,
+		BankName:        bankName,
+		CardLast4:       cardLast4,
+	}
+
+	if input.CategoryID != nil && strings.TrimSpace(*input.CategoryID) != "" {
+		sub.Category = &domain.Category{ID: strings.TrimSpace(*input.CategoryID), UserID: userID}
+	}
+	seen := map[string]bool{}
+	for _, id := range input.TagIDs {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			return domain.Subscription{}, invalidField("tag_ids")
+		}
+		if !seen[id] {
+			seen[id] = true
+			sub.Tags = append(sub.Tags, domain.Tag{ID: id, UserID: userID})
+		}
+	}
+
+	if value := strings.TrimSpace(input.TrialEndDate); value != "" {
+		trialEnd, err := time.Parse("2006-01-02", value)
+		if err != nil || trialEnd.Before(chargeDate) {
+			return domain.Subscription{}, invalidField("trial_end_date")
+		}
+		sub.TrialEndDate = &trialEnd
+		sub.PostTrialPrice = price
+		if strings.TrimSpace(input.PostTrialPrice) != "" {
+			if sub.PostTrialPrice, err = ParseAmount(input.PostTrialPrice, currency); err != nil {
+				return domain.Subscription{}, invalidField("post_trial_price")
+			}
+		}
+	}
+
+	return sub, nil
+}
+
+// TrialsEndingWithin returns trials converting into a paid charge between
+// today and the given number of days ahead, soonest first.
+func (u SubscriptionUsecase) TrialsEndingWithin(ctx context.Context, userID string, days int) ([]domain.Subscription, error) {
+	if days < 0 {
+		return nil, ErrInvalidInput
+	}
+	items, err := u.List(ctx, userID, SubscriptionFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	now := today()
+	until := now.AddDate(0, 0, days)
+	var results []domain.Subscription
+	for _, item := range items {
+		if item.TrialEndDate == nil || item.Status != domain.StatusActive {
+			continue
+		}
+		trialEnd := truncateDay(*item.TrialEndDate)
+		if trialEnd.Before(now) || trialEnd.After(until) {
+			continue
+		}
+		results = append(results, item)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].TrialEndDate.Before(*results[j].TrialEndDate)
+	})
+	return results, nil
+}
+
+// withoutTrialPrice returns sub without its trial and the price history
+// entry its trial end switched to the post-trial price with.
+func withoutTrialPrice(sub domain.Subscription) domain.Subscription {
+	trialEnd := truncateDay(*sub.TrialEndDate)
+	prices := make([]domain.PriceChange, 0, len(sub.Prices))
+	for _, price := range sub.Prices {
+		if !truncateDay(price.EffectiveFrom).Equal(trialEnd) {
+			prices = append(prices, price)
+		}
+	}
+	sub.Prices = prices
+	sub.TrialEndDate = nil
+	sub.PostTrialPrice = 0
+	return sub
+}
+
+func withNextCharge(sub domain.Subscription, now time.Time) domain.Subscription {
+	sub.NextChargeDate = time.Time{}
+	sub.NextChargeAmount = 0
+	sub.NextChargeCurrency = sub.Currency
+	if dates := upcomingCharges(sub, now, 1); len(dates) > 0 {
+		sub.NextChargeDate = dates[0]
+		sub.NextChargeAmount, sub.NextChargeCurrency = chargePrice(sub, dates[0])
+	}
+	return sub
 }
@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+
+	"subscribe_tracker/backend/internal/domain"
+)
+
+type TagUsecase struct {
+	Tags TagRepository
+}
+
+func NewTagUsecase(tags TagRepository) TagUsecase {
+	return TagUsecase{Tags: tags}
+}
+
+func (u TagUsecase) List(ctx context.Context, userID string) ([]domain.Tag, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, ErrUnauthorized
+	}
+	return u.Tags.ListByUserID(ctx, userID)
+}
+
+func (u TagUsecase) Create(ctx context.Context, userID, name string) (domain.Tag, error) {
+	name, err := labelName(userID, name)
+	if err != nil {
+		return domain.Tag{}, err
+	}
+	return u.Tags.Create(ctx, domain.Tag{UserID: userID, Name: name})
+}
+
+func (u TagUsecase) Rename(ctx context.Context, userID, id, name string) (domain.Tag, error) {
+	name, err := labelName(userID, name)
+	if err != nil {
+		return domain.Tag{}, err
+	}
+	if strings.TrimSpace(id) == "" {
+		return domain.Tag{}, ErrInvalidInput
+	}
+	return u.Tags.Update(ctx, domain.Tag{ID: id, UserID: userID, Name: name})
+}
+
+// Delete removes the tag from the user's tags and from every subscription.
+func (u TagUsecase) Delete(ctx context.Context, userID, id string) error {
+	if strings.TrimSpace(userID) == "" {
+		return ErrUnauthorized
+	}
+	if strings.TrimSpace(id) == "" {
+		return ErrInvalidInput
+	}
+	return u.Tags.Delete(ctx, userID, id)
+}
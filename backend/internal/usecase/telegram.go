@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"time"
+
+	"subscribe_tracker/backend/internal/domain"
+)
+
+const (
+	linkCodeLength = 8
+	linkCodeTTL    = 15 * time.Minute
+	// linkCodeAlphabet leaves out characters that are easy to confuse.
+	linkCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+)
+
+// TelegramUsecase links Telegram chats to accounts.
+type TelegramUsecase struct {
+	Links TelegramRepository
+	Users UserRepository
+}
+
+func NewTelegramUsecase(links TelegramRepository, users UserRepository) TelegramUsecase {
+	return TelegramUsecase{
+		Links: links,
+		Users: users,
+	}
+}
+
+// CreateLinkCode returns a one-time code the user sends to the bot to link
+// their chat, valid until the returned time.
+func (u TelegramUsecase) CreateLinkCode(ctx context.Context, userID string) (string, time.Time, error) {
+	if strings.TrimSpace(userID) == "" {
+		return "", time.Time{}, ErrUnauthorized
+	}
+	code, err := randomCode(linkCodeLength, linkCodeAlphabet)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	expiresAt := time.Now().Add(linkCodeTTL)
+	if err := u.Links.CreateLinkCode(ctx, userID, hashToken(code), expiresAt); err != nil {
+		return "", time.Time{}, err
+	}
+	return code, expiresAt, nil
+}
+
+// LinkChat links the chat to the user who created code.
+func (u TelegramUsecase) LinkChat(ctx context.Context, chatID int64, code string) (domain.User, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return domain.User{}, ErrInvalidInput
+	}
+	userID, err := u.Links.ConsumeLinkCode(ctx, hashToken(code), chatID)
+	if err != nil {
+		return domain.User{}, err
+	}
+	return u.Users.FindByID(ctx, userID)
+}
+
+// ChatUser returns the user the chat is linked to, or ErrNotFound.
+func (u TelegramUsecase) ChatUser(ctx context.Context, chatID int64) (domain.User, error) {
+	userID, err := u.Links.FindUserIDByChat(ctx, chatID)
+	if err != nil {
+		return domain.User{}, err
+	}
+	return u.Users.FindByID(ctx, userID)
+}
+
+// Chat returns the chat linked to the user, or ErrNotFound.
+func (u TelegramUsecase) Chat(ctx context.Context, userID string) (int64, error) {
+	if strings.TrimSpace(userID) == "" {
+		return 0, ErrUnauthorized
+	}
+	return u.Links.FindChatByUserID(ctx, userID)
+}
+
+func (u TelegramUsecase) Unlink(ctx context.Context, userID string) error {
+	if strings.TrimSpace(userID) == "" {
+		return ErrUnauthorized
+	}
+	return u.Links.Unlink(ctx, userID)
+}
+
+// hashToken returns the hex SHA-256 of a random secret such as a one-time
+// code. Secrets with enough entropy need no salt or slow hash.
+func hashToken(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomCode(length int, alphabet string) (string, error) {
+	max := big.NewInt(int64(len(alphabet)))
+	code := make([]byte, length)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = alphabet[n.Int64()]
+	}
+	return string(code), nil
+}
@@ -0,0 +1,297 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"subscribe_tracker/backend/internal/totp"
+)
+
+const (
+	totpIssuer = "Subscribe Tracker"
+
+	recoveryCodeCount  = 10
+	recoveryCodeLength = 10
+
+	purposeMFALogin = "mfa_login"
+	mfaTicketTTL    = 5 * time.Minute
+	// mfaTicketAttempts is how many wrong codes a ticket survives before the
+	// password has to be entered again.
+	mfaTicketAttempts = 5
+)
+
+// TwoFactorUsecase manages TOTP second factors and their recovery codes.
+type TwoFactorUsecase struct {
+	Users     UserRepository
+	TwoFactor TwoFactorRepository
+	Tickets   UserTokenRepository
+	// Guard limits password and code guesses when a change is confirmed,
+	// the same way it does for logins.
+	Guard LoginGuard
+}
+
+func NewTwoFactorUsecase(users UserRepository, twoFactor TwoFactorRepository, tickets UserTokenRepository, guard LoginGuard) TwoFactorUsecase {
+	return TwoFactorUsecase{
+		Users:     users,
+		TwoFactor: twoFactor,
+		Tickets:   tickets,
+		Guard:     guard,
+	}
+}
+
+type TwoFactorStatus struct {
+	Enabled bool
+	// Pending is true between Enroll and Confirm.
+	Pending           bool
+	RecoveryCodesLeft int
+}
+
+type TwoFactorEnrollment struct {
+	Secret string
+	URI    string
+}
+
+func (u TwoFactorUsecase) Status(ctx context.Context, userID string) (TwoFactorStatus, error) {
+	if strings.TrimSpace(userID) == "" {
+		return TwoFactorStatus{}, ErrUnauthorized
+	}
+	tf, err := u.TwoFactor.Find(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return TwoFactorStatus{}, nil
+		}
+		return TwoFactorStatus{}, err
+	}
+	return TwoFactorStatus{
+		Enabled:           tf.ConfirmedAt != nil,
+		Pending:           tf.ConfirmedAt == nil,
+		RecoveryCodesLeft: tf.RecoveryCodesLeft,
+	}, nil
+}
+
+// Enroll generates a new secret for the user to add to an authenticator app.
+// Two-factor authentication is enabled only once Confirm gets a code of it.
+func (u TwoFactorUsecase) Enroll(ctx context.Context, userID string) (TwoFactorEnrollment, error) {
+	if strings.TrimSpace(userID) == "" {
+		return TwoFactorEnrollment{}, ErrUnauthorized
+	}
+	user, err := u.Users.FindByID(ctx, userID)
+	if err != nil {
+		return TwoFactorEnrollment{}, err
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return TwoFactorEnrollment{}, err
+	}
+	if err := u.TwoFactor.SetPending(ctx, userID, secret); err != nil {
+		return TwoFactorEnrollment{}, err
+	}
+	return TwoFactorEnrollment{Secret: secret, URI: totp.URI(totpIssuer, user.Email, secret)}, nil
+}
+
+// Confirm enables two-factor authentication with the first code of the
+// enrolled secret and returns the recovery codes, which are not shown again.
+func (u TwoFactorUsecase) Confirm(ctx context.Context, userID, code string) ([]string, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, ErrUnauthorized
+	}
+	tf, err := u.TwoFactor.Find(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if tf.ConfirmedAt != nil {
+		return nil, ErrTwoFactorEnabled
+	}
+	step, ok := totp.Validate(tf.Secret, code, time.Now())
+	if !ok {
+		return nil, invalidField("code")
+	}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := u.TwoFactor.Confirm(ctx, userID, step, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable turns two-factor authentication off. It takes the password and a
+// code, so a hijacked session alone cannot do it.
+func (u TwoFactorUsecase) Disable(ctx context.Context, userID, password, code string, client ClientInfo) error {
+	if err := u.reauthenticate(ctx, userID, password, code, client); err != nil {
+		return err
+	}
+	return u.TwoFactor.Delete(ctx, userID)
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes of the user. Like
+// Disable it takes the password and a code.
+func (u TwoFactorUsecase) RegenerateRecoveryCodes(ctx context.Context, userID, password, code string, client ClientInfo) ([]string, error) {
+	if err := u.reauthenticate(ctx, userID, password, code, client); err != nil {
+		return nil, err
+	}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := u.TwoFactor.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// enabled reports whether the user has to enter a code after the password.
+func (u TwoFactorUsecase) enabled(ctx context.Context, userID string) (bool, error) {
+	tf, err := u.TwoFactor.Find(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return tf.ConfirmedAt != nil, nil
+}
+
+// issueTicket returns a ticket proving the password of the user was checked,
+// to be exchanged together with a code for a session.
+func (u TwoFactorUsecase) issueTicket(ctx context.Context, userID string) (string, error) {
+	ticket, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+	if err := u.Tickets.Create(ctx, userID, purposeMFALogin, hashToken(ticket), time.Now().Add(mfaTicketTTL)); err != nil {
+		return "", err
+	}
+	return ticket, nil
+}
+
+// ticketUser returns the user an unexpired ticket was issued to.
+func (u TwoFactorUsecase) ticketUser(ctx context.Context, ticket string) (string, error) {
+	userID, err := u.Tickets.Find(ctx, purposeMFALogin, hashToken(strings.TrimSpace(ticket)))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return "", ErrUnauthorized
+		}
+		return "", err
+	}
+	return userID, nil
+}
+
+// redeemTicket checks code for userID, the user of ticket, and uses the
+// ticket up. A wrong code counts against the ticket.
+func (u TwoFactorUsecase) redeemTicket(ctx context.Context, ticket, userID, code string) error {
+	ticketHash := hashToken(strings.TrimSpace(ticket))
+	if err := u.verify(ctx, userID, code); err != nil {
+		if errors.Is(err, ErrUnauthorized) {
+			if err := u.Tickets.RecordFailure(ctx, purposeMFALogin, ticketHash, mfaTicketAttempts); err != nil {
+				return err
+			}
+		}
+		return err
+	}
+	consumed, err := u.Tickets.Consume(ctx, purposeMFALogin, ticketHash)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return ErrUnauthorized
+		}
+		return err
+	}
+	if consumed != userID {
+		return ErrUnauthorized
+	}
+	return nil
+}
+
+// reauthenticate checks the password and a code of the user. Wrong ones
+// count as failed logins of the account, so a hijacked session cannot guess
+// them any faster than the login form.
+func (u TwoFactorUsecase) reauthenticate(ctx context.Context, userID, password, code string, client ClientInfo) error {
+	if strings.TrimSpace(userID) == "" {
+		return ErrUnauthorized
+	}
+	user, err := u.Users.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := u.Guard.check(ctx, user.Email, client); err != nil {
+		return err
+	}
+	err = ErrUnauthorized
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) == nil {
+		err = u.verify(ctx, userID, code)
+	}
+	if errors.Is(err, ErrUnauthorized) {
+		if err := u.Guard.fail(ctx, user.Email, &user, client); err != nil {
+			return err
+		}
+		return ErrUnauthorized
+	}
+	if err != nil {
+		return err
+	}
+	return u.Guard.succeed(ctx, user.Email)
+}
+
+// verify accepts either a current TOTP code or an unused recovery code and
+// uses it up. It returns ErrUnauthorized for anything else.
+func (u TwoFactorUsecase) verify(ctx context.Context, userID, code string) error {
+	tf, err := u.TwoFactor.Find(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return ErrUnauthorized
+		}
+		return err
+	}
+	if tf.ConfirmedAt == nil {
+		return ErrUnauthorized
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits && isDigits(code) {
+		step, ok := totp.Validate(tf.Secret, code, time.Now())
+		if !ok || step <= tf.LastStep {
+			return ErrUnauthorized
+		}
+		err = u.TwoFactor.UseStep(ctx, userID, step)
+	} else {
+		err = u.TwoFactor.UseRecoveryCode(ctx, userID, hashToken(normalizeRecoveryCode(code)))
+	}
+	if errors.Is(err, ErrNotFound) {
+		return ErrUnauthorized
+	}
+	return err
+}
+
+// newRecoveryCodes returns fresh recovery codes formatted for display and
+// their hashes.
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := randomCode(recoveryCodeLength, linkCodeAlphabet)
+		if err != nil {
+			return nil, nil, err
+		}
+		half := recoveryCodeLength / 2
+		codes = append(codes, code[:half]+"-"+code[half:])
+		hashes = append(hashes, hashToken(code))
+	}
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode drops the separators and case users may type a
+// recovery code with.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToUpper(code)
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, code)
+}
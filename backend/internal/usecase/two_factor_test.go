@@ -0,0 +1,145 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"subscribe_tracker/backend/internal/domain"
+	"subscribe_tracker/backend/internal/totp"
+)
+
+// fakeTwoFactorRepository keeps the second factor of a single user.
+type fakeTwoFactorRepository struct {
+	tf    domain.TwoFactor
+	codes map[string]bool // hash -> used
+}
+
+func (r *fakeTwoFactorRepository) Find(ctx context.Context, userID string) (domain.TwoFactor, error) {
+	if r.tf.UserID != userID {
+		return domain.TwoFactor{}, ErrNotFound
+	}
+	tf := r.tf
+	for _, used := range r.codes {
+		if !used {
+			tf.RecoveryCodesLeft++
+		}
+	}
+	return tf, nil
+}
+
+func (r *fakeTwoFactorRepository) SetPending(ctx context.Context, userID, secret string) error {
+	r.tf = domain.TwoFactor{UserID: userID, Secret: secret}
+	return nil
+}
+
+func (r *fakeTwoFactorRepository) Confirm(ctx context.Context, userID string, step int64, codeHashes []string) error {
+	now := time.Now()
+	r.tf.ConfirmedAt = &now
+	r.tf.LastStep = step
+	return r.ReplaceRecoveryCodes(ctx, userID, codeHashes)
+}
+
+func (r *fakeTwoFactorRepository) UseStep(ctx context.Context, userID string, step int64) error {
+	if step <= r.tf.LastStep {
+		return ErrNotFound
+	}
+	r.tf.LastStep = step
+	return nil
+}
+
+func (r *fakeTwoFactorRepository) UseRecoveryCode(ctx context.Context, userID, codeHash string) error {
+	used, ok := r.codes[codeHash]
+	if !ok || used {
+		return ErrNotFound
+	}
+	r.codes[codeHash] = true
+	return nil
+}
+
+func (r *fakeTwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error {
+	r.codes = make(map[string]bool, len(codeHashes))
+	for _, hash := range codeHashes {
+		r.codes[hash] = false
+	}
+	return nil
+}
+
+func (r *fakeTwoFactorRepository) Delete(ctx context.Context, userID string) error {
+	r.tf = domain.TwoFactor{}
+	r.codes = nil
+	return nil
+}
+
+func TestRecoveryCodeIsSingleUse(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeTwoFactorRepository{}
+	u := TwoFactorUsecase{TwoFactor: repo}
+
+	if err := repo.SetPending(ctx, "user-1", mustSecret(t)); err != nil {
+		t.Fatal(err)
+	}
+	code, err := totp.Code(repo.tf.Secret, totp.Step(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	codes, err := u.Confirm(ctx, "user-1", code)
+	if err != nil {
+		t.Fatalf("Confirm: %v", err)
+	}
+	if len(codes) != recoveryCodeCount {
+		t.Fatalf("got %d recovery codes, want %d", len(codes), recoveryCodeCount)
+	}
+
+	if err := u.verify(ctx, "user-1", codes[0]); err != nil {
+		t.Fatalf("first use: %v", err)
+	}
+	// Typed differently, it is still the same code.
+	again := strings.ToLower(strings.ReplaceAll(codes[0], "-", " "))
+	if err := u.verify(ctx, "user-1", again); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("second use: err = %v, want ErrUnauthorized", err)
+	}
+	if err := u.verify(ctx, "user-1", codes[1]); err != nil {
+		t.Errorf("other code: %v", err)
+	}
+
+	status, err := u.Status(ctx, "user-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.RecoveryCodesLeft != recoveryCodeCount-2 {
+		t.Errorf("codes left = %d, want %d", status.RecoveryCodesLeft, recoveryCodeCount-2)
+	}
+}
+
+func TestTOTPCodeIsSingleUse(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeTwoFactorRepository{}
+	u := TwoFactorUsecase{TwoFactor: repo}
+	secret := mustSecret(t)
+	now := time.Now()
+	confirmedAt := now.Add(-time.Hour)
+	repo.tf = domain.TwoFactor{UserID: "user-1", Secret: secret, ConfirmedAt: &confirmedAt}
+
+	code, err := totp.Code(secret, totp.Step(now))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := u.verify(ctx, "user-1", code); err != nil {
+		t.Fatalf("first use: %v", err)
+	}
+	if err := u.verify(ctx, "user-1", code); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("second use: err = %v, want ErrUnauthorized", err)
+	}
+}
+
+func mustSecret(t *testing.T) string {
+	t.Helper()
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return secret
+}
@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"subscribe_tracker/backend/internal/domain"
+)
+
+const (
+	purposeEmailVerification = "email_verification"
+	emailVerificationTTL     = 48 * time.Hour
+)
+
+// VerificationUsecase confirms that users own the email they registered
+// with.
+type VerificationUsecase struct {
+	Users  UserRepository
+	Tokens UserTokenRepository
+	Sender AccountSender
+	// AppURL is the address of the frontend links point at.
+	AppURL string
+}
+
+func NewVerificationUsecase(users UserRepository, tokens UserTokenRepository, sender AccountSender, appURL string) VerificationUsecase {
+	return VerificationUsecase{
+		Users:  users,
+		Tokens: tokens,
+		Sender: sender,
+		AppURL: appURL,
+	}
+}
+
+// Request mails a verification link to the user, replacing links sent
+// before. It returns false without sending anything when the email is
+// already verified.
+func (u VerificationUsecase) Request(ctx context.Context, userID string) (bool, error) {
+	if strings.TrimSpace(userID) == "" {
+		return false, ErrUnauthorized
+	}
+	user, err := u.Users.FindByID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if user.EmailVerifiedAt != nil {
+		return false, nil
+	}
+	if err := u.send(ctx, user); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Verify marks the email of the user the token was sent to as verified.
+// Tokens work once.
+func (u VerificationUsecase) Verify(ctx context.Context, token string) (domain.User, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return domain.User{}, ErrUnauthorized
+	}
+	userID, err := u.Tokens.Consume(ctx, purposeEmailVerification, hashToken(token))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return domain.User{}, ErrUnauthorized
+		}
+		return domain.User{}, err
+	}
+	return u.Users.MarkEmailVerified(ctx, userID)
+}
+
+// send stores a new token and mails its link in the background.
+func (u VerificationUsecase) send(ctx context.Context, user domain.User) error {
+	token, err := randomToken(32)
+	if err != nil {
+		return err
+	}
+	expiresAt := time.Now().Add(emailVerificationTTL)
+	if err := u.Tokens.Create(ctx, user.ID, purposeEmailVerification, hashToken(token), expiresAt); err != nil {
+		return err
+	}
+
+	link := accountLink(u.AppURL, "/verify-email", token)
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		if err := u.Sender.SendEmailVerification(ctx, user, link, expiresAt); err != nil {
+			log.Printf("email verification for %s: %v", user.Email, err)
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,317 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"subscribe_tracker/backend/internal/domain"
+)
+
+const (
+	// maxDeliveryAttempts bounds webhook retries; the delay doubles after
+	// each failed attempt starting from deliveryRetryDelay, so the last try
+	// happens about an hour after the event.
+	maxDeliveryAttempts = 8
+	deliveryRetryDelay  = 30 * time.Second
+	deliveryLease       = 2 * time.Minute
+)
+
+// Signature headers of webhook requests. SignatureHeader carries
+// "sha256=" and the hex HMAC-SHA256 of "<timestamp>.<body>" keyed with the
+// webhook secret, where timestamp is the value of TimestampHeader.
+const (
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+	TimestampHeader = "X-Webhook-Timestamp"
+	SignatureHeader = "X-Webhook-Signature"
+)
+
+var webhookEvents = map[string]bool{
+	domain.EventSubscriptionCreated: true,
+	domain.EventSubscriptionUpdated: true,
+	domain.EventSubscriptionDeleted: true,
+	domain.EventChargeUpcoming:      true,
+}
+
+type WebhookUsecase struct {
+	Webhooks WebhookRepository
+	Client   HTTPClient
+}
+
+func NewWebhookUsecase(webhooks WebhookRepository, client HTTPClient) WebhookUsecase {
+	return WebhookUsecase{
+		Webhooks: webhooks,
+		Client:   client,
+	}
+}
+
+// WebhookInput leaves the secret unchanged on update and generates one on
+// create when Secret is empty.
+type WebhookInput struct {
+	URL    string
+	Secret string
+	Events []string
+	Active *bool
+}
+
+func (u WebhookUsecase) List(ctx context.Context, userID string) ([]domain.Webhook, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, ErrUnauthorized
+	}
+	return u.Webhooks.ListByUserID(ctx, userID)
+}
+
+func (u WebhookUsecase) Create(ctx context.Context, userID string, input WebhookInput) (domain.Webhook, error) {
+	webhook, err := toWebhook(userID, input)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+	if webhook.Secret == "" {
+		if webhook.Secret, err = randomToken(32); err != nil {
+			return domain.Webhook{}, err
+		}
+	}
+	return u.Webhooks.Create(ctx, webhook)
+}
+
+func (u WebhookUsecase) Update(ctx context.Context, userID, id string, input WebhookInput) (domain.Webhook, error) {
+	webhook, err := toWebhook(userID, input)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+	if strings.TrimSpace(id) == "" {
+		return domain.Webhook{}, ErrInvalidInput
+	}
+	webhook.ID = id
+	return u.Webhooks.Update(ctx, webhook)
+}
+
+func (u WebhookUsecase) Delete(ctx context.Context, userID, id string) error {
+	if strings.TrimSpace(userID) == "" {
+		return ErrUnauthorized
+	}
+	if strings.TrimSpace(id) == "" {
+		return ErrInvalidInput
+	}
+	return u.Webhooks.Delete(ctx, userID, id)
+}
+
+// Deliveries returns the latest deliveries of a webhook, newest first.
+func (u WebhookUsecase) Deliveries(ctx context.Context, userID, webhookID string) ([]domain.WebhookDelivery, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, ErrUnauthorized
+	}
+	if strings.TrimSpace(webhookID) == "" {
+		return nil, ErrInvalidInput
+	}
+	return u.Webhooks.ListDeliveries(ctx, userID, webhookID)
+}
+
+// Redeliver sends the payload of a past delivery again as a new delivery.
+func (u WebhookUsecase) Redeliver(ctx context.Context, userID, webhookID, deliveryID string) (domain.WebhookDelivery, error) {
+	if strings.TrimSpace(userID) == "" {
+		return domain.WebhookDelivery{}, ErrUnauthorized
+	}
+	if strings.TrimSpace(webhookID) == "" || strings.TrimSpace(deliveryID) == "" {
+		return domain.WebhookDelivery{}, ErrInvalidInput
+	}
+	return u.Webhooks.Redeliver(ctx, userID, webhookID, deliveryID)
+}
+
+// Publish queues the event for the webhooks of the user subscribed to it.
+func (u WebhookUsecase) Publish(ctx context.Context, userID, event string, data any) error {
+	payload, err := json.Marshal(struct {
+		Event      string    `json:"event"`
+		OccurredAt time.Time `json:"occurred_at"`
+		Data       any       `json:"data"`
+	}{event, time.Now().UTC(), data})
+	if err != nil {
+		return err
+	}
+	return u.Webhooks.Enqueue(ctx, userID, event, payload)
+}
+
+// Deliver claims up to limit due deliveries and posts them. It returns how
+// many were claimed; failed deliveries are retried with exponential backoff.
+func (u WebhookUsecase) Deliver(ctx context.Context, limit int) (int, error) {
+	deliveries, err := u.Webhooks.ClaimDue(ctx, limit, deliveryLease)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, delivery := range deliveries {
+		status, err := u.post(ctx, delivery)
+		if err != nil {
+			var retryAt *time.Time
+			if delivery.Attempts < maxDeliveryAttempts {
+				next := time.Now().Add(deliveryRetryDelay << (delivery.Attempts - 1))
+				retryAt = &next
+			}
+			if markErr := u.Webhooks.MarkFailed(ctx, delivery.ID, status, retryAt, err.Error()); markErr != nil {
+				return len(deliveries), markErr
+			}
+			continue
+		}
+		if err := u.Webhooks.MarkDelivered(ctx, delivery.ID, status); err != nil {
+			return len(deliveries), err
+		}
+	}
+	return len(deliveries), nil
+}
+
+func (u WebhookUsecase) post(ctx context.Context, delivery domain.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "subscribe-tracker-webhooks")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, delivery.ID)
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, "sha256="+SignWebhook(delivery.Secret, timestamp, delivery.Payload))
+
+	resp, err := u.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// NewWebhookClient returns the client webhooks are posted with. It only
+// connects to public addresses, checked after DNS resolution so a name
+// cannot be rebound to an internal one, goes direct instead of through a
+// proxy and does not follow redirects.
+func NewWebhookClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			addr, err := netip.ParseAddrPort(address)
+			if err != nil || !publicAddr(addr.Addr()) {
+				return errForbiddenAddress
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+var errForbiddenAddress = errors.New("address is not public")
+
+// sharedAddressSpace is the carrier-grade NAT range, which net/netip does
+// not count as private.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// publicAddr reports whether addr may be reached from webhooks: loopback,
+// private, link-local (cloud metadata included) and other special-purpose
+// addresses are not.
+func publicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() &&
+		!addr.IsPrivate() &&
+		!sharedAddressSpace.Contains(addr) &&
+		!(addr.Is4() && addr.As4()[0] == 0)
+}
+
+// publicHost reports whether host may be a webhook target. Names are
+// checked again once resolved, when the client connects.
+func publicHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "" || host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return publicAddr(addr)
+	}
+	return true
+}
+
+// SignWebhook returns the hex HMAC-SHA256 of "<timestamp>.<body>" keyed with
+// secret, the value receivers recompute to verify a request.
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func toWebhook(userID string, input WebhookInput) (domain.Webhook, error) {
+	if strings.TrimSpace(userID) == "" {
+		return domain.Webhook{}, ErrUnauthorized
+	}
+
+	target, err := url.Parse(strings.TrimSpace(input.URL))
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || !publicHost(target.Hostname()) {
+		return domain.Webhook{}, ErrInvalidInput
+	}
+
+	var events []string
+	seen := map[string]bool{}
+	for _, event := range input.Events {
+		event = strings.ToLower(strings.TrimSpace(event))
+		if !webhookEvents[event] {
+			return domain.Webhook{}, ErrInvalidInput
+		}
+		if !seen[event] {
+			seen[event] = true
+			events = append(events, event)
+		}
+	}
+	if len(events) == 0 {
+		return domain.Webhook{}, ErrInvalidInput
+	}
+
+	active := true
+	if input.Active != nil {
+		active = *input.Active
+	}
+	return domain.Webhook{
+		UserID: userID,
+		URL:    target.String(),
+		Secret: strings.TrimSpace(input.Secret),
+		Events: events,
+		Active: active,
+	}, nil
+}
+
+// randomToken returns n random bytes encoded as hex.
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,32 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+// RateWorker periodically refreshes exchange rates from the configured
+// providers.
+type RateWorker struct {
+	Rates    usecase.RateUsecase
+	Interval time.Duration
+}
+
+func NewRateWorker(rates usecase.RateUsecase, interval time.Duration) RateWorker {
+	return RateWorker{
+		Rates:    rates,
+		Interval: interval,
+	}
+}
+
+// Run blocks until ctx is cancelled.
+func (w RateWorker) Run(ctx context.Context) {
+	every(ctx, w.Interval, func(ctx context.Context) {
+		if err := w.Rates.Refresh(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("rates: refresh: %v", err)
+		}
+	})
+}
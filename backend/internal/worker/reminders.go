@@ -0,0 +1,47 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+// ReminderWorker dispatches due reminders every Interval and sweeps all
+// subscriptions every SweepInterval to move reminders on to the next charge;
+// changes to subscriptions reschedule their reminders right away. Several
+// instances may run against the same database; claimed reminders are
+// skipped by the others.
+type ReminderWorker struct {
+	Reminders     usecase.ReminderUsecase
+	Interval      time.Duration
+	SweepInterval time.Duration
+}
+
+func NewReminderWorker(reminders usecase.ReminderUsecase, interval, sweepInterval time.Duration) ReminderWorker {
+	return ReminderWorker{
+		Reminders:     reminders,
+		Interval:      interval,
+		SweepInterval: sweepInterval,
+	}
+}
+
+// Run blocks until ctx is cancelled.
+func (w ReminderWorker) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		every(ctx, w.SweepInterval, func(ctx context.Context) {
+			if err := w.Reminders.Schedule(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("reminders: schedule: %v", err)
+			}
+		})
+	}()
+	every(ctx, w.Interval, func(ctx context.Context) {
+		drain(ctx, "reminders: dispatch", w.Reminders.Dispatch)
+	})
+	wg.Wait()
+}
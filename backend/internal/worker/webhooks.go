@@ -0,0 +1,29 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"subscribe_tracker/backend/internal/usecase"
+)
+
+// WebhookWorker periodically posts due webhook deliveries, including retries
+// of failed ones.
+type WebhookWorker struct {
+	Webhooks usecase.WebhookUsecase
+	Interval time.Duration
+}
+
+func NewWebhookWorker(webhooks usecase.WebhookUsecase, interval time.Duration) WebhookWorker {
+	return WebhookWorker{
+		Webhooks: webhooks,
+		Interval: interval,
+	}
+}
+
+// Run blocks until ctx is cancelled.
+func (w WebhookWorker) Run(ctx context.Context) {
+	every(ctx, w.Interval, func(ctx context.Context) {
+		drain(ctx, "webhooks: deliver", w.Webhooks.Deliver)
+	})
+}
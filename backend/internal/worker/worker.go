@@ -0,0 +1,43 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+const batchSize = 50
+
+// every calls fn right away and then once per interval until ctx is
+// cancelled. A call in progress is finished with the cancelled context, so
+// interrupted work is retried once its lease expires.
+func every(ctx context.Context, interval time.Duration, fn func(ctx context.Context)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		fn(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// drain calls dispatch with batchSize until a batch comes back short, an
+// error occurs or ctx is cancelled.
+func drain(ctx context.Context, name string, dispatch func(ctx context.Context, limit int) (int, error)) {
+	for ctx.Err() == nil {
+		claimed, err := dispatch(ctx, batchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("%s: %v", name, err)
+			}
+			return
+		}
+		if claimed < batchSize {
+			return
+		}
+	}
+}
@@ -0,0 +1,15 @@
+-- Replace the monthly/yearly enum with a unit + interval recurrence.
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS billing_unit TEXT NOT NULL DEFAULT 'month' CHECK (billing_unit IN ('day', 'week', 'month', 'year')),
+    ADD COLUMN IF NOT EXISTS billing_interval INTEGER NOT NULL DEFAULT 1 CHECK (billing_interval BETWEEN 1 AND 365);
+
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'subscriptions' AND column_name = 'billing_cycle'
+    ) THEN
+        UPDATE subscriptions SET billing_unit = 'year', billing_interval = 1 WHERE billing_cycle = 'yearly';
+        ALTER TABLE subscriptions DROP COLUMN billing_cycle;
+    END IF;
+END $$;
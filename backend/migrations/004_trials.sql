@@ -0,0 +1,5 @@
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS trial_end_date DATE,
+    ADD COLUMN IF NOT EXISTS post_trial_price_minor BIGINT NOT NULL DEFAULT 0 CHECK (post_trial_price_minor >= 0);
+
+CREATE INDEX IF NOT EXISTS idx_subscriptions_trial_end_date ON subscriptions(trial_end_date) WHERE trial_end_date IS NOT NULL;
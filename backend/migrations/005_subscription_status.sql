@@ -0,0 +1,16 @@
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'paused', 'cancelled')),
+    ADD COLUMN IF NOT EXISTS status_effective_date DATE;
+
+UPDATE subscriptions SET status_effective_date = LEAST(charge_date, CURRENT_DATE) WHERE status_effective_date IS NULL;
+ALTER TABLE subscriptions ALTER COLUMN status_effective_date SET NOT NULL;
+
+CREATE TABLE IF NOT EXISTS subscription_status_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    subscription_id UUID NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    status TEXT NOT NULL CHECK (status IN ('active', 'paused', 'cancelled')),
+    effective_date DATE NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_subscription_status_history_subscription_id ON subscription_status_history(subscription_id);
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS charges (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    subscription_id UUID NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    charge_date DATE NOT NULL,
+    amount_minor BIGINT NOT NULL CHECK (amount_minor >= 0),
+    currency CHAR(3) NOT NULL CHECK (currency ~ '^[A-Z]{3}$'),
+    status TEXT NOT NULL CHECK (status IN ('expected', 'paid', 'failed', 'refunded')),
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_charges_subscription_id_charge_date ON charges(subscription_id, charge_date);
+CREATE INDEX IF NOT EXISTS idx_charges_user_id_charge_date ON charges(user_id, charge_date);
+
+-- One expected charge per subscription and date, so concurrent generate
+-- requests cannot both insert it. Duplicates stored before the index existed
+-- are dropped first, keeping the oldest.
+DELETE FROM charges c
+USING charges kept
+WHERE c.status = 'expected' AND kept.status = 'expected'
+    AND c.subscription_id = kept.subscription_id AND c.charge_date = kept.charge_date
+    AND (c.created_at, c.id) > (kept.created_at, kept.id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_charges_expected_subscription_id_charge_date
+    ON charges(subscription_id, charge_date) WHERE status = 'expected';
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS subscription_prices (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    subscription_id UUID NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    price_minor BIGINT NOT NULL CHECK (price_minor >= 0),
+    currency CHAR(3) NOT NULL CHECK (currency ~ '^[A-Z]{3}$'),
+    effective_from DATE NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (subscription_id, effective_from)
+);
+
+-- Seed the history of subscriptions created before it was tracked.
+INSERT INTO subscription_prices (subscription_id, price_minor, currency, effective_from)
+SELECT s.id, s.price_minor, s.currency, s.charge_date
+FROM subscriptions s
+WHERE NOT EXISTS (SELECT 1 FROM subscription_prices p WHERE p.subscription_id = s.id);
+
+INSERT INTO subscription_prices (subscription_id, price_minor, currency, effective_from)
+SELECT s.id, s.post_trial_price_minor, s.currency, s.trial_end_date
+FROM subscriptions s
+WHERE s.trial_end_date IS NOT NULL
+ON CONFLICT (subscription_id, effective_from) DO NOTHING;
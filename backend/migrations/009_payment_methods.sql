@@ -0,0 +1,41 @@
+CREATE TABLE IF NOT EXISTS payment_methods (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    bank_name TEXT NOT NULL,
+    card_last4 TEXT NOT NULL,
+    brand TEXT NOT NULL DEFAULT '',
+    exp_month SMALLINT CHECK (exp_month BETWEEN 1 AND 12),
+    exp_year SMALLINT,
+    nickname TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((exp_month IS NULL) = (exp_year IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_methods_user_id ON payment_methods(user_id);
+
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS payment_method_id UUID REFERENCES payment_methods(id) ON DELETE RESTRICT;
+
+-- Move the free-text bank and card of existing subscriptions into one
+-- payment method per distinct pair.
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'subscriptions' AND column_name = 'card_last4'
+    ) THEN
+        INSERT INTO payment_methods (user_id, bank_name, card_last4)
+        SELECT DISTINCT user_id, bank_name, card_last4 FROM subscriptions;
+
+        UPDATE subscriptions s SET payment_method_id = pm.id
+        FROM payment_methods pm
+        WHERE pm.user_id = s.user_id AND pm.bank_name = s.bank_name AND pm.card_last4 = s.card_last4;
+
+        ALTER TABLE subscriptions DROP COLUMN bank_name, DROP COLUMN card_last4;
+    END IF;
+END $$;
+
+ALTER TABLE subscriptions ALTER COLUMN payment_method_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_subscriptions_payment_method_id ON subscriptions(payment_method_id);
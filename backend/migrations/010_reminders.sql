@@ -0,0 +1,27 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS reminder_lead_days INTEGER NOT NULL DEFAULT 3 CHECK (reminder_lead_days BETWEEN 0 AND 90);
+
+-- NULL falls back to the lead time of the user.
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS reminder_lead_days INTEGER CHECK (reminder_lead_days BETWEEN 0 AND 90);
+
+CREATE TABLE IF NOT EXISTS reminders (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    subscription_id UUID NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    charge_date DATE NOT NULL,
+    remind_at DATE NOT NULL,
+    amount_minor BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (subscription_id, charge_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_reminders_user_id ON reminders(user_id);
+CREATE INDEX IF NOT EXISTS idx_reminders_pending ON reminders(remind_at, next_attempt_at) WHERE status = 'pending';
@@ -0,0 +1,3 @@
+-- Language of emails and other notifications sent to the user.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS locale TEXT NOT NULL DEFAULT 'ru' CHECK (locale IN ('ru', 'en'));
@@ -0,0 +1,8 @@
+-- Secret tokens for the calendar feed; calendar apps cannot send an
+-- Authorization header, so the token is part of the URL. Only hashes are
+-- stored and a user has at most one feed.
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    token_hash TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,14 @@
+-- Exchange rates: one unit of base costs rate units of quote on rate_date.
+CREATE TABLE IF NOT EXISTS rates (
+    base TEXT NOT NULL,
+    quote TEXT NOT NULL,
+    rate NUMERIC(24, 10) NOT NULL CHECK (rate > 0),
+    rate_date DATE NOT NULL,
+    source TEXT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (base, quote, rate_date)
+);
+
+-- Currency totals of all subscriptions are converted into.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS base_currency TEXT NOT NULL DEFAULT 'RUB';
@@ -0,0 +1,4 @@
+-- When the user confirmed owning their email; NULL until they follow the
+-- link sent on registration.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMPTZ;
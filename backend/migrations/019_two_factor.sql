@@ -0,0 +1,26 @@
+-- TOTP second factor. The secret stays unconfirmed until the user enters a
+-- first code; last_step is the time step of the last accepted code, so a
+-- code cannot be used twice.
+CREATE TABLE IF NOT EXISTS totp_credentials (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret TEXT NOT NULL,
+    confirmed_at TIMESTAMPTZ,
+    last_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- One-time recovery codes for when the authenticator is lost; only hashes
+-- are stored.
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash TEXT NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, code_hash)
+);
+
+-- Failed attempts at a token, for tokens that allow a few, e.g. the ticket
+-- of a login waiting for its second factor.
+ALTER TABLE user_tokens
+    ADD COLUMN IF NOT EXISTS attempts INT NOT NULL DEFAULT 0;
@@ -2,6 +2,8 @@ import { BrowserRouter as Router, Routes, Route, Navigate } from 'react-router-d
 import LoginPage from './pages/LoginPage';
 import RegisterPage from './pages/RegisterPage';
 import DashboardPage from './pages/DashboardPage';
+import ResetPasswordPage from './pages/ResetPasswordPage';
+import VerifyEmailPage from './pages/VerifyEmailPage';
 import { getAuthToken } from './lib/auth';
 
 import type { ReactNode } from 'react';
@@ -30,6 +32,9 @@ function App() {
             </RequireAuth>
           }
         />
+        {/* Opened from emailed links, signed in or not. */}
+        <Route path="/reset-password" element={<ResetPasswordPage />} />
+        <Route path="/verify-email" element={<VerifyEmailPage />} />
         <Route path="*" element={<Navigate to={isAuthed ? '/app' : '/'} replace />} />
       </Routes>
     </Router>
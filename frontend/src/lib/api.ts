@@ -1,12 +1,22 @@
-import { getAuthToken, saveAuth, type AuthUser } from './auth';
+import { clearAuth, getAuthToken, getRefreshToken, saveAuth, type AuthUser } from './auth';
 
 const API_BASE = import.meta.env.VITE_API_URL ?? '/api';
 
 type AuthResponse = {
   token: string;
+  refresh_token: string;
   user: AuthUser;
 };
 
+// Accounts with two-factor authentication get a ticket instead of tokens; it
+// is exchanged for them together with a code by loginWithMFA.
+type MFARequiredResponse = {
+  mfa_required: true;
+  mfa_ticket: string;
+};
+
+export type LoginResult = { user: AuthUser; mfaTicket?: undefined } | { user?: undefined; mfaTicket: string };
+
 export type Subscription = {
   id: string;
   service_name: string;
@@ -16,7 +26,35 @@ export type Subscription = {
   charge_date: string;
 };
 
-async function request<T>(path: string, options: RequestInit = {}): Promise<T> {
+// Access tokens are short-lived; a request rejected with 401 is retried once
+// after exchanging the refresh token for new ones.
+let refreshing: Promise<boolean> | null = null;
+
+function refreshAuth(): Promise<boolean> {
+  if (!refreshing) {
+    refreshing = (async () => {
+      const refreshToken = getRefreshToken();
+      if (!refreshToken) return false;
+      const res = await fetch(`${API_BASE}/auth/refresh`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ refresh_token: refreshToken }),
+      });
+      if (!res.ok) {
+        clearAuth();
+        return false;
+      }
+      const data = (await res.json()) as AuthResponse;
+      saveAuth(data.token, data.refresh_token, data.user);
+      return true;
+    })().finally(() => {
+      refreshing = null;
+    });
+  }
+  return refreshing;
+}
+
+async function request<T>(path: string, options: RequestInit = {}, retry = true): Promise<T> {
   const token = getAuthToken();
   const headers: Record<string, string> = {
     'Content-Type': 'application/json',
@@ -32,6 +70,10 @@ async function request<T>(path: string, options: RequestInit = {}): Promise<T> {
     headers,
   });
 
+  if (res.status === 401 && retry && token && (await refreshAuth())) {
+    return request<T>(path, options, false);
+  }
+
   const contentType = res.headers.get('content-type');
   const isJSON = contentType?.includes('application/json');
   const payload = isJSON ? await res.json() : null;
@@ -49,19 +91,56 @@ export async function registerUser(name: string, email: string, password: string
     method: 'POST',
     body: JSON.stringify({ name, email, password }),
   });
-  saveAuth(data.token, data.user);
+  saveAuth(data.token, data.refresh_token, data.user);
   return data.user;
 }
 
-export async function loginUser(email: string, password: string) {
-  const data = await request<AuthResponse>('/auth/login', {
+export async function loginUser(email: string, password: string): Promise<LoginResult> {
+  const data = await request<AuthResponse | MFARequiredResponse>('/auth/login', {
     method: 'POST',
     body: JSON.stringify({ email, password }),
   });
-  saveAuth(data.token, data.user);
+  if ('mfa_required' in data) {
+    return { mfaTicket: data.mfa_ticket };
+  }
+  saveAuth(data.token, data.refresh_token, data.user);
+  return { user: data.user };
+}
+
+export async function loginWithMFA(ticket: string, code: string) {
+  const data = await request<AuthResponse>('/auth/login/mfa', {
+    method: 'POST',
+    body: JSON.stringify({ ticket, code }),
+  });
+  saveAuth(data.token, data.refresh_token, data.user);
   return data.user;
 }
 
+// Answers the same whether or not an account has the email.
+export async function requestPasswordReset(email: string) {
+  return request<{ sent: boolean }>('/auth/password/forgot', {
+    method: 'POST',
+    body: JSON.stringify({ email }),
+  });
+}
+
+// Signs the user out everywhere, so they have to sign in with the new password.
+export async function resetPassword(token: string, password: string) {
+  const data = await request<{ reset: boolean }>('/auth/password/reset', {
+    method: 'POST',
+    body: JSON.stringify({ token, password }),
+  });
+  clearAuth();
+  return data;
+}
+
+export async function verifyEmail(token: string) {
+  return request<AuthUser>('/auth/verify-email', {
+    method: 'POST',
+    body: JSON.stringify({ token }),
+  });
+}
+
 export async function getSubscriptions() {
   return request<Subscription[]>('/subscriptions');
 }
@@ -85,3 +164,15 @@ export async function deleteSubscription(id: string) {
     method: 'DELETE',
   });
 }
+
+export async function logoutUser() {
+  const refreshToken = getRefreshToken();
+  clearAuth();
+  if (refreshToken) {
+    await fetch(`${API_BASE}/auth/logout`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ refresh_token: refreshToken }),
+    }).catch(() => undefined);
+  }
+}
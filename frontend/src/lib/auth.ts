@@ -2,13 +2,16 @@ export type AuthUser = {
   id: string;
   name: string;
   email: string;
+  email_verified?: boolean;
 };
 
 const TOKEN_KEY = 'subscribe_tracker_token';
+const REFRESH_TOKEN_KEY = 'subscribe_tracker_refresh_token';
 const USER_KEY = 'subscribe_tracker_user';
 
-export function saveAuth(token: string, user: AuthUser) {
+export function saveAuth(token: string, refreshToken: string, user: AuthUser) {
   localStorage.setItem(TOKEN_KEY, token);
+  localStorage.setItem(REFRESH_TOKEN_KEY, refreshToken);
   localStorage.setItem(USER_KEY, JSON.stringify(user));
 }
 
@@ -16,6 +19,10 @@ export function getAuthToken(): string | null {
   return localStorage.getItem(TOKEN_KEY);
 }
 
+export function getRefreshToken(): string | null {
+  return localStorage.getItem(REFRESH_TOKEN_KEY);
+}
+
 export function getAuthUser(): AuthUser | null {
   const raw = localStorage.getItem(USER_KEY);
   if (!raw) return null;
@@ -28,5 +35,6 @@ export function getAuthUser(): AuthUser | null {
 
 export function clearAuth() {
   localStorage.removeItem(TOKEN_KEY);
+  localStorage.removeItem(REFRESH_TOKEN_KEY);
   localStorage.removeItem(USER_KEY);
 }
@@ -5,10 +5,11 @@ import {
   createSubscription,
   deleteSubscription,
   getSubscriptions,
+  logoutUser,
   updateSubscription,
   type Subscription,
 } from '../lib/api';
-import { clearAuth, getAuthUser } from '../lib/auth';
+import { getAuthUser } from '../lib/auth';
 
 const emptyForm: Omit<Subscription, 'id'> = {
   service_name: '',
@@ -96,8 +97,8 @@ export default function DashboardPage() {
     }
   };
 
-  const handleLogout = () => {
-    clearAuth();
+  const handleLogout = async () => {
+    await logoutUser();
     window.location.href = '/';
   };
 
@@ -1,11 +1,11 @@
-import { useState } from 'react';
+import { useState, type FormEvent } from 'react';
 import { useForm } from 'react-hook-form';
 import { zodResolver } from '@hookform/resolvers/zod';
 import * as z from 'zod';
 import { Link, useNavigate } from 'react-router-dom';
 import { cn } from '../lib/utils';
-import { Lock, Mail, ArrowRight } from 'lucide-react';
-import { loginUser } from '../lib/api';
+import { Lock, Mail, ArrowRight, KeyRound } from 'lucide-react';
+import { loginUser, loginWithMFA } from '../lib/api';
 
 const loginSchema = z.object({
     email: z.string().email('Please enter a valid email address'),
@@ -23,6 +23,8 @@ export default function LoginPage() {
     const navigate = useNavigate();
     const [error, setError] = useState('');
     const [loading, setLoading] = useState(false);
+    const [mfaTicket, setMfaTicket] = useState('');
+    const [code, setCode] = useState('');
     const {
         register,
         handleSubmit,
@@ -35,7 +37,25 @@ export default function LoginPage() {
         setError('');
         setLoading(true);
         try {
-            await loginUser(data.email, data.password);
+            const result = await loginUser(data.email, data.password);
+            if (result.mfaTicket) {
+                setMfaTicket(result.mfaTicket);
+                return;
+            }
+            navigate('/app');
+        } catch (err) {
+            setError(err instanceof Error ? err.message : 'Не удалось войти');
+        } finally {
+            setLoading(false);
+        }
+    };
+
+    const onSubmitCode = async (event: FormEvent) => {
+        event.preventDefault();
+        setError('');
+        setLoading(true);
+        try {
+            await loginWithMFA(mfaTicket, code.trim());
             navigate('/app');
         } catch (err) {
             setError(err instanceof Error ? err.message : 'Не удалось войти');
@@ -65,6 +85,41 @@ export default function LoginPage() {
                     </div>
                 )}
 
+                {mfaTicket ? (
+                <form className="mt-8 space-y-6" onSubmit={onSubmitCode}>
+                    <div>
+                        <label htmlFor="code" className="sr-only">
+                            Authentication code
+                        </label>
+                        <div className="relative">
+                            <div className="pointer-events-none absolute inset-y-0 left-0 flex items-center pl-3">
+                                <KeyRound className="h-5 w-5 text-gray-500" aria-hidden="true" />
+                            </div>
+                            <input
+                                id="code"
+                                type="text"
+                                inputMode="text"
+                                autoComplete="one-time-code"
+                                autoFocus
+                                className="block w-full rounded-lg border border-white/10 bg-background/50 py-3 pl-10 pr-3 text-white placeholder-gray-500 focus:border-primary focus:outline-none focus:ring-1 focus:ring-primary sm:text-sm transition-all duration-200"
+                                placeholder="Code from your app or a recovery code"
+                                value={code}
+                                onChange={(event) => setCode(event.target.value)}
+                            />
+                        </div>
+                    </div>
+
+                    <div>
+                        <button
+                            type="submit"
+                            disabled={loading || !code.trim()}
+                            className="group relative flex w-full justify-center rounded-lg bg-primary py-3 px-4 text-sm font-semibold text-white hover:bg-primary/90 focus:outline-none focus:ring-2 focus:ring-primary focus:ring-offset-2 focus:ring-offset-gray-900 transition-all duration-200 disabled:cursor-not-allowed disabled:opacity-60"
+                        >
+                            {loading ? 'Verifying...' : 'Verify'}
+                        </button>
+                    </div>
+                </form>
+                ) : (
                 <form className="mt-8 space-y-6" onSubmit={handleSubmit(onSubmit)}>
                     <div className="space-y-4">
                         <div>
@@ -120,9 +175,9 @@ export default function LoginPage() {
 
                     <div className="flex items-center justify-between">
                         <div className="text-sm">
-                            <a href="#" className="font-medium text-primary hover:text-primary/80 transition-colors">
+                            <Link to="/reset-password" className="font-medium text-primary hover:text-primary/80 transition-colors">
                                 Forgot your password?
-                            </a>
+                            </Link>
                         </div>
                     </div>
 
@@ -139,6 +194,7 @@ export default function LoginPage() {
                         </button>
                     </div>
                 </form>
+                )}
 
                 <div className="text-center text-sm">
                     <p className="text-gray-400">
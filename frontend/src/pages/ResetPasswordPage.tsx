@@ -0,0 +1,256 @@
+import { useState } from 'react';
+import { useForm } from 'react-hook-form';
+import { zodResolver } from '@hookform/resolvers/zod';
+import * as z from 'zod';
+import { Link, useSearchParams } from 'react-router-dom';
+import { cn } from '../lib/utils';
+import { Lock, Mail, ArrowRight } from 'lucide-react';
+import { requestPasswordReset, resetPassword } from '../lib/api';
+
+const requestSchema = z.object({
+    email: z.string().email('Please enter a valid email address'),
+});
+
+const resetSchema = z.object({
+    password: z
+        .string()
+        .min(8, 'Password must be at least 8 characters')
+        .regex(/[A-Z]/, 'Password must contain at least one uppercase letter')
+        .regex(/[a-z]/, 'Password must contain at least one lowercase letter')
+        .regex(/[0-9]/, 'Password must contain at least one number'),
+    confirmPassword: z.string(),
+}).refine((data) => data.password === data.confirmPassword, {
+    message: "Passwords don't match",
+    path: ["confirmPassword"],
+});
+
+type RequestFormData = z.infer<typeof requestSchema>;
+type ResetFormData = z.infer<typeof resetSchema>;
+
+const inputClass =
+    "block w-full rounded-lg border border-white/10 bg-background/50 py-3 pl-10 pr-3 text-white placeholder-gray-500 focus:border-primary focus:outline-none focus:ring-1 focus:ring-primary sm:text-sm transition-all duration-200";
+const invalidInputClass = "border-red-500 focus:border-red-500 focus:ring-red-500";
+const buttonClass =
+    "group relative flex w-full justify-center rounded-lg bg-primary py-3 px-4 text-sm font-semibold text-white hover:bg-primary/90 focus:outline-none focus:ring-2 focus:ring-primary focus:ring-offset-2 focus:ring-offset-gray-900 transition-all duration-200 disabled:cursor-not-allowed disabled:opacity-60";
+
+// ResetPasswordPage is where the emailed reset link leads. Without a token in
+// the link it asks for the email to send one to.
+export default function ResetPasswordPage() {
+    const [searchParams] = useSearchParams();
+    const token = searchParams.get('token') ?? '';
+
+    return (
+        <div className="flex min-h-screen items-center justify-center bg-background px-4 py-12 sm:px-6 lg:px-8 relative overflow-hidden">
+            <div className="absolute top-[-10%] left-[-10%] h-[500px] w-[500px] rounded-full bg-accent/20 blur-[100px]" />
+            <div className="absolute bottom-[-10%] right-[-10%] h-[500px] w-[500px] rounded-full bg-secondary/20 blur-[100px]" />
+
+            <div className="w-full max-w-md space-y-8 relative z-10 bg-surface/50 backdrop-blur-xl p-8 rounded-2xl border border-white/10 shadow-2xl">
+                {token ? <ResetForm token={token} /> : <RequestForm />}
+
+                <div className="text-center text-sm">
+                    <p className="text-gray-400">
+                        Remembered it?{' '}
+                        <Link to="/" className="font-semibold text-primary hover:text-primary/80 transition-colors">
+                            Sign in
+                        </Link>
+                    </p>
+                </div>
+            </div>
+        </div>
+    );
+}
+
+function RequestForm() {
+    const [error, setError] = useState('');
+    const [sent, setSent] = useState(false);
+    const [loading, setLoading] = useState(false);
+    const {
+        register,
+        handleSubmit,
+        formState: { errors },
+    } = useForm<RequestFormData>({
+        resolver: zodResolver(requestSchema),
+    });
+
+    const onSubmit = async (data: RequestFormData) => {
+        setError('');
+        setLoading(true);
+        try {
+            await requestPasswordReset(data.email);
+            setSent(true);
+        } catch (err) {
+            setError(err instanceof Error ? err.message : 'Не удалось отправить письмо');
+        } finally {
+            setLoading(false);
+        }
+    };
+
+    return (
+        <>
+            <div className="text-center">
+                <h2 className="mt-2 text-3xl font-bold tracking-tight text-white">
+                    Forgot Password
+                </h2>
+                <p className="mt-2 text-sm text-gray-400">
+                    We will email you a link to set a new one
+                </p>
+            </div>
+
+            {error && (
+                <div className="rounded-lg border border-red-500/40 bg-red-500/10 px-4 py-3 text-sm text-red-200">
+                    {error}
+                </div>
+            )}
+
+            {sent ? (
+                <div className="rounded-lg border border-primary/40 bg-primary/10 px-4 py-3 text-sm text-gray-200">
+                    If an account uses this email, a reset link is on its way. It works for one hour.
+                </div>
+            ) : (
+            <form className="mt-8 space-y-6" onSubmit={handleSubmit(onSubmit)}>
+                <div>
+                    <label htmlFor="email" className="sr-only">
+                        Email address
+                    </label>
+                    <div className="relative">
+                        <div className="pointer-events-none absolute inset-y-0 left-0 flex items-center pl-3">
+                            <Mail className="h-5 w-5 text-gray-500" aria-hidden="true" />
+                        </div>
+                        <input
+                            id="email"
+                            type="email"
+                            autoComplete="email"
+                            className={cn(inputClass, errors.email && invalidInputClass)}
+                            placeholder="Email address"
+                            {...register('email')}
+                        />
+                    </div>
+                    {errors.email && (
+                        <p className="mt-1 text-xs text-red-500 pl-1">{errors.email.message}</p>
+                    )}
+                </div>
+
+                <div>
+                    <button type="submit" disabled={loading} className={buttonClass}>
+                        {loading ? 'Sending...' : 'Send reset link'}
+                        <span className="absolute inset-y-0 right-0 flex items-center pr-3">
+                            <ArrowRight className="h-4 w-4 text-white/50 group-hover:text-white transition-colors" />
+                        </span>
+                    </button>
+                </div>
+            </form>
+            )}
+        </>
+    );
+}
+
+function ResetForm({ token }: { token: string }) {
+    const [error, setError] = useState('');
+    const [done, setDone] = useState(false);
+    const [loading, setLoading] = useState(false);
+    const {
+        register,
+        handleSubmit,
+        formState: { errors },
+    } = useForm<ResetFormData>({
+        resolver: zodResolver(resetSchema),
+    });
+
+    const onSubmit = async (data: ResetFormData) => {
+        setError('');
+        setLoading(true);
+        try {
+            await resetPassword(token, data.password);
+            setDone(true);
+        } catch (err) {
+            setError(err instanceof Error ? err.message : 'Не удалось сменить пароль');
+        } finally {
+            setLoading(false);
+        }
+    };
+
+    return (
+        <>
+            <div className="text-center">
+                <h2 className="mt-2 text-3xl font-bold tracking-tight text-white">
+                    Set New Password
+                </h2>
+                <p className="mt-2 text-sm text-gray-400">
+                    You will be signed out of all your devices
+                </p>
+            </div>
+
+            {error && (
+                <div className="rounded-lg border border-red-500/40 bg-red-500/10 px-4 py-3 text-sm text-red-200">
+                    {error}{' '}
+                    <Link to="/reset-password" className="font-semibold underline">
+                        Request a new link
+                    </Link>
+                </div>
+            )}
+
+            {done ? (
+                <div className="rounded-lg border border-primary/40 bg-primary/10 px-4 py-3 text-sm text-gray-200">
+                    Your password has been changed. Sign in with the new one.
+                </div>
+            ) : (
+            <form className="mt-8 space-y-6" onSubmit={handleSubmit(onSubmit)}>
+                <div className="space-y-4">
+                    <div>
+                        <label htmlFor="password" className="sr-only">
+                            New Password
+                        </label>
+                        <div className="relative">
+                            <div className="pointer-events-none absolute inset-y-0 left-0 flex items-center pl-3">
+                                <Lock className="h-5 w-5 text-gray-500" aria-hidden="true" />
+                            </div>
+                            <input
+                                id="password"
+                                type="password"
+                                autoComplete="new-password"
+                                className={cn(inputClass, errors.password && invalidInputClass)}
+                                placeholder="New Password"
+                                {...register('password')}
+                            />
+                        </div>
+                        {errors.password && (
+                            <p className="mt-1 text-xs text-red-500 pl-1">{errors.password.message}</p>
+                        )}
+                    </div>
+
+                    <div>
+                        <label htmlFor="confirmPassword" className="sr-only">
+                            Confirm Password
+                        </label>
+                        <div className="relative">
+                            <div className="pointer-events-none absolute inset-y-0 left-0 flex items-center pl-3">
+                                <Lock className="h-5 w-5 text-gray-500" aria-hidden="true" />
+                            </div>
+                            <input
+                                id="confirmPassword"
+                                type="password"
+                                autoComplete="new-password"
+                                className={cn(inputClass, errors.confirmPassword && invalidInputClass)}
+                                placeholder="Confirm Password"
+                                {...register('confirmPassword')}
+                            />
+                        </div>
+                        {errors.confirmPassword && (
+                            <p className="mt-1 text-xs text-red-500 pl-1">{errors.confirmPassword.message}</p>
+                        )}
+                    </div>
+                </div>
+
+                <div>
+                    <button type="submit" disabled={loading} className={buttonClass}>
+                        {loading ? 'Saving...' : 'Set password'}
+                        <span className="absolute inset-y-0 right-0 flex items-center pr-3">
+                            <ArrowRight className="h-4 w-4 text-white/50 group-hover:text-white transition-colors" />
+                        </span>
+                    </button>
+                </div>
+            </form>
+            )}
+        </>
+    );
+}
@@ -0,0 +1,65 @@
+import { useEffect, useRef, useState } from 'react';
+import { Link, useSearchParams } from 'react-router-dom';
+import { MailCheck } from 'lucide-react';
+import { verifyEmail } from '../lib/api';
+import { getAuthToken } from '../lib/auth';
+
+type Status = 'verifying' | 'verified' | 'failed';
+
+// VerifyEmailPage is where the emailed verification link leads. It confirms
+// the address as soon as it opens.
+export default function VerifyEmailPage() {
+    const [searchParams] = useSearchParams();
+    const token = searchParams.get('token') ?? '';
+    const [status, setStatus] = useState<Status>(token ? 'verifying' : 'failed');
+    const [error, setError] = useState(token ? '' : 'The link has no token');
+    // Tokens work once, so the request must not be repeated when the effect
+    // runs twice in development.
+    const requested = useRef(false);
+
+    useEffect(() => {
+        if (!token || requested.current) return;
+        requested.current = true;
+        verifyEmail(token)
+            .then(() => setStatus('verified'))
+            .catch((err) => {
+                setError(err instanceof Error ? err.message : 'Не удалось подтвердить email');
+                setStatus('failed');
+            });
+    }, [token]);
+
+    const next = getAuthToken() ? '/app' : '/';
+
+    return (
+        <div className="flex min-h-screen items-center justify-center bg-background px-4 py-12 sm:px-6 lg:px-8 relative overflow-hidden">
+            <div className="absolute top-[-10%] right-[-10%] h-[500px] w-[500px] rounded-full bg-primary/10 blur-[100px]" />
+            <div className="absolute bottom-[-10%] left-[-10%] h-[500px] w-[500px] rounded-full bg-accent/20 blur-[100px]" />
+
+            <div className="w-full max-w-md space-y-8 relative z-10 bg-surface/50 backdrop-blur-xl p-8 rounded-2xl border border-white/10 shadow-2xl">
+                <div className="text-center">
+                    <MailCheck className="mx-auto h-10 w-10 text-primary" aria-hidden="true" />
+                    <h2 className="mt-2 text-3xl font-bold tracking-tight text-white">
+                        Email Verification
+                    </h2>
+                    <p className="mt-2 text-sm text-gray-400">
+                        {status === 'verifying' && 'Confirming your email address...'}
+                        {status === 'verified' && 'Your email address is confirmed.'}
+                        {status === 'failed' && 'Your email address could not be confirmed.'}
+                    </p>
+                </div>
+
+                {error && (
+                    <div className="rounded-lg border border-red-500/40 bg-red-500/10 px-4 py-3 text-sm text-red-200">
+                        {error}. Sign in and ask for a new link if this one has expired.
+                    </div>
+                )}
+
+                <div className="text-center text-sm">
+                    <Link to={next} className="font-semibold text-primary hover:text-primary/80 transition-colors">
+                        {next === '/app' ? 'Go to your subscriptions' : 'Sign in'}
+                    </Link>
+                </div>
+            </div>
+        </div>
+    );
+}